@@ -1,19 +1,44 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"time"
 
 	parser "loganalyzer/internal/analyzer"
 	"loganalyzer/internal/config"
+	"loganalyzer/internal/history"
+	"loganalyzer/internal/notify"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/tracing"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	configPath string
-	outputPath string
+	configPath   string
+	outputPath   string
+	onlyIDs      []string
+	includeTags  []string
+	excludeTags  []string
+	dryRunNotify bool
+	templateTop  int
+	bucketSize   time.Duration
+	anomalies    bool
+	traceURL     string
+	traceStdout  bool
+	streamPath   string
+	jsonOutput   bool
+	readStdin    bool
+	sourcePath   string
+	sourceType   string
+	sourceID     string
+	sourceMulti  string
+	redactOutput bool
+	whereQuery   string
 )
 
 func formatOutputPath(path string) string {
@@ -50,6 +75,18 @@ Features:
 - JSON configuration input and optional JSON report output
 - Real-time progress updates and detailed error reporting
 - Automatic timestamp in output filenames (YYMMDD format)
+- Selection of logs by ID or tag, with skipped entries noted in the report
+- Per-log aggregates (lines, levels, HTTP status classes) and alerting rules
+- Webhook, Slack and email notifications for failures and fired alerts
+- Message template mining to report the top distinct error kinds per log
+- Time-bucketed histograms per level, rendered as sparklines in the summary
+- Anomaly detection of error spikes, volume spikes and silences
+- Optional history store of every run, queried with the history command
+- NDJSON event output on stdout with --json, human messages on stderr
+- Streaming of each result to a JSONL file or stdout as soon as it is ready
+- OpenTelemetry traces of each run with one span per log (OTLP/HTTP or stdout)
+- Ad-hoc analysis of a single log or of piped input, without configuration file
+- Analysis restricted to the entries matching a --where query
 
 Example usage:
   loganalyzer analyze --config config.json --output report.json
@@ -58,47 +95,268 @@ Example usage:
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
-	if configPath == "" {
-		return fmt.Errorf("config file path is required (use --config or -c flag)")
+	if configPath == "" && !readStdin && sourcePath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag, or --stdin or --path for a single log)")
+	}
+	if jsonOutput && streamPath == "-" {
+		return fmt.Errorf("--json already streams results on stdout, use --stream with a file path")
 	}
 
-	fmt.Printf("Loading configuration from: %s\n", configPath)
-	cfg, err := config.LoadConfig(configPath)
+	started := time.Now()
+
+	if bucketSize != 0 && bucketSize < time.Second {
+		return fmt.Errorf("invalid --bucket %s: must be at least 1s", bucketSize)
+	}
+
+	// With --json, stdout carries only NDJSON events and every human
+	// message goes to stderr.
+	out := io.Writer(os.Stdout)
+	var events *reporter.EventSink
+	if jsonOutput {
+		out = os.Stderr
+		events = reporter.NewEventSink(os.Stdout)
+	}
+
+	where, err := parseWhere(whereQuery)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadAnalyzeConfig(out)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
 
-	fmt.Printf("Loaded configuration with %d log files\n", len(cfg.Logs))
+	analyzer, err := parser.NewAnalyzerWithOptions(cfg, parser.Options{
+		Selection: config.Selection{
+			Only:        onlyIDs,
+			Tags:        includeTags,
+			ExcludeTags: excludeTags,
+		},
+		TemplateTop: templateTop,
+		Bucket:      bucketSize,
+		Anomalies:   anomalies,
+		Tracer:      newTracer(cfg, traceURL, traceStdout, out),
+		Redact:      redactOutput,
+		Where:       where,
+	})
+	if err != nil {
+		return err
+	}
+	analyzer.SetOutput(out)
 
-	analyzer := parser.NewAnalyzer(cfg)
+	results := analyzer.GetReporter()
+	results.SetOutput(out)
+	if streamPath != "" {
+		sink, err := newStreamSink(streamPath)
+		if err != nil {
+			return err
+		}
+		results.AddSink(sink)
+	}
+	if events != nil {
+		results.AddSink(events)
+		if err := events.Emit(reporter.Event{Event: reporter.EventStart, Config: configPath, Logs: len(cfg.Logs)}); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if err := results.Close(); err != nil {
+			fmt.Fprintf(out, "✗ Failed to stream results: %v\n", err)
+		}
+	}()
 
 	if err := analyzer.AnalyzeAllLogs(); err != nil {
 		return fmt.Errorf("analysis failed: %w", err)
 	}
 
-	reporter := analyzer.GetReporter()
-	reporter.PrintSummary()
+	results.PrintSummary()
 
 	if outputPath != "" {
 		timestampedPath := formatOutputPath(outputPath)
-		if err := reporter.SaveToFile(timestampedPath); err != nil {
+		if err := results.SaveToFile(timestampedPath); err != nil {
 			return fmt.Errorf("failed to save results: %w", err)
 		}
+		if events != nil {
+			if err := events.Emit(reporter.Event{Event: reporter.EventSaved, Path: timestampedPath}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.History != nil {
+		if err := recordHistory(out, *cfg.History, started, results.GetResults()); err != nil {
+			fmt.Fprintf(out, "✗ History not updated: %v\n", err)
+		}
+	}
+
+	if len(cfg.Notifiers) > 0 {
+		dispatcher, err := notify.NewDispatcher(cfg.Notifiers)
+		if err != nil {
+			return fmt.Errorf("failed to set up notifiers: %w", err)
+		}
+		dispatcher.SetDryRun(dryRunNotify)
+		dispatcher.SetOutput(out)
+
+		if err := dispatcher.Notify(context.Background(), results.GetResults()); err != nil {
+			fmt.Fprintf(out, "✗ Notification failed: %v\n", err)
+		}
+	}
+
+	if events != nil {
+		summary := reporter.Summarize(results.GetResults())
+		if err := events.Emit(reporter.Event{Event: reporter.EventSummary, Summary: &summary}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out, "\nAnalysis completed successfully!")
+	return nil
+}
+
+// loadAnalyzeConfig returns the configuration file given with --config or,
+// without one, the configuration of the single log given with --stdin or
+// --path.
+func loadAnalyzeConfig(out io.Writer) (*config.Config, error) {
+	if configPath == "" {
+		return adHocConfig(out)
+	}
+	if readStdin || sourcePath != "" {
+		return nil, fmt.Errorf("--stdin and --path analyze a single log without configuration file, they cannot be combined with --config")
+	}
+
+	fmt.Fprintf(out, "Loading configuration from: %s\n", configPath)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	fmt.Fprintf(out, "Loaded configuration with %d log files\n", len(cfg.Logs))
+	return cfg, nil
+}
+
+// adHocConfig returns the configuration of the log of --stdin or --path.
+func adHocConfig(out io.Writer) (*config.Config, error) {
+	path := sourcePath
+	if readStdin {
+		if path != "" && !config.IsStdinPath(path) {
+			return nil, fmt.Errorf("--stdin and --path cannot be combined")
+		}
+		path = config.StdinPath
+	}
+
+	if config.IsStdinPath(path) {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return nil, fmt.Errorf("--stdin expects piped input, e.g. kubectl logs my-pod | loganalyzer analyze --stdin")
+		}
+	}
+
+	id := sourceID
+	if id == "" {
+		id = "stdin"
+		if !config.IsStdinPath(path) {
+			id = filepath.Base(path)
+		}
+	}
+
+	log := config.LogConfig{ID: id, Path: path, Type: sourceType}
+	if sourceMulti != "" {
+		log.Multiline = &config.MultilineConfig{Preset: sourceMulti}
+	}
+	cfg, err := config.ForLog(log)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(out, "Analyzing %s as %s log\n", id, sourceType)
+	return cfg, nil
+}
+
+// newStreamSink returns the sink of --stream: NDJSON on stdout for "-",
+// otherwise a JSONL file the results are appended to.
+func newStreamSink(path string) (reporter.Sink, error) {
+	if path == "-" {
+		return reporter.NewJSONLSink(os.Stdout), nil
+	}
+	sink, err := reporter.NewJSONLFileSink(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up --stream: %w", err)
+	}
+	return sink, nil
+}
+
+// newTracer returns the tracer of the "tracing" configuration section, with
+// the exporter overridden by the command-line flags, or nil when tracing is
+// disabled. The stdout exporter and export failures write to out, which is
+// stderr with --json so the trace does not break the NDJSON stream.
+func newTracer(cfg *config.Config, endpoint string, stdout bool, out io.Writer) *tracing.Tracer {
+	if cfg.Tracing == nil && endpoint == "" && !stdout {
+		return nil
+	}
+
+	tracingCfg := config.TracingConfig{}
+	if cfg.Tracing != nil {
+		tracingCfg = *cfg.Tracing
+	}
+	if endpoint != "" {
+		tracingCfg.Exporter = config.TracingExporterOTLP
+		tracingCfg.Endpoint = endpoint
+	}
+	if stdout {
+		tracingCfg.Exporter = config.TracingExporterStdout
+	}
+	tracer := tracing.FromConfig(tracingCfg, out)
+	tracer.OnError(func(err error) {
+		fmt.Fprintf(out, "✗ Failed to export trace: %v\n", err)
+	})
+	return tracer
+}
+
+// recordHistory appends the results of the run to the history store and
+// prunes the records older than the retention, reporting what it did to out.
+func recordHistory(out io.Writer, cfg config.HistoryConfig, run time.Time, results []reporter.AnalysisResult) error {
+	store := history.NewStore(cfg.Path)
+	if err := store.Append(history.NewRecords(run, results)); err != nil {
+		return err
+	}
+
+	if retention := cfg.RetentionPeriod(); retention > 0 {
+		removed, err := store.Prune(run.Add(-retention))
+		if err != nil {
+			return err
+		}
+		if removed > 0 {
+			fmt.Fprintf(out, "Pruned %d history records older than %s\n", removed, retention)
+		}
 	}
 
-	fmt.Println("\nAnalysis completed successfully!")
+	fmt.Fprintf(out, "History updated: %s\n", store.Path())
 	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
 
-	analyzeCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to JSON configuration file (required)")
+	analyzeCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to JSON configuration file (required unless --stdin or --path is given)")
 	analyzeCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Path to JSON output file (optional)")
-
-	if err := analyzeCmd.MarkFlagRequired("config"); err != nil {
-		panic(fmt.Sprintf("Failed to mark config flag as required: %v", err))
-	}
+	analyzeCmd.Flags().StringSliceVar(&onlyIDs, "only", nil, "Analyze only the logs with these IDs (comma-separated)")
+	analyzeCmd.Flags().StringSliceVar(&includeTags, "tag", nil, "Analyze only logs carrying at least one of these tags")
+	analyzeCmd.Flags().StringSliceVar(&excludeTags, "exclude-tag", nil, "Skip logs carrying any of these tags")
+	analyzeCmd.Flags().IntVar(&templateTop, "templates", 0, "Report the top N message templates for logs without a templates setting (0 disables)")
+	analyzeCmd.Flags().DurationVar(&bucketSize, "bucket", 0, "Histogram bucket size (e.g. 1m, 5m, 1h) for logs without a bucket setting (0 disables)")
+	analyzeCmd.Flags().BoolVar(&anomalies, "anomalies", false, "Detect error spikes, volume spikes and silences (enabled by an \"anomaly\" config section)")
+	analyzeCmd.Flags().BoolVar(&jsonOutput, "json", false, "Print NDJSON events and results on stdout, human messages on stderr")
+	analyzeCmd.Flags().StringVar(&streamPath, "stream", "", "Append each result to this JSONL file as soon as it is ready (\"-\" for stdout)")
+	analyzeCmd.Flags().StringVar(&traceURL, "trace-endpoint", "", "Export a trace of the run to this OTLP/HTTP endpoint (e.g. http://localhost:4318)")
+	analyzeCmd.Flags().BoolVar(&traceStdout, "trace-stdout", false, "Print the trace of the run as OTLP/JSON on stdout (stderr with --json)")
+	analyzeCmd.Flags().StringVar(&whereQuery, "where", "", "Analyze only the entries matching this query (e.g. 'level>=WARN AND status>=500')")
+	analyzeCmd.Flags().BoolVar(&redactOutput, "redact", false, "Mask emails, tokens, card numbers and IPs in the results (enabled by a \"redaction\" config section)")
+	analyzeCmd.Flags().BoolVar(&dryRunNotify, "dry-run-notify", false, "Print notification payloads instead of sending them")
+	analyzeCmd.Flags().BoolVar(&readStdin, "stdin", false, "Analyze the log piped on standard input, without configuration file")
+	analyzeCmd.Flags().StringVar(&sourcePath, "path", "", "Analyze this single log (file, URL or \"-\" for stdin), without configuration file")
+	analyzeCmd.Flags().StringVar(&sourceType, "type", "text", "Type of the log of --stdin or --path (e.g. jsonl, nginx access, syslog, journal)")
+	analyzeCmd.Flags().StringVar(&sourceID, "id", "", "ID of the log of --stdin or --path (default \"stdin\" or the file name)")
+	analyzeCmd.Flags().StringVar(&sourceMulti, "multiline", "", "Assemble the multi-line entries of the log of --stdin or --path with a preset (java, python, go)")
 
 	analyzeCmd.Example = `  # Analyze logs with config file only
   loganalyzer analyze --config config.json
@@ -108,5 +366,37 @@ func init() {
   # Output will be saved as: YYMMDD_report.json (e.g., 240524_report.json)
 
   # Using short flags
-  loganalyzer analyze -c config.json -o report.json`
+  loganalyzer analyze -c config.json -o report.json
+
+  # Analyze only some logs, by ID or by tag
+  loganalyzer analyze -c config.json --only web-server-1,app-backend-2
+  loganalyzer analyze -c config.json --tag nginx --exclude-tag staging
+
+  # Report the 10 most frequent message templates of every log
+  loganalyzer analyze -c config.json --templates 10
+
+  # Show when events happened, in 5 minute buckets
+  loganalyzer analyze -c config.json --bucket 5m
+
+  # Flag error spikes and silences against a rolling baseline
+  loganalyzer analyze -c config.json --anomalies
+
+  # Machine-readable output for shell pipelines
+  loganalyzer analyze -c config.json --json | jq 'select(.event == "result") | .result.status'
+
+  # Keep a record of every finished log even if the run is interrupted
+  loganalyzer analyze -c config.json --stream results.jsonl
+
+  # Send a trace of the run to a local OpenTelemetry collector
+  loganalyzer analyze -c config.json --trace-endpoint http://localhost:4318
+
+  # Aggregate only the API server errors
+  loganalyzer analyze -c config.json --where 'status>=500 AND path ~ "^/api"'
+
+  # Analyze piped logs without configuration file
+  kubectl logs deploy/api | loganalyzer analyze --stdin --type jsonl
+  loganalyzer analyze --path /var/log/nginx/access.log --type "nginx access"
+
+  # Show the notification payloads without sending them
+  loganalyzer analyze -c config.json --dry-run-notify`
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -151,3 +152,137 @@ func TestAnalyzeCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeCommandJSON(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logPath := filepath.Join(tempDir, "test.log")
+	if err := os.WriteFile(logPath, []byte("ERROR boom\nINFO fine\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+	configContent := `[
+		{"id": "app", "path": "` + logPath + `", "type": "application"},
+		{"id": "missing", "path": "` + filepath.Join(tempDir, "missing.log") + `", "type": "application"}
+	]`
+	configFilePath := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configFilePath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	stdout, err := os.CreateTemp(tempDir, "stdout")
+	if err != nil {
+		t.Fatalf("Failed to create stdout file: %v", err)
+	}
+	savedStdout := os.Stdout
+	os.Stdout = stdout
+	// The trace must not end up among the events.
+	configPath, outputPath, jsonOutput, traceStdout = configFilePath, "", true, true
+	defer func() {
+		os.Stdout = savedStdout
+		configPath, jsonOutput, traceStdout = "", false, false
+	}()
+
+	err = runAnalyze(nil, nil)
+	os.Stdout = savedStdout
+	if err != nil {
+		t.Fatalf("runAnalyze() error = %v", err)
+	}
+
+	data, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("Failed to read stdout: %v", err)
+	}
+
+	var events []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event struct {
+			Event   string `json:"event"`
+			Summary *struct {
+				Failed int `json:"failed"`
+			} `json:"summary"`
+		}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("stdout line is not JSON: %q", line)
+		}
+		events = append(events, event.Event)
+		if event.Summary != nil && event.Summary.Failed != 1 {
+			t.Errorf("summary failed = %d, want 1", event.Summary.Failed)
+		}
+	}
+
+	if got := strings.Join(events, ","); got != "start,result,result,summary" {
+		t.Errorf("events = %s, want start,result,result,summary", got)
+	}
+}
+
+func TestAnalyzeCommandStdin(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	go func() {
+		writer.WriteString(`{"level":"error","msg":"upstream timed out"}` + "\n")
+		writer.WriteString(`{"level":"info","msg":"request served"}` + "\n")
+		writer.Close()
+	}()
+
+	outputFile := filepath.Join(t.TempDir(), "report.json")
+	savedStdin := os.Stdin
+	os.Stdin = reader
+	configPath, outputPath, readStdin, sourceType = "", outputFile, true, "jsonl"
+	defer func() {
+		os.Stdin = savedStdin
+		outputPath, readStdin, sourceType = "", false, "text"
+	}()
+
+	if err := runAnalyze(nil, nil); err != nil {
+		t.Fatalf("runAnalyze() error = %v", err)
+	}
+
+	data, err := os.ReadFile(formatOutputPath(outputFile))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var results []struct {
+		LogID    string `json:"log_id"`
+		FilePath string `json:"file_path"`
+		Status   string `json:"status"`
+		Stats    struct {
+			Lines  int            `json:"lines"`
+			Levels map[string]int `json:"levels"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("report has %d results, want 1", len(results))
+	}
+	result := results[0]
+	if result.LogID != "stdin" || result.FilePath != "-" || result.Status != "OK" {
+		t.Errorf("result = %+v, want a successful stdin result", result)
+	}
+	if result.Stats.Lines != 2 || result.Stats.Levels["ERROR"] != 1 {
+		t.Errorf("stats = %+v, want 2 lines with 1 error", result.Stats)
+	}
+}
+
+func TestAnalyzeCommandAdHocFlags(t *testing.T) {
+	defer func() {
+		configPath, readStdin, sourcePath = "", false, ""
+	}()
+
+	configPath, readStdin, sourcePath = "config.json", true, ""
+	if err := runAnalyze(nil, nil); err == nil {
+		t.Error("runAnalyze() accepted --stdin with --config")
+	}
+
+	configPath, readStdin, sourcePath = "", true, "app.log"
+	if err := runAnalyze(nil, nil); err == nil {
+		t.Error("runAnalyze() accepted --stdin with --path")
+	}
+}
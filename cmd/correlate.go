@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/correlate"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	correlateConfigPath string
+	correlateOutputPath string
+	correlateRequestID  string
+	correlateAll        bool
+	correlateLimit      int
+	correlateOnly       []string
+	correlateRedact     bool
+)
+
+// correlationReport is the JSON document written by --output.
+type correlationReport struct {
+	Requests     int                  `json:"requests"`
+	Inconsistent int                  `json:"inconsistent"`
+	Dropped      int                  `json:"dropped,omitempty"`
+	Timelines    []*correlate.Request `json:"timelines"`
+}
+
+var correlateCmd = &cobra.Command{
+	Use:   "correlate",
+	Short: "Join entries of all configured logs by request/trace ID",
+	Long: `The correlate command reads every configured log and joins the entries that
+share a request or trace ID (e.g. an X-Request-ID logged by nginx and by the
+backend). The ID is located with the "correlation" section of the configuration,
+which maps each log type to a parsed field or to a regular expression.
+
+By default it lists the requests that errored in one component but looked fine
+in another, with their timeline across the logs.`,
+	RunE: runCorrelate,
+}
+
+func runCorrelate(cmd *cobra.Command, args []string) error {
+	if correlateConfigPath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag)")
+	}
+
+	cfg, err := config.LoadConfig(correlateConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Correlation == nil {
+		return fmt.Errorf("configuration has no \"correlation\" section")
+	}
+
+	correlator, err := correlate.NewCorrelator(*cfg.Correlation)
+	if err != nil {
+		return fmt.Errorf("failed to set up correlation: %w", err)
+	}
+
+	redactor, err := redact.ForConfig(cfg.Redaction, correlateRedact)
+	if err != nil {
+		return fmt.Errorf("failed to set up redaction: %w", err)
+	}
+
+	selection := config.Selection{Only: correlateOnly}
+	if err := selection.Validate(cfg.Logs); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, logConfig := range cfg.Logs {
+		if selection.SkipReason(logConfig) != "" || !correlator.Correlates(logConfig.Type) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(logConfig config.LogConfig) {
+			defer wg.Done()
+			err := parser.ReadEntries(logConfig, func(e entry.Entry, _ error) error {
+				correlator.Add(logConfig.ID, logConfig.Type, e)
+				return nil
+			})
+			if err != nil {
+				fmt.Printf("✗ Failed to read log %s: %v\n", logConfig.ID, err)
+			}
+		}(logConfig)
+	}
+	wg.Wait()
+
+	if correlateRequestID != "" {
+		request, ok := correlator.Request(correlateRequestID)
+		if !ok {
+			return fmt.Errorf("request ID %s not found in any log", correlateRequestID)
+		}
+		redactEvents(redactor, request)
+		printTimeline(request)
+		return saveCorrelation([]*correlate.Request{request}, 1, boolToInt(request.Inconsistent()), correlator.Dropped())
+	}
+
+	requests := correlator.Requests(2)
+	var inconsistent []*correlate.Request
+	for _, r := range requests {
+		if r.Inconsistent() {
+			inconsistent = append(inconsistent, r)
+		}
+	}
+
+	fmt.Printf("Correlated %d requests across several logs, %d failed in one component but looked fine in another\n",
+		len(requests), len(inconsistent))
+	if dropped := correlator.Dropped(); dropped > 0 {
+		fmt.Printf("Warning: %d entries ignored after reaching %d distinct request IDs\n", dropped, correlate.DefaultMaxRequests)
+	}
+
+	shown := inconsistent
+	if correlateAll {
+		shown = requests
+	}
+	if correlateLimit > 0 && len(shown) > correlateLimit {
+		fmt.Printf("Showing the first %d of %d timelines (use --limit to change)\n", correlateLimit, len(shown))
+		shown = shown[:correlateLimit]
+	}
+
+	for _, r := range shown {
+		redactEvents(redactor, r)
+		fmt.Println()
+		printTimeline(r)
+	}
+
+	return saveCorrelation(shown, len(requests), len(inconsistent), correlator.Dropped())
+}
+
+func printTimeline(r *correlate.Request) {
+	fmt.Printf("=== Request %s ===\n", r.ID)
+	if r.Inconsistent() {
+		fmt.Printf("✗ Errored in %v, looked fine in %v\n", r.ErroredIn, r.OKIn)
+	}
+	for _, e := range r.Events {
+		ts := "-"
+		if !e.Time.IsZero() {
+			ts = e.Time.Format("2006-01-02T15:04:05.000Z07:00")
+		}
+		fmt.Printf("%s [%s] %-5s %s\n", ts, e.LogID, e.Level, e.Message)
+	}
+}
+
+// redactEvents masks sensitive data in the messages of a request timeline.
+func redactEvents(redactor *redact.Redactor, r *correlate.Request) {
+	for i := range r.Events {
+		r.Events[i].Message = redactor.String(r.Events[i].Message)
+	}
+}
+
+func saveCorrelation(timelines []*correlate.Request, requests, inconsistent, dropped int) error {
+	if correlateOutputPath == "" {
+		return nil
+	}
+
+	report := correlationReport{
+		Requests:     requests,
+		Inconsistent: inconsistent,
+		Dropped:      dropped,
+		Timelines:    timelines,
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal correlation report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(correlateOutputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directories for %s: %w", correlateOutputPath, err)
+	}
+	if err := os.WriteFile(correlateOutputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write correlation report to %s: %w", correlateOutputPath, err)
+	}
+
+	fmt.Printf("\nCorrelation report saved to: %s\n", correlateOutputPath)
+	return nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(correlateCmd)
+
+	correlateCmd.Flags().StringVarP(&correlateConfigPath, "config", "c", "", "Path to JSON configuration file (required)")
+	correlateCmd.Flags().StringVarP(&correlateOutputPath, "output", "o", "", "Path to JSON output file (optional)")
+	correlateCmd.Flags().StringVar(&correlateRequestID, "id", "", "Show the timeline of a single request ID")
+	correlateCmd.Flags().BoolVar(&correlateAll, "all", false, "Show every request seen in several logs, not only inconsistent ones")
+	correlateCmd.Flags().IntVar(&correlateLimit, "limit", 20, "Maximum number of timelines to print (0 for no limit)")
+	correlateCmd.Flags().BoolVar(&correlateRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the messages (enabled by a \"redaction\" config section)")
+	correlateCmd.Flags().StringSliceVar(&correlateOnly, "only", nil, "Correlate only the logs with these IDs (comma-separated)")
+
+	if err := correlateCmd.MarkFlagRequired("config"); err != nil {
+		panic(fmt.Sprintf("Failed to mark config flag as required: %v", err))
+	}
+
+	correlateCmd.Example = `  # List requests that failed in one component but looked fine in another
+  loganalyzer correlate --config config.json
+
+  # Show the timeline of one request across all logs
+  loganalyzer correlate -c config.json --id 5f2b9c1e-8d3a-4a7e-9f1c-2b6d8e4a1c3f
+
+  # Save every correlated timeline as JSON
+  loganalyzer correlate -c config.json --all --limit 0 -o correlation.json`
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCorrelateCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	accessLog := `10.0.0.1 - - [01/Jan/2024:10:00:01 +0000] "GET /api/orders HTTP/1.1" 200 120 "-" "curl/8.0" "req-1"
+10.0.0.1 - - [01/Jan/2024:10:00:02 +0000] "GET /api/users HTTP/1.1" 200 80 "-" "curl/8.0" "req-2"
+`
+	backendLog := `{"time":"2024-01-01T10:00:01Z","level":"error","msg":"order lookup failed","request_id":"req-1"}
+{"time":"2024-01-01T10:00:02Z","level":"info","msg":"users listed","request_id":"req-2"}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "access.log"), []byte(accessLog), 0644); err != nil {
+		t.Fatalf("Failed to write access log: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "backend.log"), []byte(backendLog), 0644); err != nil {
+		t.Fatalf("Failed to write backend log: %v", err)
+	}
+
+	configContent := `{
+		"logs": [
+			{"id": "web", "path": "` + filepath.Join(tempDir, "access.log") + `", "type": "nginx access"},
+			{"id": "api", "path": "` + filepath.Join(tempDir, "backend.log") + `", "type": "backend json"}
+		],
+		"correlation": {
+			"fields": {
+				"nginx access": {"pattern": "\"(req-[0-9]+)\"$"},
+				"backend json": {"field": "request_id"}
+			}
+		}
+	}`
+	configFile := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	correlateConfigPath = configFile
+	correlateOutputPath = filepath.Join(tempDir, "correlation.json")
+	correlateAll = true
+	defer func() {
+		correlateConfigPath, correlateOutputPath, correlateAll = "", "", false
+	}()
+
+	if err := runCorrelate(nil, nil); err != nil {
+		t.Fatalf("runCorrelate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(correlateOutputPath)
+	if err != nil {
+		t.Fatalf("Failed to read correlation report: %v", err)
+	}
+
+	var report correlationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to unmarshal correlation report: %v", err)
+	}
+
+	if report.Requests != 2 || report.Inconsistent != 1 {
+		t.Errorf("report = %d requests, %d inconsistent, want 2 and 1", report.Requests, report.Inconsistent)
+	}
+	if len(report.Timelines) != 2 || report.Timelines[0].ID != "req-1" || !report.Timelines[0].Inconsistent() {
+		t.Errorf("unexpected timelines: %+v", report.Timelines)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"loganalyzer/internal/diff"
+	"loganalyzer/internal/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD.json NEW.json",
+	Short: "Compare two saved analysis reports",
+	Long: `The diff command compares two reports written by "analyze --output", e.g. the
+reports of two nightly runs or of the runs before and after a deploy.
+
+It lists the logs added or removed, the logs whose status changed, the changes
+in per-level counts and error rates, and the message templates that did not
+exist in the old report. Logs that started failing, whose error rate went up or
+that show new templates are marked as regressions.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffFormat != "text" && diffFormat != "json" {
+		return fmt.Errorf("invalid --format %q (use text or json)", diffFormat)
+	}
+
+	old, err := reporter.LoadResults(args[0])
+	if err != nil {
+		return err
+	}
+	current, err := reporter.LoadResults(args[1])
+	if err != nil {
+		return err
+	}
+
+	report := diff.Compare(old, current)
+
+	if diffFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	report.Print(os.Stdout)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text or json")
+
+	diffCmd.Example = `  # Compare last night's report with tonight's
+  loganalyzer diff results/2024-01-01.json results/2024-01-02.json
+
+  # Machine-readable output
+  loganalyzer diff before.json after.json --format json`
+}
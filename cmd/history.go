@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/history"
+	"loganalyzer/internal/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyConfigPath string
+	historyFile       string
+	historyLogIDs     []string
+	historyDays       int
+	historyFormat     string
+	historyPrune      bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show per-log trends recorded by previous analyze runs",
+	Long: `The history command reads the history store filled by analyze when the
+configuration has a "history" section, and shows per log the error and warning
+counts per day, failed runs, and the current success or failure streak.
+
+Records older than the configured retention are pruned after each analyze run,
+or on demand with --prune.`,
+	RunE: runHistory,
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	if historyFormat != "text" && historyFormat != "json" {
+		return fmt.Errorf("invalid --format %q (use text or json)", historyFormat)
+	}
+
+	path := historyFile
+	var retention time.Duration
+	if historyConfigPath != "" {
+		cfg, err := config.LoadConfig(historyConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if cfg.History != nil {
+			retention = cfg.History.RetentionPeriod()
+			if path == "" {
+				path = cfg.History.Path
+			}
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("no history store: set \"history\" in the configuration (--config) or use --file")
+	}
+
+	store := history.NewStore(path)
+	if historyPrune {
+		if retention == 0 {
+			return fmt.Errorf("--prune needs a retention in the \"history\" configuration")
+		}
+		removed, err := store.Prune(time.Now().Add(-retention))
+		if err != nil {
+			return fmt.Errorf("failed to prune history: %w", err)
+		}
+		fmt.Printf("Pruned %d history records older than %s\n", removed, retention)
+	}
+
+	var since time.Time
+	if historyDays > 0 {
+		now := time.Now()
+		since = time.Date(now.Year(), now.Month(), now.Day()-historyDays+1, 0, 0, 0, 0, now.Location())
+	}
+	records, err := store.Load(func(r history.Record) bool {
+		if !since.IsZero() && r.Run.Before(since) {
+			return false
+		}
+		return len(historyLogIDs) == 0 || slices.Contains(historyLogIDs, r.LogID)
+	})
+	if err != nil {
+		return err
+	}
+
+	trends := history.Trends(records, time.Local)
+
+	if historyFormat == "json" {
+		data, err := json.MarshalIndent(trends, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(trends) == 0 {
+		fmt.Printf("No history recorded in %s\n", path)
+		return nil
+	}
+	for i, trend := range trends {
+		if i > 0 {
+			fmt.Println()
+		}
+		printTrend(trend)
+	}
+	return nil
+}
+
+func printTrend(trend history.Trend) {
+	fmt.Printf("=== %s ===\n", trend.LogID)
+	fmt.Printf("%-10s  %4s  %4s  %8s  %7s  %7s  %10s\n", "Date", "Runs", "Fail", "Lines", "Errors", "Warn", "Error rate")
+	for _, day := range trend.Days {
+		fmt.Printf("%-10s  %4d  %4d  %8d  %7d  %7d  %9.2f%%\n",
+			day.Date, day.Runs, day.Failures, day.Lines, day.Errors, day.Warnings, day.ErrorRate()*100)
+	}
+
+	if len(trend.Days) > 1 {
+		errors := make([]int, len(trend.Days))
+		for i, day := range trend.Days {
+			errors[i] = day.Errors
+		}
+		fmt.Printf("Errors per day: %s\n", reporter.Sparkline(errors, len(errors)))
+	}
+
+	fmt.Printf("Current streak: %d %s run(s)", trend.Current.Runs, trend.Current.Status)
+	if trend.LongestFailure > 0 {
+		fmt.Printf(", longest failure streak: %d", trend.LongestFailure)
+	}
+	fmt.Println()
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVarP(&historyConfigPath, "config", "c", "", "Path to JSON configuration file with a history section")
+	historyCmd.Flags().StringVar(&historyFile, "file", "", "Path to the history file (overrides the configuration)")
+	historyCmd.Flags().StringSliceVar(&historyLogIDs, "log", nil, "Show only these log IDs (comma-separated)")
+	historyCmd.Flags().IntVar(&historyDays, "days", 14, "Number of days to show, including today (0 for all)")
+	historyCmd.Flags().StringVar(&historyFormat, "format", "text", "Output format: text or json")
+	historyCmd.Flags().BoolVar(&historyPrune, "prune", false, "Remove records older than the configured retention first")
+
+	historyCmd.Example = `  # Trends of every log over the last two weeks
+  loganalyzer history -c config.json
+
+  # One log over the last 90 days, as JSON
+  loganalyzer history -c config.json --log web-server-1 --days 90 --format json
+
+  # Read a history file directly
+  loganalyzer history --file /var/lib/loganalyzer/history.jsonl`
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/listener"
+	"loganalyzer/internal/notify"
+	"loganalyzer/internal/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listenConfigPath string
+	listenUDP        string
+	listenTCP        string
+	listenTLSCert    string
+	listenTLSKey     string
+	listenID         string
+	listenOutput     string
+	listenInterval   time.Duration
+	listenTemplates  int
+	listenBucket     time.Duration
+	listenAnomalies  bool
+	listenRedact     bool
+)
+
+var listenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Receive syslog messages over the network and analyze them live",
+	Long: `The listen command is a syslog receiver: network devices and servers send
+their messages to it directly, over UDP or TCP, optionally with TLS. TCP
+accepts both the octet-counting and the newline framing (RFC 6587).
+
+The messages are parsed as syslog and aggregated as a single log, with the
+same stats, templates, histograms, anomaly detection and alerting rules as
+analyze. A snapshot of the result is printed, and saved with --output, at
+every --interval and when the receiver stops (Ctrl+C).
+
+With --config, the rules (those without log_id or with the --id of the
+receiver), the anomaly settings and the notifiers of the configuration file
+apply to the received messages; its logs are not read. Notifications are sent
+once per fired alert or detected anomaly.`,
+	RunE: runListen,
+}
+
+func runListen(cmd *cobra.Command, args []string) error {
+	if listenUDP == "" && listenTCP == "" {
+		return fmt.Errorf("nothing to listen on (use --udp or --tcp)")
+	}
+	if (listenTLSCert == "") != (listenTLSKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	if listenTLSCert != "" && listenTCP == "" {
+		return fmt.Errorf("TLS needs a TCP listener (use --tcp)")
+	}
+	if listenInterval < time.Second {
+		return fmt.Errorf("invalid --interval %s: must be at least 1s", listenInterval)
+	}
+	if listenBucket != 0 && listenBucket < time.Second {
+		return fmt.Errorf("invalid --bucket %s: must be at least 1s", listenBucket)
+	}
+
+	logConfig := config.LogConfig{ID: listenID, Path: listenSourceName(), Type: "syslog"}
+	cfg, err := listenConfig(logConfig)
+	if err != nil {
+		return err
+	}
+
+	analyzer, err := parser.NewAnalyzerWithOptions(cfg, parser.Options{
+		TemplateTop: listenTemplates,
+		Bucket:      listenBucket,
+		Anomalies:   listenAnomalies,
+		Live:        true,
+		Redact:      listenRedact,
+	})
+	if err != nil {
+		return err
+	}
+
+	receiver := listener.NewReceiver(analyzer.NewAggregator(logConfig))
+	receiver.OnError(func(err error) {
+		fmt.Printf("✗ %v\n", err)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 2)
+	if listenUDP != "" {
+		conn, err := net.ListenPacket("udp", listenUDP)
+		if err != nil {
+			return fmt.Errorf("failed to listen on udp %s: %w", listenUDP, err)
+		}
+		fmt.Printf("Listening for syslog on udp %s\n", conn.LocalAddr())
+		go func() { errCh <- receiver.ServeUDP(ctx, conn) }()
+	}
+	if listenTCP != "" {
+		l, err := listenTCPOrTLS()
+		if err != nil {
+			return err
+		}
+		go func() { errCh <- receiver.ServeTCP(ctx, l) }()
+	}
+
+	snapshots, err := newListenSnapshots(cfg, listenOutput)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(listenInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			snapshots.write(receiver, false)
+		case err := <-errCh:
+			if err != nil {
+				stop()
+				snapshots.write(receiver, true)
+				return fmt.Errorf("listener stopped: %w", err)
+			}
+		case <-ctx.Done():
+			fmt.Println("\nShutting down...")
+			snapshots.write(receiver, true)
+			return nil
+		}
+	}
+}
+
+// listenSourceName describes the listeners, as the path of the log in the
+// results.
+func listenSourceName() string {
+	var parts []string
+	if listenUDP != "" {
+		parts = append(parts, "udp "+listenUDP)
+	}
+	if listenTCP != "" {
+		transport := "tcp "
+		if listenTLSCert != "" {
+			transport = "tls "
+		}
+		parts = append(parts, transport+listenTCP)
+	}
+	return "syslog " + strings.Join(parts, ", ")
+}
+
+// listenConfig returns the configuration applied to the received messages:
+// the rules, anomaly and notifier sections of --config, if given, with the
+// receiver as only log.
+func listenConfig(logConfig config.LogConfig) (*config.Config, error) {
+	if listenConfigPath == "" {
+		return config.ForLog(logConfig)
+	}
+
+	cfg, err := config.LoadConfig(listenConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if _, err := config.ForLog(logConfig); err != nil {
+		return nil, err
+	}
+	cfg.Logs = []config.LogConfig{logConfig}
+	return cfg, nil
+}
+
+func listenTCPOrTLS() (net.Listener, error) {
+	l, err := net.Listen("tcp", listenTCP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on tcp %s: %w", listenTCP, err)
+	}
+	if listenTLSCert == "" {
+		fmt.Printf("Listening for syslog on tcp %s\n", l.Addr())
+		return l, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(listenTLSCert, listenTLSKey)
+	if err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	fmt.Printf("Listening for syslog over TLS on tcp %s\n", l.Addr())
+	return tls.NewListener(l, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// listenSnapshots reports the result of the received messages through a
+// Reporter, and notifies the alerts and anomalies not notified yet.
+type listenSnapshots struct {
+	outputPath string
+	dispatcher *notify.Dispatcher
+	messages   int
+	notified   map[string]bool
+}
+
+func newListenSnapshots(cfg *config.Config, outputPath string) (*listenSnapshots, error) {
+	s := &listenSnapshots{outputPath: outputPath, notified: make(map[string]bool)}
+	if len(cfg.Notifiers) > 0 {
+		dispatcher, err := notify.NewDispatcher(cfg.Notifiers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up notifiers: %w", err)
+		}
+		s.dispatcher = dispatcher
+	}
+	return s, nil
+}
+
+// write reports a snapshot, unless no message arrived since the previous one
+// and it is not the final snapshot.
+func (s *listenSnapshots) write(receiver *listener.Receiver, final bool) {
+	messages := receiver.Messages()
+	if messages == s.messages && !final {
+		return
+	}
+	s.messages = messages
+
+	result := receiver.Snapshot()
+	results := reporter.NewReporter()
+	results.AddResult(result)
+
+	fmt.Printf("\n[%s] %d messages received\n", time.Now().Format("2006-01-02 15:04:05"), messages)
+	results.PrintSummary()
+
+	if s.outputPath != "" {
+		if err := results.SaveToFile(formatOutputPath(s.outputPath)); err != nil {
+			fmt.Printf("✗ Failed to save snapshot: %v\n", err)
+		}
+	}
+
+	s.notify(result)
+}
+
+// notify sends the alerts and anomalies of the result that were not in a
+// previous snapshot.
+func (s *listenSnapshots) notify(result reporter.AnalysisResult) {
+	if s.dispatcher == nil {
+		return
+	}
+
+	var alerts []reporter.Alert
+	for _, alert := range result.Alerts {
+		if key := "alert:" + alert.Rule; !s.notified[key] {
+			s.notified[key] = true
+			alerts = append(alerts, alert)
+		}
+	}
+	var anomalies []reporter.Anomaly
+	for _, anomaly := range result.Anomalies {
+		if key := "anomaly:" + anomaly.Kind + ":" + anomaly.Start.String(); !s.notified[key] {
+			s.notified[key] = true
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	if len(alerts) == 0 && len(anomalies) == 0 {
+		return
+	}
+
+	result.Alerts, result.Anomalies = alerts, anomalies
+	if err := s.dispatcher.Notify(context.Background(), []reporter.AnalysisResult{result}); err != nil {
+		fmt.Printf("✗ Notification failed: %v\n", err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(listenCmd)
+
+	listenCmd.Flags().StringVarP(&listenConfigPath, "config", "c", "", "Configuration file whose rules, anomaly settings and notifiers apply (optional)")
+	listenCmd.Flags().StringVar(&listenUDP, "udp", ":5514", "UDP address to receive syslog on (\"\" disables)")
+	listenCmd.Flags().StringVar(&listenTCP, "tcp", ":5514", "TCP address to receive syslog on (\"\" disables)")
+	listenCmd.Flags().StringVar(&listenTLSCert, "tls-cert", "", "Certificate file enabling TLS on the TCP listener")
+	listenCmd.Flags().StringVar(&listenTLSKey, "tls-key", "", "Private key file of --tls-cert")
+	listenCmd.Flags().StringVar(&listenID, "id", "syslog", "ID of the received log in results and rules")
+	listenCmd.Flags().StringVarP(&listenOutput, "output", "o", "", "Save each snapshot to this JSON report file (with timestamp)")
+	listenCmd.Flags().DurationVar(&listenInterval, "interval", time.Minute, "Time between snapshots")
+	listenCmd.Flags().IntVar(&listenTemplates, "templates", 0, "Report the top N message templates (0 disables)")
+	listenCmd.Flags().DurationVar(&listenBucket, "bucket", 0, "Histogram bucket size (0 disables)")
+	listenCmd.Flags().BoolVar(&listenAnomalies, "anomalies", false, "Detect error spikes, volume spikes and silences")
+	listenCmd.Flags().BoolVar(&listenRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the results (enabled by a \"redaction\" config section)")
+
+	listenCmd.Example = `  # Receive syslog on UDP and TCP port 5514, a snapshot every minute
+  loganalyzer listen
+
+  # Only TCP with TLS on the standard port, snapshots saved every 5 minutes
+  loganalyzer listen --udp "" --tcp :6514 --tls-cert server.crt --tls-key server.key \
+    --interval 5m -o syslog.json
+
+  # Apply the alerting rules and notifiers of a configuration file
+  loganalyzer listen -c config.json --id edge-routers --templates 10
+
+  # Send a test message
+  logger --server localhost --port 5514 --udp "link down on ge-0/0/1"`
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/groupby"
+	"loganalyzer/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryConfigPath string
+	queryGroupBy    string
+	queryAggregates string
+	queryTop        int
+	querySort       string
+	queryWhere      string
+	queryOnly       []string
+	queryTags       []string
+	queryPath       string
+	queryType       string
+	queryFormat     string
+	queryOutput     string
+	queryRedact     bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Summarize the entries of all configured logs by group",
+	Long: `The query command computes SQL-like summaries of the parsed entries of all
+configured logs, read concurrently: the entries are grouped by the values of the
+--group-by fields, and each group gets the --agg aggregates:
+
+  count             number of entries
+  count(FIELD)      number of entries having the field
+  sum, avg, min, max(FIELD)
+                    of the numeric values of the field
+  pNN(FIELD)        percentile of the numeric values, e.g. p50, p95, p99.9
+
+Percentiles are estimated within 1% with a mergeable sketch (DDSketch), so the
+partial results of the logs combine exactly. Entries where a field is missing
+or not a number are ignored by its aggregates, and group under "-" when it is
+a group-by field. --where restricts the entries summarized.
+
+The groups are printed as a table sorted by the first aggregate, largest first,
+or as JSON or CSV with --format. --output saves them to a file too, as CSV when
+its name ends with .csv, as JSON otherwise.`,
+	RunE: runQuery,
+}
+
+func runQuery(cmd *cobra.Command, args []string) error {
+	if queryConfigPath == "" && queryPath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag, or --path for a single log)")
+	}
+	if queryConfigPath != "" && queryPath != "" {
+		return fmt.Errorf("--path queries a single log without configuration file, it cannot be combined with --config")
+	}
+	if queryFormat != "table" && queryFormat != "json" && queryFormat != "csv" {
+		return fmt.Errorf("invalid --format %q (use table, json or csv)", queryFormat)
+	}
+	if queryTop < 0 {
+		return fmt.Errorf("invalid --top %d: must not be negative", queryTop)
+	}
+
+	aggregates, err := groupby.ParseAggregates(queryAggregates)
+	if err != nil {
+		return fmt.Errorf("invalid --agg: %w", err)
+	}
+	groupBy := groupby.ParseFields(queryGroupBy)
+	// Reject an invalid --sort before reading the logs.
+	if _, err := groupby.NewTable(groupBy, aggregates).Result(querySort, queryTop); err != nil {
+		return fmt.Errorf("invalid --sort: %w", err)
+	}
+
+	where, err := parseWhere(queryWhere)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadLogsConfig(queryConfigPath, queryPath, queryType)
+	if err != nil {
+		return err
+	}
+
+	selection := config.Selection{Only: queryOnly, Tags: queryTags}
+	if err := selection.Validate(cfg.Logs); err != nil {
+		return err
+	}
+
+	redactor, err := redact.ForConfig(cfg.Redaction, queryRedact)
+	if err != nil {
+		return fmt.Errorf("failed to set up redaction: %w", err)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	table := groupby.NewTable(groupBy, aggregates)
+	for _, logConfig := range cfg.Logs {
+		if selection.SkipReason(logConfig) != "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(logConfig config.LogConfig) {
+			defer wg.Done()
+			partial := groupby.NewTable(groupBy, aggregates)
+			partial.MaskKeys(redactor.Field)
+			err := parser.ReadEntries(logConfig, func(e entry.Entry, _ error) error {
+				if where.Match(e) {
+					partial.Add(e)
+				}
+				return nil
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "✗ Failed to read log %s: %v\n", logConfig.ID, err)
+				failed++
+				return
+			}
+			table.Merge(partial)
+		}(logConfig)
+	}
+	wg.Wait()
+
+	result, err := table.Result(querySort, queryTop)
+	if err != nil {
+		return err
+	}
+
+	if err := writeQueryResult(os.Stdout, result, queryFormat); err != nil {
+		return err
+	}
+	if queryOutput != "" {
+		if err := saveQueryResult(result, queryOutput); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("query incomplete: %d logs could not be read", failed)
+	}
+	return nil
+}
+
+func writeQueryResult(w io.Writer, result *groupby.Result, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal query result to JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		return result.WriteCSV(w)
+	}
+	result.Print(w)
+	return nil
+}
+
+// saveQueryResult writes the result to path, as CSV for a .csv file and as
+// JSON otherwise.
+func saveQueryResult(result *groupby.Result, path string) error {
+	format := "json"
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		format = "csv"
+	}
+	var buf bytes.Buffer
+	if err := writeQueryResult(&buf, result, format); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directories for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write query result to %s: %w", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Query result saved to: %s\n", path)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	queryCmd.Flags().StringVarP(&queryConfigPath, "config", "c", "", "Path to JSON configuration file (required unless --path is given)")
+	queryCmd.Flags().StringVar(&queryGroupBy, "group-by", "", "Fields to group the entries by (comma-separated, e.g. status,path)")
+	queryCmd.Flags().StringVar(&queryAggregates, "agg", "count", "Aggregates of each group (e.g. count,avg(bytes),p95(request_time))")
+	queryCmd.Flags().IntVar(&queryTop, "top", 20, "Number of groups to show (0 for all)")
+	queryCmd.Flags().StringVar(&querySort, "sort", "", "Aggregate (largest first) or group-by field to sort the groups by (default the first aggregate)")
+	queryCmd.Flags().StringVar(&queryWhere, "where", "", "Summarize only the entries matching this query (e.g. 'path ~ \"^/api\"')")
+	queryCmd.Flags().StringSliceVar(&queryOnly, "only", nil, "Query only the logs with these IDs (comma-separated)")
+	queryCmd.Flags().StringSliceVar(&queryTags, "tag", nil, "Query only the logs having at least one of these tags")
+	queryCmd.Flags().StringVar(&queryPath, "path", "", "Query this single log (file, URL or \"-\" for stdin), without configuration file")
+	queryCmd.Flags().StringVar(&queryType, "type", "text", "Type of the log of --path (e.g. jsonl, nginx access, syslog)")
+	queryCmd.Flags().StringVar(&queryFormat, "format", "table", "Output format: table, json or csv")
+	queryCmd.Flags().StringVarP(&queryOutput, "output", "o", "", "Also save the result to this file (CSV for .csv, JSON otherwise)")
+	queryCmd.Flags().BoolVar(&queryRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the group values (enabled by a \"redaction\" config section)")
+
+	queryCmd.Example = `  # The 20 busiest status and path pairs, with their 95th percentile latency
+  loganalyzer query -c config.json --group-by status,path --agg 'count,p95(request_time)' --top 20
+
+  # Bytes served per HTTP method and status, in ascending order of the status
+  loganalyzer query -c config.json --tag nginx --group-by method,status --agg 'sum(bytes),avg(bytes)' --sort status --top 0
+
+  # Latency percentiles of the API, overall, saved as CSV
+  loganalyzer query -c config.json --where 'path ~ "^/api"' --agg 'p50(request_time),p99(request_time)' -o latency.csv`
+}
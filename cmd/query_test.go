@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueryCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	logs := map[string]string{
+		"web1.log": `10.0.0.1 - - [24/May/2024:10:00:00 +0000] "GET /api/orders HTTP/1.1" 200 120 "-" "curl/8.0" 0.120
+10.0.0.1 - - [24/May/2024:10:00:01 +0000] "GET /api/orders HTTP/1.1" 500 12 "-" "curl/8.0" 1.500
+10.0.0.2 - - [24/May/2024:10:00:02 +0000] "GET / HTTP/1.1" 200 900 "-" "Googlebot/2.1" 0.010
+`,
+		"web2.log": `10.0.0.3 - - [24/May/2024:10:00:03 +0000] "GET /api/orders HTTP/1.1" 200 100 "-" "curl/8.0" 0.300
+10.0.0.3 - - [24/May/2024:10:00:04 +0000] "POST /api/orders HTTP/1.1" 201 10 "-" "curl/8.0" 0.200
+`,
+	}
+	for name, content := range logs {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test log: %v", err)
+		}
+	}
+	configContent := `[
+		{"id": "web1", "path": "` + filepath.Join(tempDir, "web1.log") + `", "type": "nginx access"},
+		{"id": "web2", "path": "` + filepath.Join(tempDir, "web2.log") + `", "type": "nginx access"}
+	]`
+	configFile := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	stdout, err := os.CreateTemp(tempDir, "stdout")
+	if err != nil {
+		t.Fatalf("Failed to create stdout file: %v", err)
+	}
+	savedStdout := os.Stdout
+	os.Stdout = stdout
+	queryConfigPath, queryGroupBy, queryAggregates = configFile, "status,path", "count,max(request_time),sum(bytes)"
+	queryWhere, queryFormat, queryOutput = `path ~ "^/api"`, "csv", filepath.Join(tempDir, "result.json")
+	defer func() {
+		os.Stdout = savedStdout
+		queryConfigPath, queryGroupBy, queryAggregates = "", "", "count"
+		queryWhere, queryFormat, queryOutput = "", "table", ""
+	}()
+
+	err = runQuery(nil, nil)
+	os.Stdout = savedStdout
+	if err != nil {
+		t.Fatalf("runQuery() error = %v", err)
+	}
+
+	data, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("Failed to read stdout: %v", err)
+	}
+	want := "status,path,count,max(request_time),sum(bytes)\n" +
+		"200,/api/orders,2,0.3,220\n" +
+		"201,/api/orders,1,0.2,10\n" +
+		"500,/api/orders,1,1.5,12\n"
+	if string(data) != want {
+		t.Errorf("runQuery() printed\n%s\nwant\n%s", data, want)
+	}
+	if _, err := os.Stat(queryOutput); err != nil {
+		t.Errorf("runQuery() did not save the result: %v", err)
+	}
+
+	queryAggregates = "p95"
+	if err := runQuery(nil, nil); err == nil {
+		t.Error("runQuery() accepted a percentile without field")
+	}
+	queryAggregates, querySort = "count", "bytes"
+	defer func() { querySort = "" }()
+	if err := runQuery(nil, nil); err == nil {
+		t.Error("runQuery() accepted a --sort that is not a column")
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/query"
+	"loganalyzer/internal/redact"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchConfigPath string
+	searchWhere      string
+	searchOnly       []string
+	searchTags       []string
+	searchPath       string
+	searchType       string
+	searchLimit      int
+	searchCount      bool
+	searchJSON       bool
+	searchRedact     bool
+)
+
+// errSearchLimit stops reading the logs once --limit entries matched.
+var errSearchLimit = errors.New("search limit reached")
+
+// searchHit is a matching entry as printed by --json.
+type searchHit struct {
+	LogID   string            `json:"log_id"`
+	Entry   int               `json:"entry"`
+	Time    *time.Time        `json:"time,omitempty"`
+	Level   string            `json:"level"`
+	Message string            `json:"message,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Raw     string            `json:"raw"`
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Print the entries of the configured logs matching a query",
+	Long: `The search command reads the configured logs one after the other and prints
+the entries matching the --where query, prefixed with their log ID and entry
+number (the line number, unless multi-line entries are assembled).
+
+A query compares the fields of the parsed entries, whatever the log type:
+
+  level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"
+
+Comparisons are NAME OP VALUE with the operators =, !=, <, <=, >, >=, ~ (regular
+expression match) and !~, combined with AND, OR, NOT and parentheses. A bare
+NAME tests that the field is set. Besides the fields of each log type, every
+entry has a level, message, raw line and time. The same queries select the
+entries of analyze and timeline with --where.`,
+	RunE: runSearch,
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	if searchWhere == "" {
+		return fmt.Errorf("query is required (use --where)")
+	}
+	if searchConfigPath == "" && searchPath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag, or --path for a single log)")
+	}
+	if searchConfigPath != "" && searchPath != "" {
+		return fmt.Errorf("--path searches a single log without configuration file, it cannot be combined with --config")
+	}
+	if searchLimit < 0 {
+		return fmt.Errorf("invalid --limit %d: must not be negative", searchLimit)
+	}
+
+	where, err := parseWhere(searchWhere)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadLogsConfig(searchConfigPath, searchPath, searchType)
+	if err != nil {
+		return err
+	}
+
+	selection := config.Selection{Only: searchOnly, Tags: searchTags}
+	if err := selection.Validate(cfg.Logs); err != nil {
+		return err
+	}
+
+	redactor, err := redact.ForConfig(cfg.Redaction, searchRedact)
+	if err != nil {
+		return fmt.Errorf("failed to set up redaction: %w", err)
+	}
+
+	out := os.Stdout
+	matched, failed := 0, 0
+	for _, logConfig := range cfg.Logs {
+		if selection.SkipReason(logConfig) != "" {
+			continue
+		}
+
+		entries, hits := 0, 0
+		err := parser.ReadEntries(logConfig, func(e entry.Entry, parseErr error) error {
+			entries++
+			if !where.Match(e) {
+				return nil
+			}
+			hits++
+			matched++
+			if !searchCount {
+				if err := writeSearchHit(out, logConfig.ID, entries, e, redactor); err != nil {
+					return err
+				}
+			}
+			if searchLimit > 0 && matched >= searchLimit {
+				return errSearchLimit
+			}
+			return nil
+		})
+		if searchCount {
+			fmt.Fprintf(out, "%s: %d of %d entries\n", logConfig.ID, hits, entries)
+		}
+		if errors.Is(err, errSearchLimit) {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", logConfig.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("search incomplete: %d logs could not be read", failed)
+	}
+	if matched == 0 {
+		fmt.Fprintln(os.Stderr, "- No entries matched")
+	}
+	return nil
+}
+
+// loadLogsConfig returns the configuration file at configPath or, when path
+// is set instead, the configuration of that single log of the given type.
+func loadLogsConfig(configPath, path, logType string) (*config.Config, error) {
+	if path == "" {
+		cfg, err := config.LoadConfig(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+		return cfg, nil
+	}
+
+	id := "stdin"
+	if !config.IsStdinPath(path) {
+		id = filepath.Base(path)
+	}
+	return config.ForLog(config.LogConfig{ID: id, Path: path, Type: logType})
+}
+
+// parseWhere compiles the query of a --where flag, nil when it is empty.
+// Syntax errors point at their position in the query.
+func parseWhere(src string) (*query.Query, error) {
+	if src == "" {
+		return nil, nil
+	}
+	where, err := query.Parse(src)
+	var qerr *query.Error
+	if errors.As(err, &qerr) {
+		return nil, fmt.Errorf("invalid --where: %w\n%s", err, qerr.Pointer(src))
+	}
+	return where, err
+}
+
+// writeSearchHit prints a matching entry, as JSON with --json.
+func writeSearchHit(w io.Writer, logID string, n int, e entry.Entry, redactor *redact.Redactor) error {
+	raw := redactor.String(e.Raw)
+	if !searchJSON {
+		_, err := fmt.Fprintf(w, "%s:%d: %s\n", logID, n, raw)
+		return err
+	}
+
+	hit := searchHit{
+		LogID:   logID,
+		Entry:   n,
+		Level:   e.Level.String(),
+		Message: redactor.String(e.Message),
+		Raw:     raw,
+	}
+	if !e.Time.IsZero() {
+		hit.Time = &e.Time
+	}
+	if len(e.Fields) > 0 {
+		hit.Fields = make(map[string]string, len(e.Fields))
+		for name, value := range e.Fields {
+			hit.Fields[name] = redactor.Field(name, value)
+		}
+	}
+	data, err := json.Marshal(hit)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().StringVarP(&searchConfigPath, "config", "c", "", "Path to JSON configuration file (required unless --path is given)")
+	searchCmd.Flags().StringVar(&searchWhere, "where", "", "Query selecting the entries to print (required)")
+	searchCmd.Flags().StringSliceVar(&searchOnly, "only", nil, "Search only the logs with these IDs (comma-separated)")
+	searchCmd.Flags().StringSliceVar(&searchTags, "tag", nil, "Search only the logs having at least one of these tags")
+	searchCmd.Flags().StringVar(&searchPath, "path", "", "Search this single log (file, URL or \"-\" for stdin), without configuration file")
+	searchCmd.Flags().StringVar(&searchType, "type", "text", "Type of the log of --path (e.g. jsonl, nginx access, syslog)")
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 0, "Stop after this many matching entries (0 for no limit)")
+	searchCmd.Flags().BoolVar(&searchCount, "count", false, "Print the number of matching entries of each log instead of the entries")
+	searchCmd.Flags().BoolVar(&searchJSON, "json", false, "Print the matching entries as NDJSON with their parsed fields")
+	searchCmd.Flags().BoolVar(&searchRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the entries (enabled by a \"redaction\" config section)")
+
+	searchCmd.Example = `  # Server errors on the API, excluding crawlers
+  loganalyzer search -c config.json --where 'level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"'
+
+  # How many slow requests each web server logged
+  loganalyzer search -c config.json --tag nginx --where 'request_time > 1.5' --count
+
+  # The first 20 errors of a single log, as JSON
+  loganalyzer search --path app.jsonl --type jsonl --where 'level = ERROR' --limit 20 --json`
+}
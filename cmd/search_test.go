@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/redact"
+)
+
+func TestSearchCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	appLog := `{"level":"info","msg":"ok","status":200,"path":"/api/orders"}
+{"level":"error","msg":"upstream failed","status":502,"path":"/api/orders"}
+{"level":"error","msg":"crawler","status":500,"path":"/api/search","user_agent":"Googlebot"}
+{"level":"warn","msg":"slow","status":503,"path":"/health"}
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "app.jsonl"), []byte(appLog), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+	configContent := `[{"id": "app", "path": "` + filepath.Join(tempDir, "app.jsonl") + `", "type": "jsonl"}]`
+	configFile := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		where string
+		count bool
+		limit int
+		want  string
+	}{
+		{
+			name:  "entries",
+			where: `level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"`,
+			want:  `app:2: {"level":"error","msg":"upstream failed","status":502,"path":"/api/orders"}` + "\n",
+		},
+		{name: "count", where: "status >= 500", count: true, want: "app: 3 of 4 entries\n"},
+		{name: "limit", where: "status >= 500", count: true, limit: 2, want: "app: 2 of 3 entries\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, err := os.CreateTemp(tempDir, "stdout")
+			if err != nil {
+				t.Fatalf("Failed to create stdout file: %v", err)
+			}
+			savedStdout := os.Stdout
+			os.Stdout = stdout
+			searchConfigPath, searchWhere, searchCount, searchLimit = configFile, tt.where, tt.count, tt.limit
+			defer func() {
+				os.Stdout = savedStdout
+				searchConfigPath, searchWhere, searchCount, searchLimit = "", "", false, 0
+			}()
+
+			err = runSearch(nil, nil)
+			os.Stdout = savedStdout
+			if err != nil {
+				t.Fatalf("runSearch() error = %v", err)
+			}
+
+			data, err := os.ReadFile(stdout.Name())
+			if err != nil {
+				t.Fatalf("Failed to read stdout: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("runSearch() printed %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSearchHitRedacted(t *testing.T) {
+	redactor, err := redact.New(config.RedactionConfig{})
+	if err != nil {
+		t.Fatalf("redact.New() error = %v", err)
+	}
+	searchJSON = true
+	defer func() { searchJSON = false }()
+
+	e := entry.Entry{
+		Level:  entry.LevelError,
+		Raw:    `{"password":"hunter2","user":"bob@example.com"}`,
+		Fields: map[string]string{"password": "hunter2", "user": "bob@example.com"},
+	}
+	var buf bytes.Buffer
+	if err := writeSearchHit(&buf, "app", 1, e, redactor); err != nil {
+		t.Fatalf("writeSearchHit() error = %v", err)
+	}
+	if got := buf.String(); strings.Contains(got, "hunter2") || strings.Contains(got, "bob@example.com") {
+		t.Errorf("writeSearchHit() = %s, want the password and email masked", got)
+	}
+}
+
+func TestParseWhere(t *testing.T) {
+	where, err := parseWhere("")
+	if where != nil || err != nil {
+		t.Errorf("parseWhere(\"\") = %v, %v, want nil", where, err)
+	}
+
+	_, err = parseWhere("status >= 500 AND")
+	if err == nil || !strings.Contains(err.Error(), "column 18") || !strings.HasSuffix(err.Error(), "\n  status >= 500 AND\n                   ^") {
+		t.Errorf("parseWhere() error = %q, want the position of the missing condition", err)
+	}
+}
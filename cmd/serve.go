@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/notify"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/server"
+	"loganalyzer/internal/tracing"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveConfigPath string
+	serveAddr       string
+	serveTemplates  int
+	serveBucket     time.Duration
+	serveAnomalies  bool
+	serveRedact     bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the analyzer as an HTTP service",
+	Long: `The serve command keeps the configuration loaded and exposes the analyzer
+over a REST API:
+
+  GET  /health          liveness and status of the latest run
+  POST /api/analyze     start an analysis (?wait=true to block until done)
+  GET  /api/report      results of the latest analysis
+  GET  /api/logs/{id}   result of one log in the latest analysis
+  POST /api/reload      reload the configuration file
+  GET  /metrics         per-log metrics in the Prometheus text format
+
+An analysis is run at startup. Each analysis updates the history store and
+sends notifications when they are configured.`,
+	RunE: runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if serveConfigPath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag)")
+	}
+	if serveBucket != 0 && serveBucket < time.Second {
+		return fmt.Errorf("invalid --bucket %s: must be at least 1s", serveBucket)
+	}
+
+	srv, err := server.New(serveConfigPath, parser.Options{
+		TemplateTop: serveTemplates,
+		Bucket:      serveBucket,
+		Anomalies:   serveAnomalies,
+		Live:        true,
+		Redact:      serveRedact,
+	})
+	if err != nil {
+		return err
+	}
+	srv.TraceWith(func(cfg *config.Config) *tracing.Tracer {
+		return newTracer(cfg, "", false, os.Stdout)
+	})
+	srv.OnComplete(afterServeRun)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		fmt.Printf("Serving the analysis API on %s\n", serveAddr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	go func() {
+		if _, err := srv.Analyze(); err != nil {
+			fmt.Printf("✗ Initial analysis failed: %v\n", err)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	fmt.Println("\nShutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
+}
+
+// afterServeRun records the history and sends the notifications of an
+// analysis of cfg, as analyze does after each run.
+func afterServeRun(cfg *config.Config, rep *reporter.Reporter, run server.Run) {
+	results := rep.GetResults()
+
+	if cfg.History != nil {
+		if err := recordHistory(os.Stdout, *cfg.History, run.Started, results); err != nil {
+			fmt.Printf("✗ History not updated: %v\n", err)
+		}
+	}
+
+	if len(cfg.Notifiers) > 0 {
+		dispatcher, err := notify.NewDispatcher(cfg.Notifiers)
+		if err != nil {
+			fmt.Printf("✗ Failed to set up notifiers: %v\n", err)
+			return
+		}
+		if err := dispatcher.Notify(context.Background(), results); err != nil {
+			fmt.Printf("✗ Notification failed: %v\n", err)
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVarP(&serveConfigPath, "config", "c", "", "Path to JSON configuration file (required)")
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "Address to listen on")
+	serveCmd.Flags().IntVar(&serveTemplates, "templates", 0, "Report the top N message templates for logs without a templates setting (0 disables)")
+	serveCmd.Flags().DurationVar(&serveBucket, "bucket", 0, "Histogram bucket size for logs without a bucket setting (0 disables)")
+	serveCmd.Flags().BoolVar(&serveAnomalies, "anomalies", false, "Detect error spikes, volume spikes and silences")
+	serveCmd.Flags().BoolVar(&serveRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the results (enabled by a \"redaction\" config section)")
+
+	if err := serveCmd.MarkFlagRequired("config"); err != nil {
+		panic(fmt.Sprintf("Failed to mark config flag as required: %v", err))
+	}
+
+	serveCmd.Example = `  # Serve the API on port 8080
+  loganalyzer serve --config config.json --addr :8080
+
+  # Trigger an analysis and wait for its results
+  curl -X POST 'http://localhost:8080/api/analyze?wait=true'
+
+  # Result of one log, then reload the configuration after editing it
+  curl http://localhost:8080/api/logs/web-server-1
+  curl -X POST http://localhost:8080/api/reload
+
+  # Scrape the metrics
+  curl http://localhost:8080/metrics`
+}
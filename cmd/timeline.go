@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/redact"
+	"loganalyzer/internal/timeline"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	timelineConfigPath string
+	timelineSince      string
+	timelineUntil      string
+	timelineOnly       []string
+	timelineTags       []string
+	timelineNoColor    bool
+	timelineUTC        bool
+	timelineRedact     bool
+	timelineWhere      string
+)
+
+// timelineColors are the ANSI colours assigned to sources in configuration
+// order, cycling when there are more logs than colours.
+var timelineColors = []string{"36", "33", "35", "32", "34", "31", "96", "93", "95", "92"}
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Merge all configured logs into one chronological stream",
+	Long: `The timeline command reads every configured log at once and prints their
+entries merged by parsed timestamp, each line prefixed with its log ID and
+coloured per source. Logs are streamed, so memory use stays bounded whatever
+their size.
+
+Sources written in another timezone or by a host with a skewed clock can be
+aligned with the "timezone" and "time_offset" settings of each log.`,
+	RunE: runTimeline,
+}
+
+func runTimeline(cmd *cobra.Command, args []string) error {
+	if timelineConfigPath == "" {
+		return fmt.Errorf("config file path is required (use --config or -c flag)")
+	}
+
+	now := time.Now()
+	since, err := parseTimeBound(timelineSince, now)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseTimeBound(timelineUntil, now)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		return fmt.Errorf("--until must not be before --since")
+	}
+
+	where, err := parseWhere(timelineWhere)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(timelineConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	selection := config.Selection{Only: timelineOnly, Tags: timelineTags}
+	if err := selection.Validate(cfg.Logs); err != nil {
+		return err
+	}
+
+	var logs []config.LogConfig
+	for _, logConfig := range cfg.Logs {
+		if selection.SkipReason(logConfig) == "" {
+			logs = append(logs, logConfig)
+		}
+	}
+	if len(logs) == 0 {
+		return fmt.Errorf("no logs selected for the timeline")
+	}
+
+	width := 0
+	for _, logConfig := range logs {
+		width = max(width, len(logConfig.ID))
+	}
+
+	redactor, err := redact.ForConfig(cfg.Redaction, timelineRedact)
+	if err != nil {
+		return fmt.Errorf("failed to set up redaction: %w", err)
+	}
+
+	out := os.Stdout
+	color := !timelineNoColor && useColor(out)
+	opts := timeline.Options{Since: since, Until: until}
+
+	err = timeline.Merge(context.Background(), logs, opts, func(item timeline.Item) error {
+		if !where.Match(item.Entry) {
+			return nil
+		}
+		item.Entry.Raw = redactor.String(item.Entry.Raw)
+		return writeTimelineItem(out, item, width, color)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		return fmt.Errorf("timeline incomplete: some logs could not be read")
+	}
+	return nil
+}
+
+func writeTimelineItem(w io.Writer, item timeline.Item, width int, color bool) error {
+	prefix := fmt.Sprintf("[%-*s]", width, item.LogID)
+	if color {
+		code := timelineColors[item.Source%len(timelineColors)]
+		prefix = "\x1b[" + code + "m" + prefix + "\x1b[0m"
+	}
+
+	ts := "-"
+	if !item.Time.IsZero() {
+		t := item.Time
+		if timelineUTC {
+			t = t.UTC()
+		} else {
+			t = t.Local()
+		}
+		ts = t.Format("2006-01-02T15:04:05.000Z07:00")
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s %s\n", prefix, ts, item.Entry.Raw)
+	return err
+}
+
+// parseTimeBound accepts an absolute time (RFC 3339, "2006-01-02 15:04:05" or
+// "2006-01-02" in local time) or a duration such as "2h", meaning that long
+// before now. An empty value leaves the bound open.
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration %q must not be negative", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a time (e.g. 2024-01-01T10:00:00Z) nor a duration (e.g. 2h)", value)
+}
+
+// useColor reports whether ANSI colours should be written to f: it must be a
+// terminal and NO_COLOR must not be set.
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+
+	timelineCmd.Flags().StringVarP(&timelineConfigPath, "config", "c", "", "Path to JSON configuration file (required)")
+	timelineCmd.Flags().StringVar(&timelineSince, "since", "", "Show entries at or after this time or duration ago (e.g. 2024-01-01T10:00:00Z, 2h)")
+	timelineCmd.Flags().StringVar(&timelineUntil, "until", "", "Show entries at or before this time or duration ago")
+	timelineCmd.Flags().StringSliceVar(&timelineOnly, "only", nil, "Merge only the logs with these IDs (comma-separated)")
+	timelineCmd.Flags().StringSliceVar(&timelineTags, "tag", nil, "Merge only the logs having at least one of these tags")
+	timelineCmd.Flags().BoolVar(&timelineNoColor, "no-color", false, "Disable coloured log ID prefixes")
+	timelineCmd.Flags().BoolVar(&timelineUTC, "utc", false, "Print timestamps in UTC instead of local time")
+	timelineCmd.Flags().StringVar(&timelineWhere, "where", "", "Show only the entries matching this query (e.g. 'level>=ERROR OR status>=500')")
+	timelineCmd.Flags().BoolVar(&timelineRedact, "redact", false, "Mask emails, tokens, card numbers and IPs in the lines (enabled by a \"redaction\" config section)")
+
+	if err := timelineCmd.MarkFlagRequired("config"); err != nil {
+		panic(fmt.Sprintf("Failed to mark config flag as required: %v", err))
+	}
+
+	timelineCmd.Example = `  # Interleave every configured log by timestamp
+  loganalyzer timeline --config config.json
+
+  # Only the last two hours of the web tier, without colours
+  loganalyzer timeline -c config.json --tag web --since 2h --no-color
+
+  # Only the errors of every log, around an incident
+  loganalyzer timeline -c config.json --since 30m --where 'level>=ERROR OR status>=500'
+
+  # A fixed window, timestamps in UTC
+  loganalyzer timeline -c config.json --since "2024-01-01 10:00:00" --until "2024-01-01 11:00:00" --utc`
+}
@@ -0,0 +1,153 @@
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+type rule struct {
+	config  config.RuleConfig
+	pattern *regexp.Regexp
+}
+
+// Engine evaluates the configured alerting rules against analysis results.
+// A nil *Engine is valid and never fires.
+type Engine struct {
+	rules []rule
+}
+
+func NewEngine(rules []config.RuleConfig) (*Engine, error) {
+	engine := &Engine{rules: make([]rule, 0, len(rules))}
+
+	for _, rc := range rules {
+		r := rule{config: rc}
+		if rc.Pattern != "" {
+			pattern, err := regexp.Compile(rc.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s has invalid pattern: %w", rc.Name, err)
+			}
+			r.pattern = pattern
+		}
+		engine.rules = append(engine.rules, r)
+	}
+
+	return engine, nil
+}
+
+// MatchLine counts line against every pattern rule that applies to logID,
+// incrementing matches[rule name] for each rule that matches.
+func (e *Engine) MatchLine(logID, line string, matches map[string]int) {
+	if e == nil {
+		return
+	}
+
+	for _, r := range e.rules {
+		if r.pattern != nil && r.config.AppliesTo(logID) && r.pattern.MatchString(line) {
+			matches[r.config.Name]++
+		}
+	}
+}
+
+// Evaluate returns the alerts fired by the result's aggregates. Results
+// without stats (failed or skipped logs) never fire.
+func (e *Engine) Evaluate(result reporter.AnalysisResult) []reporter.Alert {
+	if e == nil || result.Stats == nil {
+		return nil
+	}
+
+	var alerts []reporter.Alert
+	for _, r := range e.rules {
+		if !r.config.AppliesTo(result.LogID) {
+			continue
+		}
+
+		metric := r.config.Metric
+		if r.pattern != nil {
+			metric = "pattern:" + r.config.Pattern
+		}
+
+		value := r.value(result.Stats)
+		if !compare(value, r.config.Operator(), r.config.Threshold) {
+			continue
+		}
+
+		message := r.config.Message
+		if message == "" {
+			message = fmt.Sprintf("%s %s %g", metric, r.config.Operator(), r.config.Threshold)
+		}
+
+		alerts = append(alerts, reporter.Alert{
+			Rule:      r.config.Name,
+			Severity:  r.config.SeverityOrDefault(),
+			Message:   message,
+			Metric:    metric,
+			Value:     value,
+			Op:        r.config.Operator(),
+			Threshold: r.config.Threshold,
+		})
+	}
+
+	return alerts
+}
+
+func (r rule) value(stats *reporter.LogStats) float64 {
+	if r.pattern != nil {
+		return float64(stats.PatternMatches[r.config.Name])
+	}
+	return MetricValue(stats, r.config.Metric)
+}
+
+// MetricValue resolves a metric name against stats. Supported metrics are
+// "lines", "bytes", "parse_errors", "error_rate", "level.<LEVEL>" and
+// "status.<N>xx"; the last two accept a "_ratio" suffix to divide by the
+// number of lines.
+func MetricValue(stats *reporter.LogStats, metric string) float64 {
+	name, ratio := strings.CutSuffix(metric, "_ratio")
+
+	var count float64
+	switch {
+	case name == "lines":
+		return float64(stats.Lines)
+	case name == "bytes":
+		return float64(stats.Bytes)
+	case name == "parse_errors":
+		count = float64(stats.ParseErrors)
+	case name == "error_rate":
+		count = float64(stats.Levels["ERROR"] + stats.Levels["FATAL"])
+		ratio = true
+	case strings.HasPrefix(name, "level."):
+		count = float64(stats.Levels[strings.ToUpper(strings.TrimPrefix(name, "level."))])
+	case strings.HasPrefix(name, "status."):
+		count = float64(stats.StatusClasses[strings.TrimPrefix(name, "status.")])
+	}
+
+	if !ratio {
+		return count
+	}
+	if stats.Lines == 0 {
+		return 0
+	}
+	return count / float64(stats.Lines)
+}
+
+func compare(value float64, op string, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package alert
+
+import (
+	"testing"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+func TestEvaluate(t *testing.T) {
+	engine, err := NewEngine([]config.RuleConfig{
+		{Name: "web-errors", LogID: "web", Metric: "level.ERROR", Op: ">", Threshold: 50, Severity: "critical", Message: "Too many errors"},
+		{Name: "panics", Pattern: "panic:", Message: "Go panic detected"},
+		{Name: "5xx-ratio", LogID: "web", Metric: "status.5xx_ratio", Threshold: 0.02},
+		{Name: "db-only", LogID: "db", Metric: "lines", Threshold: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	stats := reporter.NewLogStats()
+	stats.Lines = 1000
+	stats.Levels["ERROR"] = 60
+	stats.StatusClasses["5xx"] = 10
+
+	lines := []string{"ok", "panic: nil map", "fine", "goroutine 1 [running]:"}
+	for _, line := range lines {
+		engine.MatchLine("web", line, stats.PatternMatches)
+	}
+
+	result := reporter.CreateSuccessResult("web", "/var/log/web.log")
+	result.Stats = stats
+
+	alerts := engine.Evaluate(result)
+
+	fired := make(map[string]reporter.Alert)
+	for _, alert := range alerts {
+		fired[alert.Rule] = alert
+	}
+
+	if alert, ok := fired["web-errors"]; !ok {
+		t.Error("expected rule web-errors to fire")
+	} else if alert.Severity != "critical" || alert.Value != 60 {
+		t.Errorf("web-errors alert = %+v", alert)
+	}
+
+	if alert, ok := fired["panics"]; !ok {
+		t.Error("expected rule panics to fire")
+	} else if alert.Value != 1 || alert.Severity != "warning" {
+		t.Errorf("panics alert = %+v", alert)
+	}
+
+	if _, ok := fired["5xx-ratio"]; ok {
+		t.Error("rule 5xx-ratio fired at 1% with a 2% threshold")
+	}
+
+	if _, ok := fired["db-only"]; ok {
+		t.Error("rule db-only fired for another log")
+	}
+
+	var nilEngine *Engine
+	if alerts := nilEngine.Evaluate(result); alerts != nil {
+		t.Errorf("nil engine Evaluate() = %v, want nil", alerts)
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	stats := reporter.NewLogStats()
+	stats.Lines = 200
+	stats.ParseErrors = 4
+	stats.Levels["ERROR"] = 8
+	stats.Levels["FATAL"] = 2
+	stats.StatusClasses["4xx"] = 20
+
+	tests := []struct {
+		metric   string
+		expected float64
+	}{
+		{metric: "lines", expected: 200},
+		{metric: "parse_errors_ratio", expected: 0.02},
+		{metric: "error_rate", expected: 0.05},
+		{metric: "level.error", expected: 8},
+		{metric: "status.4xx", expected: 20},
+		{metric: "status.4xx_ratio", expected: 0.1},
+		{metric: "status.5xx", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			if got := MetricValue(stats, tt.metric); got != tt.expected {
+				t.Errorf("MetricValue(%q) = %v, want %v", tt.metric, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/histogram"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/templates"
+)
+
+// Aggregator accumulates the entries of one log into its result: stats,
+// pattern rule matches, templates and histogram. It is what the analyzer runs
+// over each log file, and what receivers of live messages feed one line at a
+// time. An Aggregator is not safe for concurrent use.
+type Aggregator struct {
+	analyzer    *Analyzer
+	logConfig   config.LogConfig
+	parser      entry.Parser
+	offset      time.Duration
+	stats       *reporter.LogStats
+	miner       *templates.Miner
+	templateCfg templateSettings
+	timeline    *histogram.Builder
+}
+
+// NewAggregator returns an empty aggregator for the log, using the templates,
+// histogram and alerting settings of the analyzer.
+func (a *Analyzer) NewAggregator(logConfig config.LogConfig) *Aggregator {
+	g := &Aggregator{
+		analyzer:  a,
+		logConfig: logConfig,
+		parser:    entry.ForLog(logConfig.Type, logConfig.Path, logConfig.Location()),
+		offset:    logConfig.ClockOffset(),
+		stats:     reporter.NewLogStats(),
+		timeline:  a.histogramBuilder(logConfig),
+	}
+	g.miner, g.templateCfg = a.templateMiner(logConfig)
+	return g
+}
+
+// AddLine parses a raw line with the parser of the log type and adds it.
+func (g *Aggregator) AddLine(line string) {
+	e, parseErr := parseEntry(g.parser, g.offset, line)
+	g.Add(e, parseErr)
+}
+
+// Add counts an entry; parseErr is the error of a line that did not match the
+// log format. Entries not matching the --where query of the analyzer are
+// ignored.
+func (g *Aggregator) Add(e entry.Entry, parseErr error) {
+	if !g.analyzer.where.Match(e) {
+		return
+	}
+	g.stats.Lines++
+	g.stats.Bytes += int64(len(e.Raw)) + 1
+	if parseErr != nil {
+		g.stats.ParseErrors++
+	}
+	recordEntry(g.stats, e)
+	g.analyzer.alerts.MatchLine(g.logConfig.ID, e.Raw, g.stats.PatternMatches)
+
+	if g.timeline != nil {
+		g.timeline.Add(e.Time, e.Level.String())
+	}
+	if g.miner != nil && e.Level >= g.templateCfg.minLevel {
+		g.miner.Add(messageOf(e), e.Raw)
+	}
+}
+
+// Result returns the success result of the entries added so far. It can be
+// called again after more entries are added; results never share state with
+// the aggregator.
+func (g *Aggregator) Result() reporter.AnalysisResult {
+	result := reporter.CreateSuccessResult(g.logConfig.ID, g.logConfig.Path)
+	result.Stats = g.stats.Clone()
+	if g.miner != nil {
+		result.Templates = g.miner.Top(g.templateCfg.top)
+	}
+	if g.timeline != nil {
+		result.Histogram = g.timeline.Histogram()
+	}
+	return result
+}
+
+// Snapshot is Result with the anomalies detected and the alerts fired so
+// far, redacted as reported at the end of an analysis.
+func (g *Aggregator) Snapshot() reporter.AnalysisResult {
+	result := g.Result()
+	g.analyzer.evaluate(&result)
+	return g.analyzer.redactor.Result(result)
+}
@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"loganalyzer/internal/alert"
+	"loganalyzer/internal/config"
+)
+
+func TestAggregatorSnapshot(t *testing.T) {
+	engine, err := alert.NewEngine([]config.RuleConfig{{Name: "errors", Metric: "level.ERROR", Threshold: 1}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	analyzer := NewAnalyzer(&config.Config{})
+	analyzer.SetAlertEngine(engine)
+	analyzer.SetDefaultBucket(time.Minute)
+
+	aggregator := analyzer.NewAggregator(config.LogConfig{ID: "live", Path: "syslog", Type: "syslog"})
+	aggregator.AddLine("<11>Mar  9 22:14:15 host sshd[1]: first failure")
+
+	first := aggregator.Snapshot()
+	if first.Stats.Lines != 1 || len(first.Alerts) != 0 {
+		t.Errorf("first snapshot lines = %d, alerts = %v", first.Stats.Lines, first.Alerts)
+	}
+
+	aggregator.AddLine("<11>Mar  9 22:14:16 host sshd[1]: second failure")
+	aggregator.AddLine("not syslog at all")
+
+	second := aggregator.Snapshot()
+	if second.Stats.Lines != 3 || second.Stats.ParseErrors != 1 {
+		t.Errorf("second snapshot lines = %d, parse errors = %d", second.Stats.Lines, second.Stats.ParseErrors)
+	}
+	if len(second.Alerts) != 1 || second.Alerts[0].Rule != "errors" {
+		t.Errorf("second snapshot alerts = %v, want the errors rule", second.Alerts)
+	}
+
+	if first.Stats.Levels["ERROR"] != 1 || first.Histogram.Buckets[0].Total != 1 {
+		t.Errorf("first snapshot changed by later lines: levels = %v", first.Stats.Levels)
+	}
+}
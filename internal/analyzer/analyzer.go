@@ -1,43 +1,199 @@
 package parser
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
 	"sync"
 	"time"
 
+	"loganalyzer/internal/alert"
+	"loganalyzer/internal/anomaly"
 	"loganalyzer/internal/config"
+	"loganalyzer/internal/query"
+	"loganalyzer/internal/redact"
 	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/source"
+	"loganalyzer/internal/tracing"
 )
 
 type Analyzer struct {
-	config   *config.Config
-	reporter *reporter.Reporter
+	config    *config.Config
+	reporter  *reporter.Reporter
+	selection config.Selection
+	alerts    *alert.Engine
+	// templateTop is the number of templates reported for logs without
+	// their own "templates" configuration; 0 disables mining for them.
+	templateTop int
+	// bucket is the histogram bucket size for logs without their own
+	// "bucket" setting; 0 disables histograms for them.
+	bucket    time.Duration
+	anomalies *anomaly.Detector
+	tracer    *tracing.Tracer
+	// redactor masks sensitive data in the results; nil leaves them as is.
+	redactor *redact.Redactor
+	// where selects the entries that are analyzed; nil analyzes them all.
+	where *query.Query
+	// live reports the logs that stopped writing as silent until now; a
+	// batch analysis only reports the gaps between entries.
+	live bool
+	// out receives the progress messages.
+	out io.Writer
 }
 
 func NewAnalyzer(cfg *config.Config) *Analyzer {
 	return &Analyzer{
 		config:   cfg,
 		reporter: reporter.NewReporter(),
+		out:      os.Stdout,
 	}
 }
 
-func (a *Analyzer) AnalyzeAllLogs() error {
+// Options are the command-line settings applied on top of the configuration
+// by NewAnalyzerWithOptions.
+type Options struct {
+	Selection   config.Selection
+	TemplateTop int
+	Bucket      time.Duration
+	// Anomalies enables anomaly detection even without an "anomaly"
+	// configuration section.
+	Anomalies bool
+	Tracer    *tracing.Tracer
+	// Redact masks sensitive data in the results with the built-in
+	// detectors even without a "redaction" configuration section.
+	Redact bool
+	// Where analyzes only the entries matching the query.
+	Where *query.Query
+	// Live checks for silences up to the current time, for services
+	// analyzing logs that are still written.
+	Live bool
+}
+
+// NewAnalyzerWithOptions creates an analyzer with the alerting rules and
+// anomaly detection of cfg and the given options.
+func NewAnalyzerWithOptions(cfg *config.Config, opts Options) (*Analyzer, error) {
+	alerts, err := alert.NewEngine(cfg.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerting rules: %w", err)
+	}
+
+	a := NewAnalyzer(cfg)
+	a.SetAlertEngine(alerts)
+	a.SetDefaultTemplateTop(opts.TemplateTop)
+	a.SetDefaultBucket(opts.Bucket)
+	if cfg.Anomaly != nil || opts.Anomalies {
+		anomalyCfg := config.AnomalyConfig{}
+		if cfg.Anomaly != nil {
+			anomalyCfg = *cfg.Anomaly
+		}
+		a.SetAnomalyDetector(anomaly.NewDetector(anomalyCfg))
+	}
+	redactor, err := redact.ForConfig(cfg.Redaction, opts.Redact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up redaction: %w", err)
+	}
+	a.SetRedactor(redactor)
+	a.SetWhere(opts.Where)
+	a.SetLive(opts.Live)
+	a.SetSelection(opts.Selection)
+	a.SetTracer(opts.Tracer)
+	return a, nil
+}
+
+// SetSelection restricts AnalyzeAllLogs to the logs matched by sel.
+func (a *Analyzer) SetSelection(sel config.Selection) {
+	a.selection = sel
+}
+
+// SetAlertEngine sets the rules evaluated against each log's aggregates.
+func (a *Analyzer) SetAlertEngine(engine *alert.Engine) {
+	a.alerts = engine
+}
+
+// SetDefaultTemplateTop enables template mining with the given top N for
+// logs that do not configure it themselves.
+func (a *Analyzer) SetDefaultTemplateTop(n int) {
+	a.templateTop = n
+}
+
+// SetDefaultBucket enables time histograms with the given bucket size for
+// logs that do not configure it themselves.
+func (a *Analyzer) SetDefaultBucket(size time.Duration) {
+	a.bucket = size
+}
+
+// SetAnomalyDetector enables anomaly detection. Logs without a histogram
+// bucket get one of the detector's bucket size.
+func (a *Analyzer) SetAnomalyDetector(detector *anomaly.Detector) {
+	a.anomalies = detector
+}
+
+// SetOutput sets where progress messages are written, stdout by default.
+func (a *Analyzer) SetOutput(w io.Writer) {
+	a.out = w
+}
+
+// SetTracer enables tracing: each analysis is a trace with one span per log.
+func (a *Analyzer) SetTracer(tracer *tracing.Tracer) {
+	a.tracer = tracer
+}
+
+// SetRedactor masks sensitive data in every result before it is reported.
+func (a *Analyzer) SetRedactor(redactor *redact.Redactor) {
+	a.redactor = redactor
+}
+
+// SetWhere restricts the analysis to the entries matching the query; the
+// other entries are not counted at all.
+func (a *Analyzer) SetWhere(where *query.Query) {
+	a.where = where
+}
+
+// SetLive makes anomaly detection report a log that stopped writing as silent
+// from its last entry until the time of the analysis. Batch analyses of old
+// files, archives and exports leave it off.
+func (a *Analyzer) SetLive(live bool) {
+	a.live = live
+}
+
+// Redactor returns the redactor of the results, nil when redaction is off.
+func (a *Analyzer) Redactor() *redact.Redactor {
+	return a.redactor
+}
+
+func (a *Analyzer) AnalyzeAllLogs() (err error) {
+	ctx, span := a.tracer.Start(context.Background(), "analyze",
+		tracing.Int("logs.configured", int64(len(a.config.Logs))))
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	if len(a.config.Logs) == 0 {
 		return fmt.Errorf("no logs to analyze")
 	}
 
-	resultsChan := make(chan reporter.AnalysisResult, len(a.config.Logs))
+	if err := a.selection.Validate(a.config.Logs); err != nil {
+		return err
+	}
+
+	selected := a.selectLogs()
+	span.SetAttributes(tracing.Int("logs.selected", int64(len(selected))))
+	if len(selected) == 0 {
+		return fmt.Errorf("no logs selected for analysis (%d skipped)", len(a.config.Logs))
+	}
+
+	resultsChan := make(chan reporter.AnalysisResult, len(selected))
 	
 	var wg sync.WaitGroup
 
-	fmt.Printf("Starting analysis of %d log files...\n", len(a.config.Logs))
+	fmt.Fprintf(a.out, "Starting analysis of %d log files...\n", len(selected))
 
-	for _, logConfig := range a.config.Logs {
+	for _, logConfig := range selected {
 		wg.Add(1)
-		go a.analyzeLogFile(logConfig, resultsChan, &wg)
+		go a.analyzeLogFile(ctx, logConfig, resultsChan, &wg)
 	}
 
 	go func() {
@@ -45,37 +201,107 @@ func (a *Analyzer) AnalyzeAllLogs() error {
 		close(resultsChan)
 	}()
 
+	failed := 0
 	for result := range resultsChan {
-		a.reporter.AddResult(result)
+		if result.Status == "FAILURE" {
+			failed++
+		}
+		a.reporter.AddResult(a.redactor.Result(result))
 	}
+	span.SetAttributes(tracing.Int("logs.failed", int64(failed)))
 
 	return nil
 }
 
-func (a *Analyzer) analyzeLogFile(logConfig config.LogConfig, resultsChan chan<- reporter.AnalysisResult, wg *sync.WaitGroup) {
+// selectLogs returns the logs to analyze and records a skipped result for
+// every other configured log.
+func (a *Analyzer) selectLogs() []config.LogConfig {
+	selected := make([]config.LogConfig, 0, len(a.config.Logs))
+
+	for _, logConfig := range a.config.Logs {
+		if reason := a.selection.SkipReason(logConfig); reason != "" {
+			fmt.Fprintf(a.out, "- Skipping log: %s (%s)\n", logConfig.ID, reason)
+			a.reporter.AddResult(reporter.CreateSkippedResult(logConfig.ID, logConfig.Path, reason))
+			continue
+		}
+		selected = append(selected, logConfig)
+	}
+
+	return selected
+}
+
+func (a *Analyzer) analyzeLogFile(ctx context.Context, logConfig config.LogConfig, resultsChan chan<- reporter.AnalysisResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	fmt.Printf("Processing log: %s (%s)\n", logConfig.ID, logConfig.Path)
+	_, span := a.tracer.Start(ctx, "analyzeLogFile",
+		tracing.String("log.id", logConfig.ID),
+		tracing.String("log.path", logConfig.Path),
+		tracing.String("log.type", logConfig.Type))
 
-	if err := a.checkFileAccess(logConfig.Path); err != nil {
-		result := a.handleFileError(logConfig, err)
-		resultsChan <- result
-		return
+	start := time.Now()
+	result, err := a.analyzeLog(logConfig, span)
+	result.DurationSeconds = time.Since(start).Seconds()
+
+	span.SetAttributes(tracing.String("log.status", result.Status))
+	if err != nil {
+		span.SetAttributes(tracing.String("error.type", errorType(err)))
+		span.SetError(err)
 	}
+	span.End()
 
-	sleepDuration := time.Duration(50+rand.Intn(151)) * time.Millisecond
-	time.Sleep(sleepDuration)
+	resultsChan <- result
+}
 
-	if rand.Float32() < 0.1 {
-		parseErr := NewParseError(logConfig.ID, "simulated parsing failure", fmt.Errorf("random parse error"))
-		result := a.handleParseError(logConfig, parseErr)
-		resultsChan <- result
-		return
+// analyzeLog returns the result of a log and, when it failed, the error that
+// caused the failure. The span of the log, if any, gets what was read.
+func (a *Analyzer) analyzeLog(logConfig config.LogConfig, span *tracing.Span) (reporter.AnalysisResult, error) {
+	fmt.Fprintf(a.out, "Processing log: %s (%s)\n", logConfig.ID, logConfig.Path)
+
+	// Remote files and the standard input are checked when ReadEntries
+	// opens them.
+	if source.IsLocalFile(logConfig) {
+		if err := a.checkFileAccess(logConfig.Path); err != nil {
+			return a.handleFileError(logConfig, err), err
+		}
 	}
 
-	result := reporter.CreateSuccessResult(logConfig.ID, logConfig.Path)
-	fmt.Printf("✓ Completed analysis of log: %s\n", logConfig.ID)
-	resultsChan <- result
+	result, err := a.scanLogFile(logConfig, span)
+	if err != nil {
+		var fileErr *FileNotFoundError
+		if errors.As(err, &fileErr) {
+			return a.handleFileError(logConfig, err), err
+		}
+		return a.handleParseError(logConfig, err), err
+	}
+
+	a.evaluate(&result)
+	fmt.Fprintf(a.out, "✓ Completed analysis of log: %s\n", logConfig.ID)
+	return result, nil
+}
+
+// evaluate adds the anomalies detected in the histogram of a success result
+// and the alerts fired by its aggregates.
+func (a *Analyzer) evaluate(result *reporter.AnalysisResult) {
+	var end time.Time
+	if a.live {
+		end = time.Now()
+	}
+	result.Anomalies = a.anomalies.Detect(result.Histogram, end)
+	result.Alerts = a.alerts.Evaluate(*result)
+}
+
+// errorType names the custom error type behind a failed analysis.
+func errorType(err error) string {
+	var fileErr *FileNotFoundError
+	var parseErr *ParseError
+	switch {
+	case errors.As(err, &fileErr):
+		return "FileNotFoundError"
+	case errors.As(err, &parseErr):
+		return "ParseError"
+	default:
+		return fmt.Sprintf("%T", err)
+	}
 }
 
 func (a *Analyzer) checkFileAccess(filePath string) error {
@@ -108,7 +334,7 @@ func (a *Analyzer) handleFileError(logConfig config.LogConfig, err error) report
 
 	if errors.As(err, &fileNotFoundErr) {
 		if errors.Is(fileNotFoundErr.Err, os.ErrNotExist) {
-			fmt.Printf("✗ File not found for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
+			fmt.Fprintf(a.out, "✗ File not found for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
 			return reporter.CreateFailureResult(
 				logConfig.ID,
 				logConfig.Path,
@@ -116,7 +342,7 @@ func (a *Analyzer) handleFileError(logConfig config.LogConfig, err error) report
 				fileNotFoundErr.Error(),
 			)
 		} else if errors.Is(fileNotFoundErr.Err, os.ErrPermission) {
-			fmt.Printf("✗ Permission denied for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
+			fmt.Fprintf(a.out, "✗ Permission denied for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
 			return reporter.CreateFailureResult(
 				logConfig.ID,
 				logConfig.Path,
@@ -124,7 +350,7 @@ func (a *Analyzer) handleFileError(logConfig config.LogConfig, err error) report
 				fileNotFoundErr.Error(),
 			)
 		} else {
-			fmt.Printf("✗ File access error for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
+			fmt.Fprintf(a.out, "✗ File access error for log %s: %s\n", logConfig.ID, fileNotFoundErr.Error())
 			return reporter.CreateFailureResult(
 				logConfig.ID,
 				logConfig.Path,
@@ -135,7 +361,7 @@ func (a *Analyzer) handleFileError(logConfig config.LogConfig, err error) report
 	}
 
 	// Generic file error
-	fmt.Printf("✗ File error for log %s: %s\n", logConfig.ID, err.Error())
+	fmt.Fprintf(a.out, "✗ File error for log %s: %s\n", logConfig.ID, err.Error())
 	return reporter.CreateFailureResult(
 		logConfig.ID,
 		logConfig.Path,
@@ -149,7 +375,7 @@ func (a *Analyzer) handleParseError(logConfig config.LogConfig, err error) repor
 
 	if errors.As(err, &parseErr) {
 		if errors.Is(parseErr.Err, os.ErrInvalid) {
-			fmt.Printf("✗ Invalid format in log %s: %s\n", logConfig.ID, parseErr.Error())
+			fmt.Fprintf(a.out, "✗ Invalid format in log %s: %s\n", logConfig.ID, parseErr.Error())
 			return reporter.CreateFailureResult(
 				logConfig.ID,
 				logConfig.Path,
@@ -157,7 +383,7 @@ func (a *Analyzer) handleParseError(logConfig config.LogConfig, err error) repor
 				parseErr.Error(),
 			)
 		} else {
-			fmt.Printf("✗ Parse error for log %s: %s\n", logConfig.ID, parseErr.Error())
+			fmt.Fprintf(a.out, "✗ Parse error for log %s: %s\n", logConfig.ID, parseErr.Error())
 			return reporter.CreateFailureResult(
 				logConfig.ID,
 				logConfig.Path,
@@ -167,7 +393,7 @@ func (a *Analyzer) handleParseError(logConfig config.LogConfig, err error) repor
 		}
 	}
 
-	fmt.Printf("✗ Parse error for log %s: %s\n", logConfig.ID, err.Error())
+	fmt.Fprintf(a.out, "✗ Parse error for log %s: %s\n", logConfig.ID, err.Error())
 	return reporter.CreateFailureResult(
 		logConfig.ID,
 		logConfig.Path,
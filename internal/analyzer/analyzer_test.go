@@ -1,11 +1,18 @@
 package parser
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"loganalyzer/internal/config"
+	"loganalyzer/internal/query"
+	"loganalyzer/internal/tracing"
 )
 
 func TestNewAnalyzer(t *testing.T) {
@@ -113,6 +120,167 @@ func TestAnalyzeAllLogs(t *testing.T) {
 	}
 }
 
+func TestAnalyzeAllLogsSelection(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logPath := filepath.Join(tempDir, "test.log")
+	if err := os.WriteFile(logPath, []byte("test log"), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	disabled := false
+	cfg := &config.Config{
+		Logs: []config.LogConfig{
+			{ID: "web", Path: logPath, Type: "nginx", Tags: []string{"web"}},
+			{ID: "db", Path: logPath, Type: "postgres", Tags: []string{"db"}},
+			{ID: "old", Path: logPath, Type: "nginx", Tags: []string{"web"}, Enabled: &disabled},
+		},
+	}
+
+	analyzer := NewAnalyzer(cfg)
+	analyzer.SetSelection(config.Selection{Tags: []string{"web"}})
+
+	if err := analyzer.AnalyzeAllLogs(); err != nil {
+		t.Fatalf("AnalyzeAllLogs() error = %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, result := range analyzer.GetReporter().GetResults() {
+		statuses[result.LogID] = result.Status
+	}
+
+	if statuses["web"] == "SKIPPED" || statuses["web"] == "" {
+		t.Errorf("expected log web to be analyzed, got status %q", statuses["web"])
+	}
+	if statuses["db"] != "SKIPPED" {
+		t.Errorf("expected log db to be skipped, got status %q", statuses["db"])
+	}
+	if statuses["old"] != "SKIPPED" {
+		t.Errorf("expected log old to be skipped, got status %q", statuses["old"])
+	}
+
+	analyzer = NewAnalyzer(cfg)
+	analyzer.SetSelection(config.Selection{Only: []string{"missing"}})
+	if err := analyzer.AnalyzeAllLogs(); err == nil {
+		t.Error("AnalyzeAllLogs() expected error for unknown --only ID, got nil")
+	}
+}
+
+func TestAnalyzeAllLogsTracing(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	logPath := filepath.Join(tempDir, "test.log")
+	if err := os.WriteFile(logPath, []byte("line 1\nline 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	cfg := &config.Config{
+		Logs: []config.LogConfig{
+			{ID: "ok", Path: logPath, Type: "application"},
+			{ID: "missing", Path: filepath.Join(tempDir, "missing.log"), Type: "application"},
+		},
+	}
+
+	// The spans count what was read, not only the entries analyzed.
+	where, err := query.Parse(`raw ~ "1"`)
+	if err != nil {
+		t.Fatalf("query.Parse() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	analyzer := NewAnalyzer(cfg)
+	analyzer.SetTracer(tracing.NewTracer(tracing.NewStdoutExporter(&buf, "loganalyzer")))
+	analyzer.SetWhere(where)
+
+	if err := analyzer.AnalyzeAllLogs(); err != nil {
+		t.Fatalf("AnalyzeAllLogs() error = %v", err)
+	}
+
+	trace := buf.String()
+	for _, want := range []string{
+		`"name":"analyze"`,
+		`"name":"analyzeLogFile"`,
+		`{"key":"log.lines","value":{"intValue":"2"}}`,
+		`{"key":"log.bytes","value":{"intValue":"14"}}`,
+		`{"key":"error.type","value":{"stringValue":"FileNotFoundError"}}`,
+		`{"key":"logs.failed","value":{"intValue":"1"}}`,
+	} {
+		if !strings.Contains(trace, want) {
+			t.Errorf("trace missing %s:\n%s", want, trace)
+		}
+	}
+}
+
+func TestAnalyzeAllLogsRedaction(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "auth.log")
+	lines := "ERROR login failed for ann@example.com from 10.0.0.7\nERROR login failed for bob@example.com from 10.0.0.7\n"
+	if err := os.WriteFile(logPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	cfg := &config.Config{Logs: []config.LogConfig{{ID: "auth", Path: logPath, Type: "application"}}}
+	analyzer, err := NewAnalyzerWithOptions(cfg, Options{TemplateTop: 5, Redact: true})
+	if err != nil {
+		t.Fatalf("NewAnalyzerWithOptions() error = %v", err)
+	}
+	analyzer.SetOutput(io.Discard)
+	if err := analyzer.AnalyzeAllLogs(); err != nil {
+		t.Fatalf("AnalyzeAllLogs() error = %v", err)
+	}
+
+	results := analyzer.GetReporter().GetResults()
+	if len(results) != 1 || len(results[0].Templates) != 1 {
+		t.Fatalf("AnalyzeAllLogs() results = %+v, want one log with one template", results)
+	}
+	sample := results[0].Templates[0].Sample
+	if strings.Contains(sample, "@example.com") || strings.Contains(sample, "10.0.0.7") || !strings.Contains(sample, "<email:") || !strings.Contains(sample, "<ip:") {
+		t.Errorf("template sample = %q, want the email and IP masked", sample)
+	}
+}
+
+func TestAnalyzeAllLogsOldLogNotSilent(t *testing.T) {
+	// A rotated log, written steadily for ten minutes years ago.
+	var lines strings.Builder
+	for minute := 0; minute < 10; minute++ {
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(&lines, `{"time":"2020-01-02T10:%02d:%02dZ","level":"info","msg":"ok"}`+"\n", minute, i)
+		}
+	}
+	logPath := filepath.Join(t.TempDir(), "app.log.1")
+	if err := os.WriteFile(logPath, []byte(lines.String()), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+	cfg := &config.Config{Logs: []config.LogConfig{{ID: "app", Path: logPath, Type: "jsonl"}}}
+
+	for _, live := range []bool{false, true} {
+		analyzer, err := NewAnalyzerWithOptions(cfg, Options{Bucket: time.Minute, Anomalies: true, Live: live})
+		if err != nil {
+			t.Fatalf("NewAnalyzerWithOptions() error = %v", err)
+		}
+		analyzer.SetOutput(io.Discard)
+		if err := analyzer.AnalyzeAllLogs(); err != nil {
+			t.Fatalf("AnalyzeAllLogs() error = %v", err)
+		}
+
+		anomalies := analyzer.GetReporter().GetResults()[0].Anomalies
+		silent := len(anomalies) == 1 && anomalies[0].Kind == "silence"
+		if live && !silent {
+			t.Errorf("live AnalyzeAllLogs() anomalies = %+v, want a silence until now", anomalies)
+		}
+		if !live && len(anomalies) != 0 {
+			t.Errorf("AnalyzeAllLogs() anomalies = %+v, want none for a log that ended in the past", anomalies)
+		}
+	}
+}
+
 func TestCheckFileAccess(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
 	if err != nil {
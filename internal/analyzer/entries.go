@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/source"
+)
+
+// maxLineSize bounds the memory used for a single log line.
+const maxLineSize = 1024 * 1024
+
+// EntryFunc receives every line of a log parsed into an entry, together with
+// the parse error when the line did not match the log format. Returning an
+// error stops the iteration and is returned by ReadEntries.
+type EntryFunc func(e entry.Entry, parseErr error) error
+
+// ReadEntries opens the log, local or remote, parses every line with the
+// parser matching the log type and passes the entries to fn in file order.
+// Timestamps are read in the log's timezone and corrected by its time offset.
+// Open failures are returned as *FileNotFoundError and read failures as
+// *ParseError.
+func ReadEntries(logConfig config.LogConfig, fn EntryFunc) error {
+	file, err := source.Open(logConfig)
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return NewFileNotFoundError(logConfig.Path, fmt.Errorf("permission denied: %w", err))
+		}
+		return NewFileNotFoundError(logConfig.Path, fmt.Errorf("cannot read file: %w", err))
+	}
+	defer file.Close()
+
+	logParser := entry.ForLog(logConfig.Type, logConfig.Path, logConfig.Location())
+	offset := logConfig.ClockOffset()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	if splitter, ok := logParser.(entry.RecordSplitter); ok {
+		scanner.Split(splitter.SplitRecords)
+	} else if rule := logConfig.MultilineRule(); rule != nil {
+		scanner.Split(rule.Split)
+	}
+
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		e, parseErr := parseEntry(logParser, offset, scanner.Text())
+		if err := fn(e, parseErr); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return NewParseError(logConfig.ID, fmt.Sprintf("line %d exceeds %d bytes", lines+1, maxLineSize), os.ErrInvalid)
+		}
+		return NewParseError(logConfig.ID, "failed to read log", err)
+	}
+
+	return nil
+}
+
+// parseEntry parses a line and corrects its timestamp by the clock offset of
+// the log.
+func parseEntry(logParser entry.Parser, offset time.Duration, line string) (entry.Entry, error) {
+	e, parseErr := logParser.Parse(line)
+	if offset != 0 && !e.Time.IsZero() {
+		e.Time = e.Time.Add(offset)
+	}
+	return e, parseErr
+}
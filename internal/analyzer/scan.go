@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"strings"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/histogram"
+	"loganalyzer/internal/multiline"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/templates"
+	"loganalyzer/internal/tracing"
+)
+
+// scanLogFile reads the log entry by entry and aggregates the entries into a
+// success result. The span, if any, gets the lines and bytes read, including
+// the entries the --where query leaves out of the result.
+func (a *Analyzer) scanLogFile(logConfig config.LogConfig, span *tracing.Span) (reporter.AnalysisResult, error) {
+	aggregator := a.NewAggregator(logConfig)
+	var lines, bytes int64
+	err := ReadEntries(logConfig, func(e entry.Entry, parseErr error) error {
+		lines++
+		bytes += int64(len(e.Raw)) + 1
+		aggregator.Add(e, parseErr)
+		return nil
+	})
+	span.SetAttributes(tracing.Int("log.bytes", bytes), tracing.Int("log.lines", lines))
+	if err != nil {
+		return reporter.AnalysisResult{}, err
+	}
+	return aggregator.Result(), nil
+}
+
+// histogramBuilder returns a builder when histograms are enabled for the log
+// or needed by anomaly detection.
+func (a *Analyzer) histogramBuilder(logConfig config.LogConfig) *histogram.Builder {
+	size := logConfig.BucketSize()
+	if size == 0 {
+		size = a.bucket
+	}
+	if size == 0 && a.anomalies != nil {
+		size = a.anomalies.BucketSize()
+	}
+	if size <= 0 {
+		return nil
+	}
+	return histogram.NewBuilder(size)
+}
+
+type templateSettings struct {
+	top      int
+	minLevel entry.Level
+}
+
+// templateMiner returns a miner when template mining is enabled for the log,
+// either in its configuration or through the analyzer default.
+func (a *Analyzer) templateMiner(logConfig config.LogConfig) (*templates.Miner, templateSettings) {
+	settings := templateSettings{top: a.templateTop}
+
+	if logConfig.Templates != nil {
+		settings.top = logConfig.Templates.Top
+		if logConfig.Templates.MinLevel != "" {
+			settings.minLevel, _ = entry.ParseLevel(logConfig.Templates.MinLevel)
+		}
+	}
+
+	if settings.top <= 0 {
+		return nil, settings
+	}
+	return templates.NewMiner(), settings
+}
+
+// messageOf returns the part of the entry used for template mining: the
+// message, or its first line for an entry spanning several lines.
+func messageOf(e entry.Entry) string {
+	message := e.Message
+	if message == "" {
+		message = e.Raw
+	}
+	first, _, _ := strings.Cut(message, "\n")
+	return first
+}
+
+func recordEntry(stats *reporter.LogStats, e entry.Entry) {
+	stats.Levels[e.Level.String()]++
+
+	if status := e.Fields["status"]; len(status) == 3 && status[0] >= '1' && status[0] <= '5' {
+		stats.StatusClasses[status[:1]+"xx"]++
+	}
+	if unit := e.Fields["unit"]; unit != "" {
+		stats.Units[unit]++
+	}
+	if severity := e.Fields["severity"]; severity != "" {
+		stats.Priorities[severity]++
+	}
+	if name := e.Fields["stream"]; name != "" {
+		stream := stats.Streams[name]
+		if stream == nil {
+			stream = &reporter.StreamStats{Levels: make(map[string]int)}
+			stats.Streams[name] = stream
+		}
+		stream.Lines++
+		stream.Levels[e.Level.String()]++
+	}
+	if kind, ok := multiline.StackTrace(e.Raw); ok {
+		stats.StackTraces++
+		if kind != "" {
+			stats.Exceptions[kind]++
+		}
+	}
+}
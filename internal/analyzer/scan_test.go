@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/alert"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/query"
+)
+
+func TestScanLogFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lines := []string{
+		`10.0.0.1 - - [01/Jan/2024:00:00:00 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`,
+		`10.0.0.2 - - [01/Jan/2024:00:00:01 +0000] "GET /missing HTTP/1.1" 404 0 "-" "curl/8.0"`,
+		`10.0.0.3 - - [01/Jan/2024:00:00:02 +0000] "POST /api HTTP/1.1" 502 0 "-" "curl/8.0"`,
+		`garbage line`,
+	}
+	logPath := filepath.Join(tempDir, "access.log")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	engine, err := alert.NewEngine([]config.RuleConfig{{Name: "api", Pattern: `"POST /api`}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	analyzer := NewAnalyzer(&config.Config{})
+	analyzer.SetAlertEngine(engine)
+
+	result, err := analyzer.scanLogFile(config.LogConfig{ID: "web", Path: logPath, Type: "nginx access"}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	stats := result.Stats
+	if result.Templates != nil {
+		t.Errorf("scanLogFile() mined templates without being enabled: %v", result.Templates)
+	}
+
+	if stats.Lines != 4 {
+		t.Errorf("scanLogFile() lines = %d, want 4", stats.Lines)
+	}
+	if stats.ParseErrors != 1 {
+		t.Errorf("scanLogFile() parse errors = %d, want 1", stats.ParseErrors)
+	}
+	if stats.Levels["ERROR"] != 1 || stats.Levels["WARN"] != 1 || stats.Levels["INFO"] != 1 {
+		t.Errorf("scanLogFile() levels = %v", stats.Levels)
+	}
+	if stats.StatusClasses["2xx"] != 1 || stats.StatusClasses["4xx"] != 1 || stats.StatusClasses["5xx"] != 1 {
+		t.Errorf("scanLogFile() status classes = %v", stats.StatusClasses)
+	}
+	if stats.PatternMatches["api"] != 1 {
+		t.Errorf("scanLogFile() pattern matches = %v", stats.PatternMatches)
+	}
+
+	tooLong := filepath.Join(tempDir, "long.log")
+	if err := os.WriteFile(tooLong, []byte(strings.Repeat("x", maxLineSize+1)), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+	_, err = analyzer.scanLogFile(config.LogConfig{ID: "long", Path: tooLong, Type: "text"}, nil)
+	if _, ok := err.(*ParseError); !ok {
+		t.Errorf("scanLogFile() error = %v, want *ParseError", err)
+	}
+}
+
+func TestScanLogFileTemplates(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lines := []string{
+		"2024-01-01 10:00:00 ERROR payment 1001 failed: card declined",
+		"2024-01-01 10:00:01 INFO payment 1002 accepted",
+		"2024-01-01 10:00:02 ERROR payment 1003 failed: card declined",
+		"2024-01-01 10:00:03 ERROR database connection to 10.0.0.5 lost",
+	}
+	logPath := filepath.Join(tempDir, "app.log")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	analyzer := NewAnalyzer(&config.Config{})
+	result, err := analyzer.scanLogFile(config.LogConfig{
+		ID:        "app",
+		Path:      logPath,
+		Type:      "custom application",
+		Templates: &config.TemplateConfig{Top: 5, MinLevel: "ERROR"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	if len(result.Templates) != 2 {
+		t.Fatalf("scanLogFile() templates = %+v, want 2 error templates", result.Templates)
+	}
+	if result.Templates[0].Pattern != "ERROR payment <NUM> failed: card declined" || result.Templates[0].Count != 2 {
+		t.Errorf("scanLogFile() top template = %+v", result.Templates[0])
+	}
+	if result.Templates[0].Sample != lines[0] {
+		t.Errorf("scanLogFile() sample = %q, want %q", result.Templates[0].Sample, lines[0])
+	}
+	if result.Histogram != nil {
+		t.Errorf("scanLogFile() built a histogram without a bucket: %+v", result.Histogram)
+	}
+
+	analyzer.SetDefaultBucket(time.Minute)
+	result, err = analyzer.scanLogFile(config.LogConfig{ID: "app", Path: logPath, Type: "custom application"}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+	if result.Histogram == nil || len(result.Histogram.Buckets) != 1 || result.Histogram.Buckets[0].Levels["ERROR"] != 3 {
+		t.Errorf("scanLogFile() histogram = %+v, want one bucket with 3 errors", result.Histogram)
+	}
+}
+
+func TestScanLogFileJournal(t *testing.T) {
+	journal := strings.Join([]string{
+		"PRIORITY=3\n_SYSTEMD_UNIT=nginx.service\nMESSAGE=upstream timed out\n",
+		"PRIORITY=6\n_SYSTEMD_UNIT=nginx.service\nMESSAGE=reloaded\n",
+		`{"PRIORITY":"4","_SYSTEMD_UNIT":"sshd.service","MESSAGE":"invalid user"}` + "\n",
+	}, "\n")
+	logPath := filepath.Join(t.TempDir(), "journal.export")
+	if err := os.WriteFile(logPath, []byte(journal), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	analyzer := NewAnalyzer(&config.Config{})
+	result, err := analyzer.scanLogFile(config.LogConfig{ID: "journal", Path: logPath, Type: "journal"}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	stats := result.Stats
+	if stats.Lines != 3 || stats.ParseErrors != 0 {
+		t.Errorf("scanLogFile() lines = %d, parse errors = %d, want 3 and 0", stats.Lines, stats.ParseErrors)
+	}
+	if stats.Units["nginx.service"] != 2 || stats.Units["sshd.service"] != 1 {
+		t.Errorf("scanLogFile() units = %v", stats.Units)
+	}
+	if stats.Priorities["err"] != 1 || stats.Priorities["info"] != 1 || stats.Priorities["warning"] != 1 {
+		t.Errorf("scanLogFile() priorities = %v", stats.Priorities)
+	}
+	if stats.Levels["ERROR"] != 1 || stats.Levels["WARN"] != 1 || stats.Levels["INFO"] != 1 {
+		t.Errorf("scanLogFile() levels = %v", stats.Levels)
+	}
+}
+
+func TestScanLogFileDocker(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+	dir := filepath.Join(t.TempDir(), "containers", id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create container directory: %v", err)
+	}
+	logPath := filepath.Join(dir, id+"-json.log")
+	lines := strings.Join([]string{
+		`{"log":"listening on :8080\n","stream":"stdout","time":"2024-05-24T10:00:00Z"}`,
+		`{"log":"ERROR connection ","stream":"stderr","time":"2024-05-24T10:00:01Z"}`,
+		`{"log":"refused\n","stream":"stderr","time":"2024-05-24T10:00:01Z"}`,
+		`{"log":"{\"level\":\"error\",\"msg\":\"retry failed\"}\n","stream":"stdout","time":"2024-05-24T10:00:02Z"}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(logPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	analyzer := NewAnalyzer(&config.Config{})
+	result, err := analyzer.scanLogFile(config.LogConfig{ID: "api", Path: logPath, Type: "docker"}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	stats := result.Stats
+	if stats.Lines != 3 || stats.ParseErrors != 0 {
+		t.Errorf("scanLogFile() lines = %d, parse errors = %d, want 3 and 0", stats.Lines, stats.ParseErrors)
+	}
+	stdout, stderr := stats.Streams["stdout"], stats.Streams["stderr"]
+	if stdout == nil || stdout.Lines != 2 || stdout.Levels["ERROR"] != 1 {
+		t.Errorf("scanLogFile() stdout stats = %+v", stdout)
+	}
+	if stderr == nil || stderr.Lines != 1 || stderr.Levels["ERROR"] != 1 {
+		t.Errorf("scanLogFile() stderr stats = %+v", stderr)
+	}
+}
+
+func TestScanLogFileMultiline(t *testing.T) {
+	lines := strings.Join([]string{
+		"2024-05-24 10:00:00 INFO Started",
+		"2024-05-24 10:00:01 ERROR Request failed",
+		"java.lang.IllegalStateException: boom",
+		"\tat com.example.Service.run(Service.java:42)",
+		"2024-05-24 10:00:02 ERROR Request failed",
+		"java.lang.IllegalStateException: boom",
+		"\tat com.example.Service.run(Service.java:42)",
+		"2024-05-24 10:00:03 ERROR Lookup failed",
+		"java.lang.NullPointerException",
+		"\tat com.example.Cache.get(Cache.java:7)",
+	}, "\n") + "\n"
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	analyzer := NewAnalyzer(&config.Config{})
+	logConfig := config.LogConfig{ID: "app", Path: logPath, Type: "spring", Multiline: &config.MultilineConfig{Preset: "java"}}
+	result, err := analyzer.scanLogFile(logConfig, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	stats := result.Stats
+	if stats.Lines != 4 || stats.Levels["ERROR"] != 3 {
+		t.Errorf("scanLogFile() lines = %d, levels = %v, want 4 entries with 3 errors", stats.Lines, stats.Levels)
+	}
+	if stats.StackTraces != 3 {
+		t.Errorf("scanLogFile() stack traces = %d, want 3", stats.StackTraces)
+	}
+	if stats.Exceptions["java.lang.IllegalStateException"] != 2 || stats.Exceptions["java.lang.NullPointerException"] != 1 {
+		t.Errorf("scanLogFile() exceptions = %v", stats.Exceptions)
+	}
+}
+
+func TestScanLogFileWhere(t *testing.T) {
+	lines := strings.Join([]string{
+		`{"level":"info","msg":"ok","status":200,"path":"/api/orders"}`,
+		`{"level":"error","msg":"upstream failed","status":502,"path":"/api/orders"}`,
+		`{"level":"warn","msg":"slow","status":503,"path":"/health"}`,
+		`{"level":"error","msg":"crawler","status":500,"path":"/api/search","user_agent":"Googlebot"}`,
+		`not json`,
+	}, "\n") + "\n"
+	logPath := filepath.Join(t.TempDir(), "app.jsonl")
+	if err := os.WriteFile(logPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write test log: %v", err)
+	}
+
+	where, err := query.Parse(`level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	analyzer := NewAnalyzer(&config.Config{})
+	analyzer.SetWhere(where)
+	result, err := analyzer.scanLogFile(config.LogConfig{ID: "app", Path: logPath, Type: "jsonl"}, nil)
+	if err != nil {
+		t.Fatalf("scanLogFile() error = %v", err)
+	}
+
+	stats := result.Stats
+	if stats.Lines != 1 || stats.ParseErrors != 0 || stats.Levels["ERROR"] != 1 {
+		t.Errorf("scanLogFile() lines = %d, parse errors = %d, levels = %v, want the 502 entry only", stats.Lines, stats.ParseErrors, stats.Levels)
+	}
+}
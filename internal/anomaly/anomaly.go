@@ -0,0 +1,265 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+const (
+	KindErrorSpike  = "error_spike"
+	KindVolumeSpike = "volume_spike"
+	KindSilence     = "silence"
+
+	// minHistory is the number of buckets needed before a bucket is scored.
+	minHistory = 3
+)
+
+// Detector flags abnormal buckets in a log's histogram: error or volume
+// spikes compared with a rolling baseline, and gaps where an active log
+// stopped writing entirely. A nil *Detector detects nothing.
+type Detector struct {
+	cfg config.AnomalyConfig
+}
+
+func NewDetector(cfg config.AnomalyConfig) *Detector {
+	return &Detector{cfg: cfg.WithDefaults()}
+}
+
+// BucketSize returns the histogram bucket size used for logs that do not set
+// their own.
+func (d *Detector) BucketSize() time.Duration {
+	return d.cfg.BucketSize()
+}
+
+// Detect returns the anomalies found in h, in chronological order. end is the
+// end of the analysis window, usually the time of the analysis: a log whose
+// last entry is at least SilenceBuckets buckets before it went silent. A zero
+// end skips that check.
+func (d *Detector) Detect(h *reporter.Histogram, end time.Time) []reporter.Anomaly {
+	if d == nil || h == nil || len(h.Buckets) == 0 {
+		return nil
+	}
+
+	size := h.BucketSize()
+	start, _ := h.Span()
+	volume := h.Series(func(b reporter.Bucket) int { return b.Total })
+	errors := h.Series(func(b reporter.Bucket) int { return b.Levels["ERROR"] + b.Levels["FATAL"] })
+
+	var anomalies []reporter.Anomaly
+	anomalies = append(anomalies, d.spikes(KindErrorSpike, "errors", errors, start, size)...)
+	anomalies = append(anomalies, d.spikes(KindVolumeSpike, "entries", volume, start, size)...)
+	anomalies = append(anomalies, d.silences(volume, start, size, end)...)
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Start.Before(anomalies[j].Start)
+	})
+	return anomalies
+}
+
+// spikes scores every bucket against the baseline of the preceding window and
+// merges consecutive flagged buckets into one anomaly.
+func (d *Detector) spikes(kind, noun string, series []int, start time.Time, size time.Duration) []reporter.Anomaly {
+	scores, baselines := d.score(series)
+
+	var anomalies []reporter.Anomaly
+	var current *reporter.Anomaly
+	for i, value := range series {
+		flagged := !math.IsNaN(scores[i]) && scores[i] >= d.cfg.Threshold && value >= d.cfg.MinCount
+		if !flagged {
+			current = nil
+			continue
+		}
+
+		bucketStart := start.Add(time.Duration(i) * size)
+		if current == nil {
+			anomalies = append(anomalies, reporter.Anomaly{
+				Kind:     kind,
+				Start:    bucketStart,
+				Baseline: round(baselines[i]),
+			})
+			current = &anomalies[len(anomalies)-1]
+		}
+
+		current.End = bucketStart.Add(size)
+		if scores[i] > current.Score {
+			current.Score = round(scores[i])
+			current.Value = float64(value)
+			current.Baseline = round(baselines[i])
+		}
+		current.Message = fmt.Sprintf("%g %s in a bucket against a baseline of %g (%s, score %g)",
+			current.Value, noun, current.Baseline, d.cfg.Method, current.Score)
+	}
+
+	return anomalies
+}
+
+// score returns a score and the baseline for every bucket. Buckets without
+// enough history get a NaN score. Buckets scoring above the threshold are
+// left out of the baseline, so a spike lasting several buckets does not hide
+// its own tail. After a full window of flagged buckets the new level is
+// accepted as the baseline.
+func (d *Detector) score(series []int) ([]float64, []float64) {
+	scores := make([]float64, len(series))
+	baselines := make([]float64, len(series))
+
+	alpha := 2 / float64(d.cfg.Window+1)
+	var ewmaMean, ewmaVar float64
+	history := make([]float64, 0, len(series))
+	excluded := 0
+
+	for i, v := range series {
+		x := float64(v)
+		scores[i] = math.NaN()
+
+		if len(history) >= minHistory {
+			switch d.cfg.Method {
+			case config.AnomalyMethodEWMA:
+				baselines[i] = ewmaMean
+				scores[i] = (x - ewmaMean) / spread(math.Sqrt(ewmaVar), ewmaMean)
+			case config.AnomalyMethodMAD:
+				window := history[max(0, len(history)-d.cfg.Window):]
+				center := median(window)
+				deviations := make([]float64, len(window))
+				for j, w := range window {
+					deviations[j] = math.Abs(w - center)
+				}
+				baselines[i] = center
+				scores[i] = (x - center) / spread(1.4826*median(deviations), center)
+			default:
+				mean, std := meanStd(history[max(0, len(history)-d.cfg.Window):])
+				baselines[i] = mean
+				scores[i] = (x - mean) / spread(std, mean)
+			}
+		}
+
+		if !math.IsNaN(scores[i]) && scores[i] >= d.cfg.Threshold && excluded < d.cfg.Window {
+			excluded++
+			continue
+		}
+		excluded = 0
+
+		if len(history) == 0 {
+			ewmaMean = x
+		} else {
+			diff := x - ewmaMean
+			ewmaMean += alpha * diff
+			ewmaVar = (1 - alpha) * (ewmaVar + alpha*diff*diff)
+		}
+		history = append(history, x)
+	}
+
+	return scores, baselines
+}
+
+// silences reports runs of at least SilenceBuckets empty buckets following a
+// window where the log was writing at least one entry per bucket on average,
+// including the run from the last bucket to end when the log never resumed.
+func (d *Detector) silences(volume []int, start time.Time, size time.Duration, end time.Time) []reporter.Anomaly {
+	var anomalies []reporter.Anomaly
+
+	for i := 0; i < len(volume); {
+		if volume[i] != 0 {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(volume) && volume[j] == 0 {
+			j++
+		}
+
+		if silence, ok := d.silence(volume, i, j-i, start, size); ok {
+			anomalies = append(anomalies, silence)
+		}
+		i = j
+	}
+
+	// The series stops at the last non-empty bucket, so the gap up to the end
+	// of the window is counted without materializing its empty buckets.
+	if !end.IsZero() {
+		last := start.Add(time.Duration(len(volume)) * size)
+		if gap := int(end.Sub(last) / size); gap > 0 {
+			if silence, ok := d.silence(volume, len(volume), gap, start, size); ok {
+				anomalies = append(anomalies, silence)
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// silence returns the anomaly of gap empty buckets starting at bucket i, if
+// the gap is long enough and the log was active before it.
+func (d *Detector) silence(volume []int, i, gap int, start time.Time, size time.Duration) (reporter.Anomaly, bool) {
+	baseline, _ := meanStd(toFloats(volume[max(0, i-d.cfg.Window):min(i, len(volume))]))
+	if gap < d.cfg.SilenceBuckets || baseline < 1 {
+		return reporter.Anomaly{}, false
+	}
+
+	return reporter.Anomaly{
+		Kind:     KindSilence,
+		Start:    start.Add(time.Duration(i) * size),
+		End:      start.Add(time.Duration(i+gap) * size),
+		Score:    float64(gap),
+		Value:    0,
+		Baseline: round(baseline),
+		Message:  fmt.Sprintf("no entries for %s after %g entries per bucket", time.Duration(gap)*size, round(baseline)),
+	}, true
+}
+
+// spread returns the deviation used to normalize scores. It never drops below
+// the Poisson noise of the baseline, so a perfectly flat history does not turn
+// every small bump into an anomaly.
+func spread(deviation, baseline float64) float64 {
+	return math.Max(deviation, math.Sqrt(math.Max(baseline, 1)))
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func toFloats(values []int) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
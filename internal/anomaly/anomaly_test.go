@@ -0,0 +1,107 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+var base = time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+// buildHistogram creates a 5m histogram from per-bucket totals and errors.
+// Zero totals are left out, as in a real histogram.
+func buildHistogram(totals, errors []int) *reporter.Histogram {
+	h := &reporter.Histogram{Bucket: "5m"}
+	for i, total := range totals {
+		if total == 0 {
+			continue
+		}
+		h.Buckets = append(h.Buckets, reporter.Bucket{
+			Start:  base.Add(time.Duration(i) * 5 * time.Minute),
+			Total:  total,
+			Levels: map[string]int{"ERROR": errors[i], "INFO": total - errors[i]},
+		})
+	}
+	return h
+}
+
+func TestDetectErrorSpike(t *testing.T) {
+	totals := []int{100, 102, 98, 101, 99, 100, 103, 100, 97, 100}
+	errors := []int{2, 3, 1, 2, 3, 2, 40, 38, 2, 3}
+
+	for _, method := range []string{config.AnomalyMethodZScore, config.AnomalyMethodEWMA, config.AnomalyMethodMAD} {
+		t.Run(method, func(t *testing.T) {
+			detector := NewDetector(config.AnomalyConfig{Method: method})
+			anomalies := detector.Detect(buildHistogram(totals, errors), time.Time{})
+
+			if len(anomalies) != 1 {
+				t.Fatalf("Detect() = %+v, want a single error spike", anomalies)
+			}
+
+			spike := anomalies[0]
+			if spike.Kind != KindErrorSpike {
+				t.Errorf("Detect() kind = %s, want %s", spike.Kind, KindErrorSpike)
+			}
+			if !spike.Start.Equal(base.Add(30*time.Minute)) || !spike.End.Equal(base.Add(40*time.Minute)) {
+				t.Errorf("Detect() range = %v → %v, want 10:30 → 10:40", spike.Start, spike.End)
+			}
+			if spike.Value != 40 || spike.Score < 3 {
+				t.Errorf("Detect() value = %v, score = %v", spike.Value, spike.Score)
+			}
+		})
+	}
+}
+
+func TestDetectIgnoresSmallCounts(t *testing.T) {
+	totals := []int{10, 10, 10, 10, 10}
+	errors := []int{0, 0, 0, 0, 4}
+
+	detector := NewDetector(config.AnomalyConfig{})
+	if anomalies := detector.Detect(buildHistogram(totals, errors), time.Time{}); len(anomalies) != 0 {
+		t.Errorf("Detect() = %+v, want no anomaly below min_count", anomalies)
+	}
+}
+
+func TestDetectSilence(t *testing.T) {
+	totals := []int{50, 48, 52, 0, 0, 0, 0, 49, 51}
+	errors := make([]int, len(totals))
+
+	detector := NewDetector(config.AnomalyConfig{SilenceBuckets: 3})
+	anomalies := detector.Detect(buildHistogram(totals, errors), time.Time{})
+
+	if len(anomalies) != 1 || anomalies[0].Kind != KindSilence {
+		t.Fatalf("Detect() = %+v, want a single silence", anomalies)
+	}
+	if !anomalies[0].Start.Equal(base.Add(15*time.Minute)) || !anomalies[0].End.Equal(base.Add(35*time.Minute)) {
+		t.Errorf("Detect() silence = %v → %v, want 10:15 → 10:35", anomalies[0].Start, anomalies[0].End)
+	}
+}
+
+func TestDetectSilenceUntilEnd(t *testing.T) {
+	totals := []int{50, 48, 52, 49}
+	errors := make([]int, len(totals))
+	h := buildHistogram(totals, errors)
+
+	detector := NewDetector(config.AnomalyConfig{SilenceBuckets: 3})
+	anomalies := detector.Detect(h, base.Add(42*time.Minute))
+
+	if len(anomalies) != 1 || anomalies[0].Kind != KindSilence {
+		t.Fatalf("Detect() = %+v, want a single silence", anomalies)
+	}
+	if !anomalies[0].Start.Equal(base.Add(20*time.Minute)) || !anomalies[0].End.Equal(base.Add(40*time.Minute)) {
+		t.Errorf("Detect() silence = %v → %v, want 10:20 → 10:40", anomalies[0].Start, anomalies[0].End)
+	}
+
+	if anomalies := detector.Detect(h, base.Add(34*time.Minute)); len(anomalies) != 0 {
+		t.Errorf("Detect() = %+v, want no silence shorter than 3 buckets", anomalies)
+	}
+}
+
+func TestNilDetector(t *testing.T) {
+	var detector *Detector
+	if anomalies := detector.Detect(buildHistogram([]int{1}, []int{0}), time.Now()); anomalies != nil {
+		t.Errorf("nil detector Detect() = %v, want nil", anomalies)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnomalyConfig tunes the anomaly detection run on each log's time histogram.
+// Zero values fall back to the defaults below.
+type AnomalyConfig struct {
+	Method         string  `json:"method,omitempty"`
+	Threshold      float64 `json:"threshold,omitempty"`
+	Window         int     `json:"window,omitempty"`
+	MinCount       int     `json:"min_count,omitempty"`
+	SilenceBuckets int     `json:"silence_buckets,omitempty"`
+	Bucket         string  `json:"bucket,omitempty"`
+}
+
+const (
+	AnomalyMethodZScore = "zscore"
+	AnomalyMethodEWMA   = "ewma"
+	AnomalyMethodMAD    = "mad"
+
+	defaultAnomalyThreshold = 3
+	defaultAnomalyWindow    = 12
+	defaultAnomalyMinCount  = 5
+	defaultSilenceBuckets   = 3
+	defaultAnomalyBucket    = 5 * time.Minute
+)
+
+// WithDefaults returns a copy of the configuration with every unset field
+// replaced by its default.
+func (a AnomalyConfig) WithDefaults() AnomalyConfig {
+	if a.Method == "" {
+		a.Method = AnomalyMethodZScore
+	}
+	if a.Threshold == 0 {
+		a.Threshold = defaultAnomalyThreshold
+	}
+	if a.Window == 0 {
+		a.Window = defaultAnomalyWindow
+	}
+	if a.MinCount == 0 {
+		a.MinCount = defaultAnomalyMinCount
+	}
+	if a.SilenceBuckets == 0 {
+		a.SilenceBuckets = defaultSilenceBuckets
+	}
+	if a.Bucket == "" {
+		a.Bucket = defaultAnomalyBucket.String()
+	}
+	return a
+}
+
+// BucketSize returns the bucket size used for logs without their own bucket.
+func (a AnomalyConfig) BucketSize() time.Duration {
+	d, err := time.ParseDuration(a.WithDefaults().Bucket)
+	if err != nil {
+		return defaultAnomalyBucket
+	}
+	return d
+}
+
+func validateAnomaly(a *AnomalyConfig) error {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Method {
+	case "", AnomalyMethodZScore, AnomalyMethodEWMA, AnomalyMethodMAD:
+	default:
+		return fmt.Errorf("anomaly has unknown method: %s", a.Method)
+	}
+
+	if a.Threshold < 0 {
+		return fmt.Errorf("anomaly threshold must be positive")
+	}
+	if a.Window < 0 || a.Window == 1 {
+		return fmt.Errorf("anomaly window must be at least 2 buckets")
+	}
+	if a.MinCount < 0 || a.SilenceBuckets < 0 {
+		return fmt.Errorf("anomaly min_count and silence_buckets cannot be negative")
+	}
+	if a.Bucket != "" {
+		if d, err := time.ParseDuration(a.Bucket); err != nil || d < time.Second {
+			return fmt.Errorf("anomaly has invalid bucket %q", a.Bucket)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAnomaly(t *testing.T) {
+	tests := []struct {
+		name        string
+		anomaly     *AnomalyConfig
+		expectError bool
+	}{
+		{name: "Not configured", anomaly: nil, expectError: false},
+		{name: "Defaults", anomaly: &AnomalyConfig{}, expectError: false},
+		{name: "MAD", anomaly: &AnomalyConfig{Method: "mad", Threshold: 4, Window: 24, Bucket: "1m"}, expectError: false},
+		{name: "Unknown method", anomaly: &AnomalyConfig{Method: "prophet"}, expectError: true},
+		{name: "Window too small", anomaly: &AnomalyConfig{Window: 1}, expectError: true},
+		{name: "Invalid bucket", anomaly: &AnomalyConfig{Bucket: "often"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAnomaly(tt.anomaly)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateAnomaly() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestAnomalyDefaults(t *testing.T) {
+	cfg := AnomalyConfig{}.WithDefaults()
+
+	if cfg.Method != AnomalyMethodZScore || cfg.Threshold != 3 || cfg.Window != 12 {
+		t.Errorf("WithDefaults() = %+v", cfg)
+	}
+	if got := (AnomalyConfig{}).BucketSize(); got != 5*time.Minute {
+		t.Errorf("BucketSize() = %v, want 5m", got)
+	}
+}
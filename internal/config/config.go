@@ -1,19 +1,131 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/multiline"
 )
 
 type LogConfig struct {
-	ID   string `json:"id"`
-	Path string `json:"path"`
-	Type string `json:"type"`
+	ID        string          `json:"id"`
+	Path      string          `json:"path"`
+	Type      string          `json:"type"`
+	Tags      []string        `json:"tags,omitempty"`
+	Enabled   *bool           `json:"enabled,omitempty"`
+	Templates *TemplateConfig `json:"templates,omitempty"`
+	Bucket    string          `json:"bucket,omitempty"`
+	// Timezone is the IANA zone of timestamps written without an offset,
+	// and TimeOffset corrects a skewed clock (e.g. "-90s").
+	Timezone   string `json:"timezone,omitempty"`
+	TimeOffset string `json:"time_offset,omitempty"`
+	// SSH overrides the top-level "ssh" settings for an ssh:// path.
+	SSH *SSHConfig `json:"ssh,omitempty"`
+	// HTTP and S3 override the top-level "http" and "s3" settings for an
+	// http(s):// or s3:// path.
+	HTTP *HTTPConfig `json:"http,omitempty"`
+	S3   *S3Config   `json:"s3,omitempty"`
+	// Multiline assembles entries spanning several lines, such as stack
+	// traces, before they are parsed.
+	Multiline *MultilineConfig `json:"multiline,omitempty"`
+}
+
+// BucketSize returns the histogram bucket size of the log, or 0 when the log
+// has no (valid) "bucket" setting.
+func (l LogConfig) BucketSize() time.Duration {
+	if l.Bucket == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(l.Bucket)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Location returns the time zone of the log, or nil when it has none.
+func (l LogConfig) Location() *time.Location {
+	if l.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(l.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// ClockOffset returns the correction added to every timestamp of the log.
+func (l LogConfig) ClockOffset() time.Duration {
+	if l.TimeOffset == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(l.TimeOffset)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// MultilineConfig groups the lines of a log into entries: a line continues
+// the current entry when it matches one of the continuation patterns of the
+// preset or of Continuation, or, when Start is set, when it does not match
+// Start. MaxLines bounds the lines of an entry.
+type MultilineConfig struct {
+	Preset       string   `json:"preset,omitempty"`
+	Start        string   `json:"start,omitempty"`
+	Continuation []string `json:"continuation,omitempty"`
+	MaxLines     int      `json:"max_lines,omitempty"`
+}
+
+// MultilineRule returns the rule assembling the entries of the log, or nil
+// when the log has no (valid) "multiline" setting.
+func (l LogConfig) MultilineRule() *multiline.Rule {
+	if l.Multiline == nil {
+		return nil
+	}
+	rule, err := multiline.New(l.Multiline.Preset, l.Multiline.Start, l.Multiline.Continuation, l.Multiline.MaxLines)
+	if err != nil {
+		return nil
+	}
+	return rule
+}
+
+// TemplateConfig enables error template mining for a log. Only entries at or
+// above MinLevel are mined when it is set.
+type TemplateConfig struct {
+	Top      int    `json:"top"`
+	MinLevel string `json:"min_level,omitempty"`
+}
+
+// IsEnabled reports whether the log should be analyzed. Entries without an
+// explicit "enabled" field are enabled.
+func (l LogConfig) IsEnabled() bool {
+	return l.Enabled == nil || *l.Enabled
+}
+
+// HasTag reports whether the log carries the given tag.
+func (l LogConfig) HasTag(tag string) bool {
+	return contains(l.Tags, tag)
 }
 
 type Config struct {
-	Logs []LogConfig
+	Logs        []LogConfig        `json:"logs"`
+	Rules       []RuleConfig       `json:"rules,omitempty"`
+	Notifiers   []NotifierConfig   `json:"notifiers,omitempty"`
+	Anomaly     *AnomalyConfig     `json:"anomaly,omitempty"`
+	Correlation *CorrelationConfig `json:"correlation,omitempty"`
+	History     *HistoryConfig     `json:"history,omitempty"`
+	Tracing     *TracingConfig     `json:"tracing,omitempty"`
+	SSH         *SSHConfig         `json:"ssh,omitempty"`
+	HTTP        *HTTPConfig        `json:"http,omitempty"`
+	S3          *S3Config          `json:"s3,omitempty"`
+	Redaction   *RedactionConfig   `json:"redaction,omitempty"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -26,16 +138,81 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
 
-	var logs []LogConfig
-	if err := json.Unmarshal(data, &logs); err != nil {
+	// The configuration is either a bare array of logs or an object with
+	// "logs" and the optional sections such as "rules".
+	cfg := &Config{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, cfg)
+	} else {
+		err = json.Unmarshal(data, &cfg.Logs)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
-	if err := validateConfig(logs); err != nil {
+	if err := validateConfig(cfg.Logs); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateRules(cfg.Rules, cfg.Logs); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateNotifiers(cfg.Notifiers); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateAnomaly(cfg.Anomaly); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateCorrelation(cfg.Correlation); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateHistory(cfg.History); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateTracing(cfg.Tracing); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	return &Config{Logs: logs}, nil
+	if err := validateSSH(cfg.SSH, "ssh"); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := validateHTTP(cfg.HTTP, "http"); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateS3(cfg.S3, "s3"); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := validateRedaction(cfg.Redaction); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	applySourceDefaults(cfg)
+
+	return cfg, nil
+}
+
+// StdinPath is the path of a log read from the standard input.
+const StdinPath = "-"
+
+// IsStdinPath reports whether a log path designates the standard input.
+func IsStdinPath(path string) bool {
+	return path == StdinPath
+}
+
+// ForLog returns the configuration of an ad-hoc analysis of a single log,
+// without a configuration file.
+func ForLog(log LogConfig) (*Config, error) {
+	cfg := &Config{Logs: []LogConfig{log}}
+	if err := validateConfig(cfg.Logs); err != nil {
+		return nil, fmt.Errorf("invalid log: %w", err)
+	}
+	return cfg, nil
 }
 
 func validateConfig(logs []LogConfig) error {
@@ -44,6 +221,7 @@ func validateConfig(logs []LogConfig) error {
 	}
 
 	ids := make(map[string]bool)
+	stdinLog := ""
 	for _, log := range logs {
 		if log.ID == "" {
 			return fmt.Errorf("log entry missing ID")
@@ -54,6 +232,62 @@ func validateConfig(logs []LogConfig) error {
 		if log.Type == "" {
 			return fmt.Errorf("log entry %s missing type", log.ID)
 		}
+		for _, tag := range log.Tags {
+			if strings.TrimSpace(tag) == "" {
+				return fmt.Errorf("log entry %s has an empty tag", log.ID)
+			}
+		}
+		if log.Templates != nil {
+			if log.Templates.Top < 0 {
+				return fmt.Errorf("log entry %s has a negative templates.top", log.ID)
+			}
+			if log.Templates.MinLevel != "" {
+				if _, err := entry.ParseLevel(log.Templates.MinLevel); err != nil {
+					return fmt.Errorf("log entry %s has invalid templates.min_level: %w", log.ID, err)
+				}
+			}
+		}
+		if log.Bucket != "" {
+			if d, err := time.ParseDuration(log.Bucket); err != nil || d < time.Second {
+				return fmt.Errorf("log entry %s has invalid bucket %q (use e.g. 1m, 5m, 1h)", log.ID, log.Bucket)
+			}
+		}
+		if log.Timezone != "" {
+			if _, err := time.LoadLocation(log.Timezone); err != nil {
+				return fmt.Errorf("log entry %s has invalid timezone %q: %w", log.ID, log.Timezone, err)
+			}
+		}
+		if log.TimeOffset != "" {
+			if _, err := time.ParseDuration(log.TimeOffset); err != nil {
+				return fmt.Errorf("log entry %s has invalid time_offset %q: %w", log.ID, log.TimeOffset, err)
+			}
+		}
+		if log.Multiline != nil {
+			if _, err := multiline.New(log.Multiline.Preset, log.Multiline.Start, log.Multiline.Continuation, log.Multiline.MaxLines); err != nil {
+				return fmt.Errorf("log entry %s has invalid multiline: %w", log.ID, err)
+			}
+			if _, ok := entry.ForType(log.Type).(entry.RecordSplitter); ok {
+				return fmt.Errorf("log entry %s has multiline, which %s logs do not support", log.ID, log.Type)
+			}
+		}
+		if err := validateSourcePath(log.Path); err != nil {
+			return fmt.Errorf("log entry %s has invalid path: %w", log.ID, err)
+		}
+		if err := validateSSH(log.SSH, "log entry "+log.ID+" ssh"); err != nil {
+			return err
+		}
+		if err := validateHTTP(log.HTTP, "log entry "+log.ID+" http"); err != nil {
+			return err
+		}
+		if err := validateS3(log.S3, "log entry "+log.ID+" s3"); err != nil {
+			return err
+		}
+		if IsStdinPath(log.Path) {
+			if stdinLog != "" {
+				return fmt.Errorf("log entries %s and %s both read the standard input", stdinLog, log.ID)
+			}
+			stdinLog = log.ID
+		}
 		if ids[log.ID] {
 			return fmt.Errorf("duplicate log ID: %s", log.ID)
 		}
@@ -61,4 +295,4 @@ func validateConfig(logs []LogConfig) error {
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
@@ -34,6 +34,144 @@ func TestLoadConfig(t *testing.T) {
 			]`,
 			expectError: false,
 		},
+		{
+			name: "Tags and enabled",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "nginx",
+					"tags": ["web", "nginx"],
+					"enabled": false
+				}
+			]`,
+			expectError: false,
+		},
+		{
+			name: "Empty tag",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "nginx",
+					"tags": [""]
+				}
+			]`,
+			expectError: true,
+		},
+		{
+			name: "Object config with rules",
+			configJSON: `{
+				"logs": [
+					{
+						"id": "log1",
+						"path": "/var/log/app1.log",
+						"type": "nginx"
+					}
+				],
+				"rules": [
+					{
+						"name": "errors",
+						"log_id": "log1",
+						"metric": "level.ERROR",
+						"threshold": 50
+					}
+				]
+			}`,
+			expectError: false,
+		},
+		{
+			name: "Object config with invalid rule",
+			configJSON: `{
+				"logs": [
+					{
+						"id": "log1",
+						"path": "/var/log/app1.log",
+						"type": "nginx"
+					}
+				],
+				"rules": [
+					{
+						"name": "errors",
+						"log_id": "missing",
+						"metric": "lines"
+					}
+				]
+			}`,
+			expectError: true,
+		},
+		{
+			name: "Invalid bucket",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "nginx",
+					"bucket": "5 minutes"
+				}
+			]`,
+			expectError: true,
+		},
+		{
+			name: "Multiline preset",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "spring boot",
+					"multiline": {"preset": "java", "max_lines": 200}
+				}
+			]`,
+			expectError: false,
+		},
+		{
+			name: "Invalid multiline pattern",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "text",
+					"multiline": {"start": "^(\\d+"}
+				}
+			]`,
+			expectError: true,
+		},
+		{
+			name: "Multiline on journal log",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app.journal",
+					"type": "journal",
+					"multiline": {"preset": "python"}
+				}
+			]`,
+			expectError: true,
+		},
+		{
+			name: "Invalid timezone",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "nginx",
+					"timezone": "Mars/Olympus"
+				}
+			]`,
+			expectError: true,
+		},
+		{
+			name: "Invalid time offset",
+			configJSON: `[
+				{
+					"id": "log1",
+					"path": "/var/log/app1.log",
+					"type": "nginx",
+					"time_offset": "ten seconds"
+				}
+			]`,
+			expectError: true,
+		},
 		{
 			name:        "Empty config",
 			configJSON:  `[]`,
@@ -133,3 +271,25 @@ func TestLoadConfigInvalidJSON(t *testing.T) {
 		t.Error("LoadConfig() expected error for invalid JSON, got nil")
 	}
 }
+
+func TestForLog(t *testing.T) {
+	cfg, err := ForLog(LogConfig{ID: "stdin", Path: StdinPath, Type: "jsonl"})
+	if err != nil {
+		t.Fatalf("ForLog() error = %v", err)
+	}
+	if len(cfg.Logs) != 1 || !IsStdinPath(cfg.Logs[0].Path) {
+		t.Errorf("ForLog() logs = %+v", cfg.Logs)
+	}
+
+	if _, err := ForLog(LogConfig{ID: "stdin", Path: StdinPath}); err == nil {
+		t.Error("ForLog() accepted a log without type")
+	}
+
+	err = validateConfig([]LogConfig{
+		{ID: "a", Path: StdinPath, Type: "text"},
+		{ID: "b", Path: StdinPath, Type: "text"},
+	})
+	if err == nil {
+		t.Error("validateConfig() accepted two logs reading the standard input")
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// CorrelationConfig maps log types to the request/trace ID carried by their
+// entries, so entries of different logs can be joined per request.
+type CorrelationConfig struct {
+	Fields map[string]CorrelationField `json:"fields"`
+}
+
+// CorrelationField locates the ID in an entry: either a parsed field name
+// (e.g. "request_id" in JSON logs) or a regular expression whose first
+// capturing group is matched against the raw line.
+type CorrelationField struct {
+	Field   string `json:"field,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+func validateCorrelation(c *CorrelationConfig) error {
+	if c == nil {
+		return nil
+	}
+
+	if len(c.Fields) == 0 {
+		return fmt.Errorf("correlation needs at least one entry in fields")
+	}
+
+	for logType, field := range c.Fields {
+		if (field.Field == "") == (field.Pattern == "") {
+			return fmt.Errorf("correlation for type %q needs either a field or a pattern", logType)
+		}
+		if field.Pattern != "" {
+			pattern, err := regexp.Compile(field.Pattern)
+			if err != nil {
+				return fmt.Errorf("correlation for type %q has invalid pattern: %w", logType, err)
+			}
+			if pattern.NumSubexp() < 1 {
+				return fmt.Errorf("correlation pattern for type %q needs a capturing group", logType)
+			}
+		}
+	}
+
+	return nil
+}
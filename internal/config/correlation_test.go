@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateCorrelation(t *testing.T) {
+	tests := []struct {
+		name        string
+		correlation *CorrelationConfig
+		expectError bool
+	}{
+		{name: "Not configured", correlation: nil, expectError: false},
+		{
+			name: "Field and pattern",
+			correlation: &CorrelationConfig{Fields: map[string]CorrelationField{
+				"backend json": {Field: "request_id"},
+				"nginx access": {Pattern: `"req=([^"]+)"`},
+			}},
+			expectError: false,
+		},
+		{name: "No fields", correlation: &CorrelationConfig{}, expectError: true},
+		{
+			name:        "Both field and pattern",
+			correlation: &CorrelationConfig{Fields: map[string]CorrelationField{"json": {Field: "id", Pattern: "(x)"}}},
+			expectError: true,
+		},
+		{
+			name:        "Pattern without group",
+			correlation: &CorrelationConfig{Fields: map[string]CorrelationField{"json": {Pattern: "req=[0-9]+"}}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCorrelation(tt.correlation)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateCorrelation() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
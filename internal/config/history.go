@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryConfig enables the local history store: every analyze run appends
+// its results to Path, and records older than Retention are pruned.
+type HistoryConfig struct {
+	Path      string `json:"path"`
+	Retention string `json:"retention,omitempty"`
+}
+
+// RetentionPeriod returns how long records are kept, or 0 to keep them
+// forever.
+func (h HistoryConfig) RetentionPeriod() time.Duration {
+	d, err := ParseRetention(h.Retention)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// ParseRetention parses a retention period written as a Go duration ("720h")
+// or as a number of days ("30d"). An empty value means no retention limit.
+func ParseRetention(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid retention %q (use e.g. 30d or 720h)", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention %q (use e.g. 30d or 720h)", value)
+	}
+	return d, nil
+}
+
+func validateHistory(h *HistoryConfig) error {
+	if h == nil {
+		return nil
+	}
+
+	if h.Path == "" {
+		return fmt.Errorf("history is missing path")
+	}
+	if _, err := ParseRetention(h.Retention); err != nil {
+		return fmt.Errorf("history has %w", err)
+	}
+
+	return nil
+}
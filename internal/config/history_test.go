@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "", expected: 0},
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "36h", expected: 36 * time.Hour},
+		{value: "0d", expectError: true},
+		{value: "-1h", expectError: true},
+		{value: "a month", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRetention(tt.value)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseRetention(%q) error = %v, expectError %v", tt.value, err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseRetention(%q) = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     *HistoryConfig
+		expectError bool
+	}{
+		{name: "Not configured", history: nil, expectError: false},
+		{name: "Valid", history: &HistoryConfig{Path: "history.jsonl", Retention: "90d"}, expectError: false},
+		{name: "Missing path", history: &HistoryConfig{Retention: "90d"}, expectError: true},
+		{name: "Invalid retention", history: &HistoryConfig{Path: "history.jsonl", Retention: "forever"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHistory(tt.history)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateHistory() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+// NotifierConfig declares a sink that is notified when a run has failed logs
+// or fired alerts.
+type NotifierConfig struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	URL        string            `json:"url,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Template   string            `json:"template,omitempty"`
+	SMTP       *SMTPConfig       `json:"smtp,omitempty"`
+	On         []string          `json:"on,omitempty"`
+	Retries    *int              `json:"retries,omitempty"`
+	RetryDelay string            `json:"retry_delay,omitempty"`
+}
+
+// SMTPConfig holds the settings of an email notifier. The password can be
+// read from the environment variable named by PasswordEnv instead of being
+// stored in the file.
+type SMTPConfig struct {
+	Host        string   `json:"host"`
+	Port        int      `json:"port,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	PasswordEnv string   `json:"password_env,omitempty"`
+	From        string   `json:"from"`
+	To          []string `json:"to"`
+}
+
+const (
+	NotifyOnFailure = "failure"
+	NotifyOnAlert   = "alert"
+
+	defaultNotifyRetries    = 3
+	defaultNotifyRetryDelay = time.Second
+)
+
+var notifierTypes = []string{"webhook", "slack", "email"}
+
+// Triggers returns the events the notifier fires on, defaulting to both
+// failures and alerts.
+func (n NotifierConfig) Triggers() []string {
+	if len(n.On) == 0 {
+		return []string{NotifyOnFailure, NotifyOnAlert}
+	}
+	return n.On
+}
+
+// RetryCount returns the number of retries after a failed delivery.
+func (n NotifierConfig) RetryCount() int {
+	if n.Retries == nil {
+		return defaultNotifyRetries
+	}
+	return *n.Retries
+}
+
+// RetryInterval returns the delay before the first retry; it doubles after
+// every attempt.
+func (n NotifierConfig) RetryInterval() time.Duration {
+	if n.RetryDelay == "" {
+		return defaultNotifyRetryDelay
+	}
+	d, err := time.ParseDuration(n.RetryDelay)
+	if err != nil {
+		return defaultNotifyRetryDelay
+	}
+	return d
+}
+
+// SMTPPassword returns the configured password, preferring PasswordEnv.
+func (s SMTPConfig) SMTPPassword() string {
+	if s.PasswordEnv != "" {
+		return os.Getenv(s.PasswordEnv)
+	}
+	return s.Password
+}
+
+func validateNotifiers(notifiers []NotifierConfig) error {
+	names := make(map[string]bool, len(notifiers))
+	for _, n := range notifiers {
+		if n.Name == "" {
+			return fmt.Errorf("notifier missing name")
+		}
+		if names[n.Name] {
+			return fmt.Errorf("duplicate notifier name: %s", n.Name)
+		}
+		names[n.Name] = true
+
+		if !contains(notifierTypes, n.Type) {
+			return fmt.Errorf("notifier %s has unknown type: %s", n.Name, n.Type)
+		}
+
+		for _, on := range n.On {
+			if on != NotifyOnFailure && on != NotifyOnAlert {
+				return fmt.Errorf("notifier %s has unknown trigger: %s", n.Name, on)
+			}
+		}
+
+		if n.RetryCount() < 0 {
+			return fmt.Errorf("notifier %s has negative retries", n.Name)
+		}
+		if n.RetryDelay != "" {
+			if _, err := time.ParseDuration(n.RetryDelay); err != nil {
+				return fmt.Errorf("notifier %s has invalid retry_delay: %w", n.Name, err)
+			}
+		}
+
+		switch n.Type {
+		case "webhook", "slack":
+			u, err := url.Parse(n.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("notifier %s needs an http(s) url", n.Name)
+			}
+		case "email":
+			if n.SMTP == nil || n.SMTP.Host == "" {
+				return fmt.Errorf("notifier %s needs smtp.host", n.Name)
+			}
+			if n.SMTP.From == "" || len(n.SMTP.To) == 0 {
+				return fmt.Errorf("notifier %s needs smtp.from and smtp.to", n.Name)
+			}
+		}
+	}
+
+	return nil
+}
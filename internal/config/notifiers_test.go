@@ -0,0 +1,83 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateNotifiers(t *testing.T) {
+	tests := []struct {
+		name        string
+		notifiers   []NotifierConfig
+		expectError bool
+	}{
+		{
+			name: "Valid notifiers",
+			notifiers: []NotifierConfig{
+				{Name: "hook", Type: "webhook", URL: "https://example.com/hook", Template: `{"text": {{json .Title}}}`},
+				{Name: "slack", Type: "slack", URL: "https://hooks.slack.com/services/x", On: []string{"alert"}},
+				{Name: "mail", Type: "email", SMTP: &SMTPConfig{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}}},
+			},
+			expectError: false,
+		},
+		{
+			name:        "Missing name",
+			notifiers:   []NotifierConfig{{Type: "slack", URL: "https://example.com"}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown type",
+			notifiers:   []NotifierConfig{{Name: "a", Type: "pager"}},
+			expectError: true,
+		},
+		{
+			name:        "Webhook without URL",
+			notifiers:   []NotifierConfig{{Name: "a", Type: "webhook"}},
+			expectError: true,
+		},
+		{
+			name:        "Email without recipients",
+			notifiers:   []NotifierConfig{{Name: "a", Type: "email", SMTP: &SMTPConfig{Host: "smtp.example.com", From: "a@example.com"}}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown trigger",
+			notifiers:   []NotifierConfig{{Name: "a", Type: "slack", URL: "https://example.com", On: []string{"success"}}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid retry delay",
+			notifiers:   []NotifierConfig{{Name: "a", Type: "slack", URL: "https://example.com", RetryDelay: "soon"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNotifiers(tt.notifiers)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateNotifiers() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestNotifierDefaults(t *testing.T) {
+	n := NotifierConfig{Name: "a", Type: "slack"}
+
+	if got := n.RetryCount(); got != 3 {
+		t.Errorf("RetryCount() = %d, want 3", got)
+	}
+	if got := n.RetryInterval(); got != time.Second {
+		t.Errorf("RetryInterval() = %v, want 1s", got)
+	}
+	if got := n.Triggers(); len(got) != 2 {
+		t.Errorf("Triggers() = %v, want failure and alert", got)
+	}
+
+	t.Setenv("LOGANALYZER_TEST_SMTP_PASSWORD", "from-env")
+	smtp := SMTPConfig{Password: "inline", PasswordEnv: "LOGANALYZER_TEST_SMTP_PASSWORD"}
+	if got := smtp.SMTPPassword(); got != "from-env" {
+		t.Errorf("SMTPPassword() = %q, want value from environment", got)
+	}
+}
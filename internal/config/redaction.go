@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RedactionConfig masks sensitive data in everything the analyzer outputs.
+// Detectors selects the built-in detectors, all of them when empty, and
+// Patterns adds custom ones. Every match is replaced by a token derived from
+// a keyed hash of the value, so equal values get equal tokens. The key is
+// read from the KeyEnv environment variable, making tokens stable across
+// runs; without it a random key is used for each run.
+type RedactionConfig struct {
+	Detectors []string           `json:"detectors,omitempty"`
+	Patterns  []RedactionPattern `json:"patterns,omitempty"`
+	KeyEnv    string             `json:"key_env,omitempty"`
+}
+
+// RedactionPattern is a custom detector. When Regex has a capture group,
+// only the text of the first group is masked, e.g. the value of
+// `password=(\S+)`.
+type RedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+}
+
+const (
+	DetectorEmail      = "email"
+	DetectorToken      = "token"
+	DetectorCreditCard = "credit_card"
+	DetectorIP         = "ip"
+)
+
+// RedactionDetectors are the built-in detectors.
+var RedactionDetectors = []string{DetectorEmail, DetectorToken, DetectorCreditCard, DetectorIP}
+
+var redactionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// EnabledDetectors returns the built-in detectors to run.
+func (r RedactionConfig) EnabledDetectors() []string {
+	if len(r.Detectors) == 0 {
+		return RedactionDetectors
+	}
+	return r.Detectors
+}
+
+// Key returns the hashing key of the KeyEnv variable, or nil when it is not
+// set.
+func (r RedactionConfig) Key() []byte {
+	if r.KeyEnv == "" {
+		return nil
+	}
+	if key := os.Getenv(r.KeyEnv); key != "" {
+		return []byte(key)
+	}
+	return nil
+}
+
+func validateRedaction(r *RedactionConfig) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, detector := range r.Detectors {
+		if !contains(RedactionDetectors, detector) {
+			return fmt.Errorf("redaction has unknown detector %q (use %s)", detector, strings.Join(RedactionDetectors, ", "))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, pattern := range r.Patterns {
+		if !redactionNamePattern.MatchString(pattern.Name) {
+			return fmt.Errorf("redaction pattern %d has invalid name %q (use lowercase letters, digits and _)", i+1, pattern.Name)
+		}
+		if names[pattern.Name] || contains(RedactionDetectors, pattern.Name) {
+			return fmt.Errorf("redaction pattern %s is defined twice", pattern.Name)
+		}
+		names[pattern.Name] = true
+		if pattern.Regex == "" {
+			return fmt.Errorf("redaction pattern %s missing regex", pattern.Name)
+		}
+		if _, err := regexp.Compile(pattern.Regex); err != nil {
+			return fmt.Errorf("redaction pattern %s has invalid regex: %w", pattern.Name, err)
+		}
+	}
+
+	return nil
+}
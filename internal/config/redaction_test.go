@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateRedaction(t *testing.T) {
+	tests := []struct {
+		name        string
+		redaction   *RedactionConfig
+		expectError bool
+	}{
+		{name: "Not configured", redaction: nil, expectError: false},
+		{name: "Defaults", redaction: &RedactionConfig{}, expectError: false},
+		{name: "Detectors and pattern", redaction: &RedactionConfig{Detectors: []string{"email", "ip"}, Patterns: []RedactionPattern{{Name: "customer_id", Regex: `CUST-\d+`}}}, expectError: false},
+		{name: "Unknown detector", redaction: &RedactionConfig{Detectors: []string{"phone"}}, expectError: true},
+		{name: "Invalid name", redaction: &RedactionConfig{Patterns: []RedactionPattern{{Name: "Customer ID", Regex: `x`}}}, expectError: true},
+		{name: "Built-in name", redaction: &RedactionConfig{Patterns: []RedactionPattern{{Name: "ip", Regex: `x`}}}, expectError: true},
+		{name: "Duplicate name", redaction: &RedactionConfig{Patterns: []RedactionPattern{{Name: "a", Regex: `x`}, {Name: "a", Regex: `y`}}}, expectError: true},
+		{name: "Missing regex", redaction: &RedactionConfig{Patterns: []RedactionPattern{{Name: "a"}}}, expectError: true},
+		{name: "Invalid regex", redaction: &RedactionConfig{Patterns: []RedactionPattern{{Name: "a", Regex: `(`}}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRedaction(tt.redaction)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateRedaction() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPConfig holds the settings used to download logs whose path is an
+// http:// or https:// URL. The top-level "http" section applies to every such
+// log without its own.
+type HTTPConfig struct {
+	Headers     map[string]string `json:"headers,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Password    string            `json:"password,omitempty"`
+	PasswordEnv string            `json:"password_env,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+}
+
+// S3Config holds the settings used to read logs whose path is an s3:// URL.
+// Unset credentials and region fall back to the standard AWS environment
+// variables. The top-level "s3" section applies to every such log without its
+// own.
+type S3Config struct {
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	SessionToken    string `json:"session_token,omitempty"`
+	// PathStyle addresses buckets as endpoint/bucket instead of
+	// bucket.endpoint. It defaults to true with a custom endpoint, as
+	// MinIO and most S3-compatible stores expect.
+	PathStyle *bool  `json:"path_style,omitempty"`
+	Timeout   string `json:"timeout,omitempty"`
+}
+
+const (
+	defaultHTTPTimeout = 30 * time.Second
+	defaultS3Region    = "us-east-1"
+)
+
+// BasicAuthPassword returns the configured password, preferring PasswordEnv.
+func (h HTTPConfig) BasicAuthPassword() string {
+	if h.PasswordEnv != "" {
+		return os.Getenv(h.PasswordEnv)
+	}
+	return h.Password
+}
+
+// ResponseTimeout returns how long to wait for the response headers, 30s by
+// default. The download itself is not limited.
+func (h HTTPConfig) ResponseTimeout() time.Duration {
+	return parseTimeout(h.Timeout, defaultHTTPTimeout)
+}
+
+// RegionOrDefault returns the region, falling back to AWS_REGION,
+// AWS_DEFAULT_REGION and then us-east-1.
+func (s S3Config) RegionOrDefault() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if env := os.Getenv(name); env != "" {
+			return env
+		}
+	}
+	return defaultS3Region
+}
+
+// EndpointOrDefault returns the base URL of the object store, falling back to
+// AWS_ENDPOINT_URL_S3, AWS_ENDPOINT_URL and then Amazon S3 in the region.
+func (s S3Config) EndpointOrDefault() string {
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/")
+	}
+	for _, name := range []string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"} {
+		if env := os.Getenv(name); env != "" {
+			return strings.TrimSuffix(env, "/")
+		}
+	}
+	return "https://s3." + s.RegionOrDefault() + ".amazonaws.com"
+}
+
+// UsePathStyle reports whether buckets are addressed in the URL path.
+func (s S3Config) UsePathStyle() bool {
+	if s.PathStyle != nil {
+		return *s.PathStyle
+	}
+	return !strings.HasSuffix(s.EndpointOrDefault(), ".amazonaws.com")
+}
+
+// Credentials returns the access key, secret key and session token, each
+// falling back to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN. Without an access key requests are sent unsigned, which
+// public buckets accept.
+func (s S3Config) Credentials() (accessKeyID, secretAccessKey, sessionToken string) {
+	accessKeyID, secretAccessKey, sessionToken = s.AccessKeyID, s.SecretAccessKey, s.SessionToken
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if sessionToken == "" {
+			sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+	}
+	return accessKeyID, secretAccessKey, sessionToken
+}
+
+// ResponseTimeout returns how long to wait for the response headers, 30s by
+// default.
+func (s S3Config) ResponseTimeout() time.Duration {
+	return parseTimeout(s.Timeout, defaultHTTPTimeout)
+}
+
+func parseTimeout(value string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// S3Location is a bucket and key pattern parsed from an s3:// path.
+type S3Location struct {
+	Bucket string
+	// Key is an object key, a prefix ending with "/" or a glob pattern
+	// such as "app/2024-05-*.log".
+	Key string
+}
+
+// Prefix returns the part of the key before the first glob metacharacter,
+// used to list the candidate objects.
+func (l S3Location) Prefix() string {
+	if i := strings.IndexAny(l.Key, "*?["); i >= 0 {
+		return l.Key[:i]
+	}
+	return l.Key
+}
+
+// IsPattern reports whether the key designates several objects: a prefix
+// ending with "/", an empty key or a glob pattern.
+func (l S3Location) IsPattern() bool {
+	return l.Key == "" || strings.HasSuffix(l.Key, "/") || strings.ContainsAny(l.Key, "*?[")
+}
+
+// IsHTTPPath reports whether a log path is an http:// or https:// URL.
+func IsHTTPPath(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// IsS3Path reports whether a log path designates objects in a bucket.
+func IsS3Path(path string) bool {
+	return strings.HasPrefix(path, "s3://")
+}
+
+// IsRemotePath reports whether a log path designates a file that is not on
+// the local file system.
+func IsRemotePath(path string) bool {
+	return IsSSHPath(path) || IsHTTPPath(path) || IsS3Path(path)
+}
+
+// ParseS3Path parses "s3://bucket/key", where the key may be a prefix or a
+// glob pattern.
+func ParseS3Path(path string) (S3Location, error) {
+	rest, ok := strings.CutPrefix(path, "s3://")
+	if !ok {
+		return S3Location{}, fmt.Errorf("%q is not an s3:// path", path)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return S3Location{}, fmt.Errorf("%q has no bucket", path)
+	}
+	return S3Location{Bucket: bucket, Key: key}, nil
+}
+
+func validateHTTPPath(path string) error {
+	u, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", path)
+	}
+	return nil
+}
+
+func validateHTTP(h *HTTPConfig, name string) error {
+	if h == nil {
+		return nil
+	}
+	if h.Timeout != "" {
+		if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("%s has invalid timeout %q", name, h.Timeout)
+		}
+	}
+	return nil
+}
+
+func validateS3(s *S3Config, name string) error {
+	if s == nil {
+		return nil
+	}
+	if s.Endpoint != "" {
+		u, err := url.Parse(s.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s has invalid endpoint %q (use e.g. http://localhost:9000)", name, s.Endpoint)
+		}
+	}
+	if (s.AccessKeyID == "") != (s.SecretAccessKey == "") {
+		return fmt.Errorf("%s needs both access_key_id and secret_access_key", name)
+	}
+	if s.Timeout != "" {
+		if d, err := time.ParseDuration(s.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("%s has invalid timeout %q", name, s.Timeout)
+		}
+	}
+	return nil
+}
+
+// validateSourcePath checks the URL of a remote log.
+func validateSourcePath(path string) error {
+	switch {
+	case IsSSHPath(path):
+		_, err := ParseSSHPath(path)
+		return err
+	case IsHTTPPath(path):
+		return validateHTTPPath(path)
+	case IsS3Path(path):
+		_, err := ParseS3Path(path)
+		return err
+	}
+	return nil
+}
+
+// applySourceDefaults gives every remote log without its own connection
+// settings the top-level ones.
+func applySourceDefaults(cfg *Config) {
+	for i := range cfg.Logs {
+		log := &cfg.Logs[i]
+		switch {
+		case IsSSHPath(log.Path) && log.SSH == nil && cfg.SSH != nil:
+			ssh := *cfg.SSH
+			log.SSH = &ssh
+		case IsHTTPPath(log.Path) && log.HTTP == nil && cfg.HTTP != nil:
+			http := *cfg.HTTP
+			log.HTTP = &http
+		case IsS3Path(log.Path) && log.S3 == nil && cfg.S3 != nil:
+			s3 := *cfg.S3
+			log.S3 = &s3
+		}
+	}
+}
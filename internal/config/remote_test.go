@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseS3Path(t *testing.T) {
+	tests := []struct {
+		path        string
+		expected    S3Location
+		prefix      string
+		pattern     bool
+		expectError bool
+	}{
+		{path: "s3://logs/app/2024-05-24.log", expected: S3Location{Bucket: "logs", Key: "app/2024-05-24.log"}, prefix: "app/2024-05-24.log"},
+		{path: "s3://logs/app/", expected: S3Location{Bucket: "logs", Key: "app/"}, prefix: "app/", pattern: true},
+		{path: "s3://logs", expected: S3Location{Bucket: "logs"}, pattern: true},
+		{path: "s3://logs/app/2024-05-*.log", expected: S3Location{Bucket: "logs", Key: "app/2024-05-*.log"}, prefix: "app/2024-05-", pattern: true},
+		{path: "s3:///app.log", expectError: true},
+		{path: "/var/log/app.log", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseS3Path(tt.path)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseS3Path() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.expectError {
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("ParseS3Path() = %+v, want %+v", got, tt.expected)
+			}
+			if got.Prefix() != tt.prefix {
+				t.Errorf("Prefix() = %q, want %q", got.Prefix(), tt.prefix)
+			}
+			if got.IsPattern() != tt.pattern {
+				t.Errorf("IsPattern() = %v, want %v", got.IsPattern(), tt.pattern)
+			}
+		})
+	}
+}
+
+func TestLoadConfigRemoteDefaults(t *testing.T) {
+	configContent := `{
+		"logs": [
+			{"id": "appliance", "path": "https://fw1.example.com/logs/system.log", "type": "syslog"},
+			{"id": "archive", "path": "s3://logs/app/2024-05-*.log", "type": "app"},
+			{"id": "other", "path": "s3://other/app.log", "type": "app", "s3": {"region": "eu-west-3"}},
+			{"id": "local", "path": "/var/log/app.log", "type": "app"}
+		],
+		"http": {"headers": {"Authorization": "Bearer abc"}},
+		"s3": {"endpoint": "http://localhost:9000", "access_key_id": "minio", "secret_access_key": "minio123"}
+	}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if h := cfg.Logs[0].HTTP; h == nil || h.Headers["Authorization"] != "Bearer abc" {
+		t.Errorf("appliance http = %+v, want the top-level settings", h)
+	}
+	if s := cfg.Logs[1].S3; s == nil || s.EndpointOrDefault() != "http://localhost:9000" || !s.UsePathStyle() {
+		t.Errorf("archive s3 = %+v, want the top-level settings", s)
+	}
+	if s := cfg.Logs[2].S3; s == nil || s.Endpoint != "" || s.RegionOrDefault() != "eu-west-3" {
+		t.Errorf("other s3 = %+v, want its own settings", s)
+	}
+	if cfg.Logs[3].HTTP != nil || cfg.Logs[3].S3 != nil {
+		t.Errorf("local log got remote settings")
+	}
+}
+
+func TestS3ConfigDefaults(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "eu-central-1")
+	t.Setenv("AWS_ENDPOINT_URL_S3", "")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secretenv")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+
+	s := S3Config{}
+	if got := s.EndpointOrDefault(); got != "https://s3.eu-central-1.amazonaws.com" {
+		t.Errorf("EndpointOrDefault() = %s", got)
+	}
+	if s.UsePathStyle() {
+		t.Error("UsePathStyle() = true for Amazon S3")
+	}
+	if id, secret, token := s.Credentials(); id != "AKIDENV" || secret != "secretenv" || token != "token" {
+		t.Errorf("Credentials() = %s, %s, %s, want the environment", id, secret, token)
+	}
+
+	s = S3Config{AccessKeyID: "minio", SecretAccessKey: "minio123"}
+	if id, secret, token := s.Credentials(); id != "minio" || secret != "minio123" || token != "" {
+		t.Errorf("Credentials() = %s, %s, %s, want the configured keys", id, secret, token)
+	}
+}
+
+func TestValidateRemote(t *testing.T) {
+	if err := validateS3(&S3Config{Endpoint: "localhost:9000"}, "s3"); err == nil {
+		t.Error("validateS3() accepted an endpoint without scheme")
+	}
+	if err := validateS3(&S3Config{AccessKeyID: "minio"}, "s3"); err == nil {
+		t.Error("validateS3() accepted an access key without secret")
+	}
+	if err := validateS3(&S3Config{Endpoint: "http://localhost:9000", Timeout: "5s"}, "s3"); err != nil {
+		t.Errorf("validateS3() error = %v", err)
+	}
+	if err := validateHTTP(&HTTPConfig{Timeout: "-1s"}, "http"); err == nil {
+		t.Error("validateHTTP() accepted a negative timeout")
+	}
+	if err := validateSourcePath("https:///logs/app.log"); err == nil {
+		t.Error("validateSourcePath() accepted a URL without host")
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RuleConfig declares an alerting rule evaluated against the aggregates of
+// each analyzed log. A rule either compares a metric (e.g. "level.ERROR",
+// "status.5xx_ratio") to a threshold, or counts the lines matching Pattern.
+type RuleConfig struct {
+	Name      string  `json:"name"`
+	LogID     string  `json:"log_id,omitempty"`
+	Metric    string  `json:"metric,omitempty"`
+	Pattern   string  `json:"pattern,omitempty"`
+	Op        string  `json:"op,omitempty"`
+	Threshold float64 `json:"threshold"`
+	Severity  string  `json:"severity,omitempty"`
+	Message   string  `json:"message,omitempty"`
+}
+
+var (
+	ruleOps        = []string{">", ">=", "<", "<=", "==", "!="}
+	ruleSeverities = []string{"info", "warning", "critical"}
+	metricPattern  = regexp.MustCompile(`^(lines|bytes|parse_errors|error_rate|(level\.[A-Za-z]+|status\.[1-5]xx)(_ratio)?)$`)
+)
+
+// Operator returns the comparison operator, defaulting to ">".
+func (r RuleConfig) Operator() string {
+	if r.Op == "" {
+		return ">"
+	}
+	return r.Op
+}
+
+// SeverityOrDefault returns the rule severity, defaulting to "warning".
+func (r RuleConfig) SeverityOrDefault() string {
+	if r.Severity == "" {
+		return "warning"
+	}
+	return strings.ToLower(r.Severity)
+}
+
+// AppliesTo reports whether the rule targets the given log.
+func (r RuleConfig) AppliesTo(logID string) bool {
+	return r.LogID == "" || r.LogID == logID
+}
+
+func validateRules(rules []RuleConfig, logs []LogConfig) error {
+	ids := make(map[string]bool, len(logs))
+	for _, log := range logs {
+		ids[log.ID] = true
+	}
+
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule missing name")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.LogID != "" && !ids[rule.LogID] {
+			return fmt.Errorf("rule %s references unknown log ID: %s", rule.Name, rule.LogID)
+		}
+
+		switch {
+		case rule.Metric == "" && rule.Pattern == "":
+			return fmt.Errorf("rule %s needs a metric or a pattern", rule.Name)
+		case rule.Metric != "" && rule.Pattern != "":
+			return fmt.Errorf("rule %s cannot have both a metric and a pattern", rule.Name)
+		case rule.Metric != "" && !metricPattern.MatchString(rule.Metric):
+			return fmt.Errorf("rule %s has unknown metric: %s", rule.Name, rule.Metric)
+		case rule.Pattern != "":
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("rule %s has invalid pattern: %w", rule.Name, err)
+			}
+		}
+
+		if !contains(ruleOps, rule.Operator()) {
+			return fmt.Errorf("rule %s has unknown operator: %s", rule.Name, rule.Op)
+		}
+		if !contains(ruleSeverities, rule.SeverityOrDefault()) {
+			return fmt.Errorf("rule %s has unknown severity: %s", rule.Name, rule.Severity)
+		}
+	}
+
+	return nil
+}
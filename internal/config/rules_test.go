@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateRules(t *testing.T) {
+	logs := []LogConfig{{ID: "web", Path: "/var/log/web.log", Type: "nginx"}}
+
+	tests := []struct {
+		name        string
+		rules       []RuleConfig
+		expectError bool
+	}{
+		{
+			name: "Valid rules",
+			rules: []RuleConfig{
+				{Name: "errors", LogID: "web", Metric: "level.ERROR", Op: ">", Threshold: 50, Severity: "critical"},
+				{Name: "panics", Pattern: "panic:"},
+				{Name: "5xx", Metric: "status.5xx_ratio", Threshold: 0.02},
+			},
+			expectError: false,
+		},
+		{
+			name:        "Missing name",
+			rules:       []RuleConfig{{Metric: "lines"}},
+			expectError: true,
+		},
+		{
+			name:        "Duplicate name",
+			rules:       []RuleConfig{{Name: "a", Metric: "lines"}, {Name: "a", Metric: "bytes"}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown log ID",
+			rules:       []RuleConfig{{Name: "a", LogID: "db", Metric: "lines"}},
+			expectError: true,
+		},
+		{
+			name:        "Neither metric nor pattern",
+			rules:       []RuleConfig{{Name: "a"}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown metric",
+			rules:       []RuleConfig{{Name: "a", Metric: "latency"}},
+			expectError: true,
+		},
+		{
+			name:        "Invalid pattern",
+			rules:       []RuleConfig{{Name: "a", Pattern: "("}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown operator",
+			rules:       []RuleConfig{{Name: "a", Metric: "lines", Op: "=>"}},
+			expectError: true,
+		},
+		{
+			name:        "Unknown severity",
+			rules:       []RuleConfig{{Name: "a", Metric: "lines", Severity: "page"}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRules(tt.rules, logs)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateRules() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
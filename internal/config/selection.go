@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selection narrows the set of configured logs that get analyzed.
+// An empty Selection keeps every enabled log.
+type Selection struct {
+	Only        []string
+	Tags        []string
+	ExcludeTags []string
+}
+
+// IsEmpty reports whether the selection has no filters set.
+func (s Selection) IsEmpty() bool {
+	return len(s.Only) == 0 && len(s.Tags) == 0 && len(s.ExcludeTags) == 0
+}
+
+// Validate checks that every ID listed in Only exists in the configuration,
+// so a typo does not silently skip everything.
+func (s Selection) Validate(logs []LogConfig) error {
+	known := make(map[string]bool, len(logs))
+	for _, log := range logs {
+		known[log.ID] = true
+	}
+
+	var unknown []string
+	for _, id := range s.Only {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown log ID(s) in selection: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// SkipReason returns a human readable reason when the log is excluded by the
+// configuration or the selection, and an empty string when it should be
+// analyzed.
+func (s Selection) SkipReason(log LogConfig) string {
+	if !log.IsEnabled() {
+		return "disabled in configuration"
+	}
+
+	if len(s.Only) > 0 && !contains(s.Only, log.ID) {
+		return "not selected by --only"
+	}
+
+	if len(s.Tags) > 0 {
+		matched := false
+		for _, tag := range s.Tags {
+			if log.HasTag(tag) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Sprintf("missing tag %s", strings.Join(s.Tags, " or "))
+		}
+	}
+
+	for _, tag := range s.ExcludeTags {
+		if log.HasTag(tag) {
+			return fmt.Sprintf("excluded by tag %s", tag)
+		}
+	}
+
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
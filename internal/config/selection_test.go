@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSelectionSkipReason(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name       string
+		selection  Selection
+		log        LogConfig
+		expectSkip bool
+	}{
+		{
+			name:       "Empty selection keeps enabled log",
+			selection:  Selection{},
+			log:        LogConfig{ID: "log1"},
+			expectSkip: false,
+		},
+		{
+			name:       "Disabled log is skipped",
+			selection:  Selection{},
+			log:        LogConfig{ID: "log1", Enabled: &disabled},
+			expectSkip: true,
+		},
+		{
+			name:       "Only keeps listed log",
+			selection:  Selection{Only: []string{"log1"}},
+			log:        LogConfig{ID: "log1"},
+			expectSkip: false,
+		},
+		{
+			name:       "Only skips other logs",
+			selection:  Selection{Only: []string{"log2"}},
+			log:        LogConfig{ID: "log1"},
+			expectSkip: true,
+		},
+		{
+			name:       "Tag matches",
+			selection:  Selection{Tags: []string{"web", "db"}},
+			log:        LogConfig{ID: "log1", Tags: []string{"db"}},
+			expectSkip: false,
+		},
+		{
+			name:       "Tag missing",
+			selection:  Selection{Tags: []string{"web"}},
+			log:        LogConfig{ID: "log1", Tags: []string{"db"}},
+			expectSkip: true,
+		},
+		{
+			name:       "Excluded tag",
+			selection:  Selection{Tags: []string{"web"}, ExcludeTags: []string{"staging"}},
+			log:        LogConfig{ID: "log1", Tags: []string{"web", "staging"}},
+			expectSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := tt.selection.SkipReason(tt.log)
+			if (reason != "") != tt.expectSkip {
+				t.Errorf("SkipReason() = %q, expectSkip %v", reason, tt.expectSkip)
+			}
+		})
+	}
+}
+
+func TestSelectionValidate(t *testing.T) {
+	logs := []LogConfig{{ID: "log1"}, {ID: "log2"}}
+
+	if err := (Selection{Only: []string{"log1", "log2"}}).Validate(logs); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	if err := (Selection{Only: []string{"log1", "typo"}}).Validate(logs); err == nil {
+		t.Error("Validate() expected error for unknown log ID, got nil")
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSHConfig holds the settings used to read logs whose path is an ssh:// URL.
+// The top-level "ssh" section applies to every remote log without its own.
+type SSHConfig struct {
+	User          string `json:"user,omitempty"`
+	KeyFile       string `json:"key_file,omitempty"`
+	KnownHosts    string `json:"known_hosts,omitempty"`
+	HostKeyPolicy string `json:"host_key_policy,omitempty"`
+	Timeout       string `json:"timeout,omitempty"`
+}
+
+const (
+	// HostKeyStrict only accepts hosts listed in the known_hosts file.
+	HostKeyStrict = "strict"
+	// HostKeyAcceptNew records unknown hosts in the known_hosts file but
+	// still rejects changed keys.
+	HostKeyAcceptNew = "accept-new"
+	// HostKeyInsecure accepts any host key.
+	HostKeyInsecure = "insecure"
+
+	defaultSSHTimeout = 10 * time.Second
+	defaultSSHPort    = 22
+)
+
+var hostKeyPolicies = []string{HostKeyStrict, HostKeyAcceptNew, HostKeyInsecure}
+
+// Policy returns the host key policy, "strict" by default.
+func (s SSHConfig) Policy() string {
+	if s.HostKeyPolicy == "" {
+		return HostKeyStrict
+	}
+	return s.HostKeyPolicy
+}
+
+// KnownHostsPath returns the known_hosts file, ~/.ssh/known_hosts by default.
+func (s SSHConfig) KnownHostsPath() string {
+	if s.KnownHosts != "" {
+		return expandHome(s.KnownHosts)
+	}
+	return expandHome("~/.ssh/known_hosts")
+}
+
+// KeyFiles returns the private keys to try: the configured one, or the usual
+// default keys of the user.
+func (s SSHConfig) KeyFiles() []string {
+	if s.KeyFile != "" {
+		return []string{expandHome(s.KeyFile)}
+	}
+	return []string{
+		expandHome("~/.ssh/id_ed25519"),
+		expandHome("~/.ssh/id_ecdsa"),
+		expandHome("~/.ssh/id_rsa"),
+	}
+}
+
+// DialTimeout returns the connection timeout, 10s by default.
+func (s SSHConfig) DialTimeout() time.Duration {
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return defaultSSHTimeout
+	}
+	return d
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// SSHTarget is a remote log location parsed from an ssh:// path.
+type SSHTarget struct {
+	User string
+	Host string
+	Port int
+	Path string
+}
+
+// Addr returns the host:port to dial.
+func (t SSHTarget) Addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// IsSSHPath reports whether a log path designates a file on a remote host.
+func IsSSHPath(path string) bool {
+	return strings.HasPrefix(path, "ssh://")
+}
+
+// ParseSSHPath parses "ssh://[user@]host[:port]:/path" or the URL form
+// "ssh://[user@]host[:port]/path". An IPv6 host is written in brackets when
+// a port follows, e.g. "ssh://[::1]:22/path". The path must be absolute.
+func ParseSSHPath(path string) (SSHTarget, error) {
+	rest, ok := strings.CutPrefix(path, "ssh://")
+	if !ok {
+		return SSHTarget{}, fmt.Errorf("%q is not an ssh:// path", path)
+	}
+
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		return SSHTarget{}, fmt.Errorf("%q has no remote file path", path)
+	}
+	hostPart, remotePath := strings.TrimSuffix(rest[:slash], ":"), rest[slash:]
+	if remotePath == "/" {
+		return SSHTarget{}, fmt.Errorf("%q has no remote file path", path)
+	}
+
+	target := SSHTarget{Port: defaultSSHPort, Path: remotePath}
+	if user, host, found := strings.Cut(hostPart, "@"); found {
+		target.User, hostPart = user, host
+	}
+	if host, port, err := net.SplitHostPort(hostPart); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil || n <= 0 || n > 65535 {
+			return SSHTarget{}, fmt.Errorf("%q has invalid port %q", path, port)
+		}
+		target.Port, hostPart = n, host
+	} else {
+		// No port: the whole host, such as "web1", "[::1]" or "fe80::1".
+		hostPart = strings.TrimSuffix(strings.TrimPrefix(hostPart, "["), "]")
+	}
+	if hostPart == "" {
+		return SSHTarget{}, fmt.Errorf("%q has no host", path)
+	}
+	target.Host = hostPart
+
+	return target, nil
+}
+
+func validateSSH(s *SSHConfig, name string) error {
+	if s == nil {
+		return nil
+	}
+
+	if !contains(hostKeyPolicies, s.Policy()) {
+		return fmt.Errorf("%s has unknown host_key_policy %q (use %s)", name, s.HostKeyPolicy, strings.Join(hostKeyPolicies, ", "))
+	}
+	if s.Timeout != "" {
+		if d, err := time.ParseDuration(s.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("%s has invalid timeout %q", name, s.Timeout)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSSHPath(t *testing.T) {
+	tests := []struct {
+		path        string
+		expected    SSHTarget
+		expectError bool
+	}{
+		{path: "ssh://deploy@web1:/var/log/syslog", expected: SSHTarget{User: "deploy", Host: "web1", Port: 22, Path: "/var/log/syslog"}},
+		{path: "ssh://web1/var/log/syslog", expected: SSHTarget{Host: "web1", Port: 22, Path: "/var/log/syslog"}},
+		{path: "ssh://deploy@web1:2222/var/log/app.log", expected: SSHTarget{User: "deploy", Host: "web1", Port: 2222, Path: "/var/log/app.log"}},
+		{path: "ssh://deploy@web1:2222:/var/log/app.log", expected: SSHTarget{User: "deploy", Host: "web1", Port: 2222, Path: "/var/log/app.log"}},
+		{path: "ssh://deploy@[::1]:22/var/log/x", expected: SSHTarget{User: "deploy", Host: "::1", Port: 22, Path: "/var/log/x"}},
+		{path: "ssh://[fe80::1]:2222:/var/log/x", expected: SSHTarget{Host: "fe80::1", Port: 2222, Path: "/var/log/x"}},
+		{path: "ssh://[fe80::1]/var/log/x", expected: SSHTarget{Host: "fe80::1", Port: 22, Path: "/var/log/x"}},
+		{path: "ssh://deploy@fe80::1:/var/log/x", expected: SSHTarget{User: "deploy", Host: "fe80::1", Port: 22, Path: "/var/log/x"}},
+		{path: "ssh://deploy@web1", expectError: true},
+		{path: "ssh://deploy@:/var/log/syslog", expectError: true},
+		{path: "ssh://web1:port/var/log/syslog", expectError: true},
+		{path: "/var/log/syslog", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseSSHPath(tt.path)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseSSHPath() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseSSHPath() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+
+	if addr := (SSHTarget{Host: "::1", Port: 22}).Addr(); addr != "[::1]:22" {
+		t.Errorf("Addr() = %q, want [::1]:22", addr)
+	}
+}
+
+func TestLoadConfigSSHDefaults(t *testing.T) {
+	configContent := `{
+		"logs": [
+			{"id": "web1", "path": "ssh://deploy@web1:/var/log/syslog", "type": "syslog"},
+			{"id": "web2", "path": "ssh://web2:/var/log/syslog", "type": "syslog", "ssh": {"user": "ops", "host_key_policy": "insecure"}},
+			{"id": "local", "path": "/var/log/app.log", "type": "app"}
+		],
+		"ssh": {"key_file": "/etc/loganalyzer/id_ed25519", "host_key_policy": "accept-new"}
+	}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if ssh := cfg.Logs[0].SSH; ssh == nil || ssh.Policy() != HostKeyAcceptNew || ssh.KeyFiles()[0] != "/etc/loganalyzer/id_ed25519" {
+		t.Errorf("web1 ssh = %+v, want the top-level settings", ssh)
+	}
+	if ssh := cfg.Logs[1].SSH; ssh == nil || ssh.Policy() != HostKeyInsecure || ssh.User != "ops" {
+		t.Errorf("web2 ssh = %+v, want its own settings", ssh)
+	}
+	if cfg.Logs[2].SSH != nil {
+		t.Errorf("local log got ssh settings %+v", cfg.Logs[2].SSH)
+	}
+}
+
+func TestValidateSSH(t *testing.T) {
+	if err := validateSSH(&SSHConfig{HostKeyPolicy: "trust-me"}, "ssh"); err == nil {
+		t.Error("validateSSH() accepted an unknown host key policy")
+	}
+	if err := validateSSH(&SSHConfig{Timeout: "soon"}, "ssh"); err == nil {
+		t.Error("validateSSH() accepted an invalid timeout")
+	}
+	if err := validateSSH(&SSHConfig{HostKeyPolicy: "strict", Timeout: "5s"}, "ssh"); err != nil {
+		t.Errorf("validateSSH() error = %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// TracingConfig enables the export of a trace of every analysis, either to an
+// OTLP/HTTP endpoint or, with Exporter "stdout", to the standard output.
+type TracingConfig struct {
+	Exporter    string            `json:"exporter,omitempty"`
+	Endpoint    string            `json:"endpoint,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"service_name,omitempty"`
+}
+
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+
+	defaultTracingEndpoint = "http://localhost:4318"
+	defaultServiceName     = "loganalyzer"
+)
+
+// ExporterOrDefault returns the exporter, "otlp" by default.
+func (t TracingConfig) ExporterOrDefault() string {
+	if t.Exporter == "" {
+		return TracingExporterOTLP
+	}
+	return t.Exporter
+}
+
+// EndpointOrDefault returns the OTLP endpoint, falling back to the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variable and then to a local collector.
+func (t TracingConfig) EndpointOrDefault() string {
+	if t.Endpoint != "" {
+		return t.Endpoint
+	}
+	if env := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); env != "" {
+		return env
+	}
+	return defaultTracingEndpoint
+}
+
+// ServiceNameOrDefault returns the service.name of the exported spans.
+func (t TracingConfig) ServiceNameOrDefault() string {
+	if t.ServiceName == "" {
+		return defaultServiceName
+	}
+	return t.ServiceName
+}
+
+func validateTracing(t *TracingConfig) error {
+	if t == nil {
+		return nil
+	}
+
+	switch t.ExporterOrDefault() {
+	case TracingExporterOTLP:
+		u, err := url.Parse(t.EndpointOrDefault())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tracing has invalid endpoint %q (use e.g. http://localhost:4318)", t.EndpointOrDefault())
+		}
+	case TracingExporterStdout:
+	default:
+		return fmt.Errorf("tracing has unknown exporter %q (use otlp or stdout)", t.Exporter)
+	}
+
+	return nil
+}
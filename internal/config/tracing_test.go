@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateTracing(t *testing.T) {
+	tests := []struct {
+		name        string
+		tracing     *TracingConfig
+		expectError bool
+	}{
+		{name: "Not configured", tracing: nil, expectError: false},
+		{name: "Defaults", tracing: &TracingConfig{}, expectError: false},
+		{name: "OTLP endpoint", tracing: &TracingConfig{Endpoint: "https://otel.example.com:4318"}, expectError: false},
+		{name: "Stdout", tracing: &TracingConfig{Exporter: "stdout"}, expectError: false},
+		{name: "Invalid endpoint", tracing: &TracingConfig{Endpoint: "localhost:4318"}, expectError: true},
+		{name: "Unknown exporter", tracing: &TracingConfig{Exporter: "jaeger"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTracing(tt.tracing)
+			if (err != nil) != tt.expectError {
+				t.Errorf("validateTracing() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package correlate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+)
+
+const (
+	// DefaultMaxRequests bounds the number of distinct request IDs kept in memory.
+	DefaultMaxRequests = 100000
+	// maxMessageLength truncates the messages stored in timelines.
+	maxMessageLength = 200
+)
+
+// Event is one entry of a request timeline.
+type Event struct {
+	LogID   string    `json:"log_id"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// Request is the timeline of one request ID across the configured logs.
+// ErroredIn lists the logs with an ERROR or FATAL entry for the request and
+// OKIn the logs where every entry for the request was below ERROR.
+type Request struct {
+	ID        string   `json:"id"`
+	LogIDs    []string `json:"log_ids"`
+	ErroredIn []string `json:"errored_in,omitempty"`
+	OKIn      []string `json:"ok_in,omitempty"`
+	Events    []Event  `json:"events"`
+}
+
+// Inconsistent reports whether the request failed in one log but looked fine
+// in another.
+func (r *Request) Inconsistent() bool {
+	return len(r.ErroredIn) > 0 && len(r.OKIn) > 0
+}
+
+type extractor struct {
+	field   string
+	pattern *regexp.Regexp
+}
+
+func (x extractor) id(e entry.Entry) string {
+	if x.pattern == nil {
+		return e.Fields[x.field]
+	}
+	if m := x.pattern.FindStringSubmatch(e.Raw); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+type request struct {
+	events []Event
+	order  int
+}
+
+// Correlator joins the entries of several logs by request ID. It is safe for
+// concurrent use, so every log can be read in its own goroutine.
+type Correlator struct {
+	mu          sync.Mutex
+	extractors  map[string]extractor
+	requests    map[string]*request
+	maxRequests int
+	dropped     int
+}
+
+func NewCorrelator(cfg config.CorrelationConfig) (*Correlator, error) {
+	c := &Correlator{
+		extractors:  make(map[string]extractor, len(cfg.Fields)),
+		requests:    make(map[string]*request),
+		maxRequests: DefaultMaxRequests,
+	}
+
+	for logType, field := range cfg.Fields {
+		x := extractor{field: field.Field}
+		if field.Pattern != "" {
+			pattern, err := regexp.Compile(field.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid correlation pattern for type %q: %w", logType, err)
+			}
+			x.pattern = pattern
+		}
+		c.extractors[logType] = x
+	}
+
+	return c, nil
+}
+
+// SetMaxRequests changes the number of distinct request IDs kept in memory.
+func (c *Correlator) SetMaxRequests(n int) {
+	c.maxRequests = n
+}
+
+// Correlates reports whether entries of the given log type carry an ID.
+func (c *Correlator) Correlates(logType string) bool {
+	_, ok := c.extractors[logType]
+	return ok
+}
+
+// Add records the entry under its request ID, if it has one.
+func (c *Correlator) Add(logID, logType string, e entry.Entry) {
+	x, ok := c.extractors[logType]
+	if !ok {
+		return
+	}
+
+	id := x.id(e)
+	if id == "" || id == "-" {
+		return
+	}
+
+	message := e.Message
+	if message == "" {
+		message = e.Raw
+	}
+	if len(message) > maxMessageLength {
+		message = message[:maxMessageLength] + "…"
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.requests[id]
+	if !ok {
+		if len(c.requests) >= c.maxRequests {
+			c.dropped++
+			return
+		}
+		r = &request{order: len(c.requests)}
+		c.requests[id] = r
+	}
+
+	r.events = append(r.events, Event{
+		LogID:   logID,
+		Time:    e.Time,
+		Level:   e.Level.String(),
+		Message: message,
+	})
+}
+
+// Dropped returns the number of entries ignored because the request limit
+// was reached.
+func (c *Correlator) Dropped() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropped
+}
+
+// Request returns the timeline of a single request ID.
+func (c *Correlator) Request(id string) (*Request, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.requests[id]
+	if !ok {
+		return nil, false
+	}
+	return buildRequest(id, r), true
+}
+
+// Requests returns the requests seen in at least minLogs distinct logs,
+// ordered by the time of their first event.
+func (c *Correlator) Requests(minLogs int) []*Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var requests []*Request
+	orders := make(map[string]int)
+	for id, r := range c.requests {
+		built := buildRequest(id, r)
+		if len(built.LogIDs) >= minLogs {
+			requests = append(requests, built)
+			orders[id] = r.order
+		}
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		ti, tj := requests[i].Events[0].Time, requests[j].Events[0].Time
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return orders[requests[i].ID] < orders[requests[j].ID]
+	})
+	return requests
+}
+
+func buildRequest(id string, r *request) *Request {
+	// Events without a timestamp keep their place after the previous event
+	// of the same request.
+	events := append([]Event(nil), r.events...)
+	keys := make([]time.Time, len(events))
+	for i, e := range events {
+		keys[i] = e.Time
+		if keys[i].IsZero() && i > 0 {
+			keys[i] = keys[i-1]
+		}
+	}
+	indexes := make([]int, len(events))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return keys[indexes[i]].Before(keys[indexes[j]])
+	})
+	sorted := make([]Event, len(events))
+	for i, idx := range indexes {
+		sorted[i] = events[idx]
+	}
+	events = sorted
+
+	built := &Request{ID: id, Events: events}
+
+	errored := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if !seen[e.LogID] {
+			seen[e.LogID] = true
+			built.LogIDs = append(built.LogIDs, e.LogID)
+		}
+		if level, _ := entry.ParseLevel(e.Level); level >= entry.LevelError {
+			errored[e.LogID] = true
+		}
+	}
+
+	for _, logID := range built.LogIDs {
+		if errored[logID] {
+			built.ErroredIn = append(built.ErroredIn, logID)
+		} else {
+			built.OKIn = append(built.OKIn, logID)
+		}
+	}
+
+	return built
+}
@@ -0,0 +1,82 @@
+package correlate
+
+import (
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+)
+
+func TestCorrelator(t *testing.T) {
+	c, err := NewCorrelator(config.CorrelationConfig{
+		Fields: map[string]config.CorrelationField{
+			"nginx access": {Pattern: `req=([0-9a-f-]+)`},
+			"backend json": {Field: "request_id"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewCorrelator() error = %v", err)
+	}
+
+	base := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	nginx := func(id string, offset time.Duration, level entry.Level) entry.Entry {
+		return entry.Entry{Raw: "GET /api 200 req=" + id, Time: base.Add(offset), Level: level, Message: "GET /api"}
+	}
+	backend := func(id string, offset time.Duration, level entry.Level) entry.Entry {
+		return entry.Entry{Time: base.Add(offset), Level: level, Message: "handled", Fields: map[string]string{"request_id": id}}
+	}
+
+	c.Add("web", "nginx access", nginx("aa-1", 2*time.Second, entry.LevelInfo))
+	c.Add("api", "backend json", backend("aa-1", 1*time.Second, entry.LevelError))
+	c.Add("web", "nginx access", nginx("bb-2", 3*time.Second, entry.LevelInfo))
+	c.Add("api", "backend json", backend("bb-2", 3*time.Second, entry.LevelInfo))
+	c.Add("web", "nginx access", nginx("cc-3", 4*time.Second, entry.LevelInfo))
+	c.Add("other", "syslog", entry.Entry{Raw: "req=aa-1"})
+
+	requests := c.Requests(2)
+	if len(requests) != 2 {
+		t.Fatalf("Requests(2) returned %d requests, want 2", len(requests))
+	}
+
+	first := requests[0]
+	if first.ID != "aa-1" {
+		t.Errorf("Requests(2)[0] = %s, want aa-1", first.ID)
+	}
+	if first.Events[0].LogID != "api" || first.Events[1].LogID != "web" {
+		t.Errorf("timeline not in chronological order: %+v", first.Events)
+	}
+	if !first.Inconsistent() || first.ErroredIn[0] != "api" || first.OKIn[0] != "web" {
+		t.Errorf("request aa-1 = %+v, want errored in api and fine in web", first)
+	}
+
+	if requests[1].Inconsistent() {
+		t.Errorf("request bb-2 should be consistent: %+v", requests[1])
+	}
+
+	if single, ok := c.Request("cc-3"); !ok || len(single.LogIDs) != 1 {
+		t.Errorf("Request(cc-3) = %+v, %v", single, ok)
+	}
+	if _, ok := c.Request("zz-9"); ok {
+		t.Error("Request(zz-9) found an unknown request")
+	}
+}
+
+func TestCorrelatorLimit(t *testing.T) {
+	c, err := NewCorrelator(config.CorrelationConfig{Fields: map[string]config.CorrelationField{"json": {Field: "id"}}})
+	if err != nil {
+		t.Fatalf("NewCorrelator() error = %v", err)
+	}
+	c.SetMaxRequests(1)
+
+	c.Add("a", "json", entry.Entry{Fields: map[string]string{"id": "1"}})
+	c.Add("b", "json", entry.Entry{Fields: map[string]string{"id": "1"}})
+	c.Add("a", "json", entry.Entry{Fields: map[string]string{"id": "2"}})
+
+	if got := c.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+	if got := len(c.Requests(1)); got != 1 {
+		t.Errorf("Requests(1) returned %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,218 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"loganalyzer/internal/alert"
+	"loganalyzer/internal/reporter"
+)
+
+// Report is the comparison of two saved analysis reports.
+type Report struct {
+	Added       []string    `json:"added,omitempty"`
+	Removed     []string    `json:"removed,omitempty"`
+	Changed     []LogChange `json:"changed,omitempty"`
+	Regressions int         `json:"regressions"`
+}
+
+// LogChange describes how a log present in both reports evolved. Only the
+// levels whose count changed are listed.
+type LogChange struct {
+	LogID        string                `json:"log_id"`
+	OldStatus    string                `json:"old_status"`
+	NewStatus    string                `json:"new_status"`
+	Levels       map[string]LevelDelta `json:"levels,omitempty"`
+	OldErrorRate float64               `json:"old_error_rate"`
+	NewErrorRate float64               `json:"new_error_rate"`
+	NewTemplates []reporter.Template   `json:"new_templates,omitempty"`
+	Regression   bool                  `json:"regression"`
+}
+
+// LevelDelta is the count of a level in the old and new report.
+type LevelDelta struct {
+	Old int `json:"old"`
+	New int `json:"new"`
+}
+
+// StatusChanged reports whether the analysis status of the log changed.
+func (c LogChange) StatusChanged() bool {
+	return c.OldStatus != c.NewStatus
+}
+
+// IsEmpty reports whether nothing worth showing changed for the log.
+func (c LogChange) IsEmpty() bool {
+	return !c.StatusChanged() && len(c.Levels) == 0 && len(c.NewTemplates) == 0
+}
+
+// IsEmpty reports whether the two reports are equivalent.
+func (r *Report) IsEmpty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Compare builds the differences between an old and a new report. A log is a
+// regression when it started failing, when its error rate went up, or when
+// templates unknown to the old report appeared.
+func Compare(old, current []reporter.AnalysisResult) *Report {
+	oldByID := make(map[string]reporter.AnalysisResult, len(old))
+	for _, result := range old {
+		oldByID[result.LogID] = result
+	}
+	currentByID := make(map[string]bool, len(current))
+
+	report := &Report{}
+	for _, result := range current {
+		currentByID[result.LogID] = true
+		before, ok := oldByID[result.LogID]
+		if !ok {
+			report.Added = append(report.Added, result.LogID)
+			continue
+		}
+
+		change := compareLog(before, result)
+		if change.IsEmpty() {
+			continue
+		}
+		if change.Regression {
+			report.Regressions++
+		}
+		report.Changed = append(report.Changed, change)
+	}
+
+	for _, result := range old {
+		if !currentByID[result.LogID] {
+			report.Removed = append(report.Removed, result.LogID)
+		}
+	}
+
+	return report
+}
+
+func compareLog(old, current reporter.AnalysisResult) LogChange {
+	change := LogChange{
+		LogID:     current.LogID,
+		OldStatus: old.Status,
+		NewStatus: current.Status,
+	}
+
+	oldLevels, currentLevels := levels(old.Stats), levels(current.Stats)
+	for level := range mergeKeys(oldLevels, currentLevels) {
+		if oldLevels[level] != currentLevels[level] {
+			if change.Levels == nil {
+				change.Levels = make(map[string]LevelDelta)
+			}
+			change.Levels[level] = LevelDelta{Old: oldLevels[level], New: currentLevels[level]}
+		}
+	}
+
+	if old.Stats != nil {
+		change.OldErrorRate = alert.MetricValue(old.Stats, "error_rate")
+	}
+	if current.Stats != nil {
+		change.NewErrorRate = alert.MetricValue(current.Stats, "error_rate")
+	}
+
+	known := make(map[string]bool, len(old.Templates))
+	for _, t := range old.Templates {
+		known[t.Pattern] = true
+	}
+	for _, t := range current.Templates {
+		if !known[t.Pattern] {
+			change.NewTemplates = append(change.NewTemplates, t)
+		}
+	}
+
+	change.Regression = (current.Status == "FAILURE" && old.Status != "FAILURE") ||
+		change.NewErrorRate > change.OldErrorRate ||
+		len(change.NewTemplates) > 0
+	return change
+}
+
+func levels(stats *reporter.LogStats) map[string]int {
+	if stats == nil {
+		return nil
+	}
+	return stats.Levels
+}
+
+func mergeKeys(a, b map[string]int) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return keys
+}
+
+// Print writes the report in human-readable form. Regressions are marked with
+// "✗", other changes with "~".
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintln(w, "=== Report Diff ===")
+	if r.IsEmpty() {
+		fmt.Fprintln(w, "No differences.")
+		return
+	}
+
+	for _, id := range r.Added {
+		fmt.Fprintf(w, "+ [%s] added\n", id)
+	}
+	for _, id := range r.Removed {
+		fmt.Fprintf(w, "- [%s] removed\n", id)
+	}
+
+	for _, c := range r.Changed {
+		symbol := "~"
+		if c.Regression {
+			symbol = "✗"
+		}
+		fmt.Fprintf(w, "%s [%s]\n", symbol, c.LogID)
+
+		if c.StatusChanged() {
+			fmt.Fprintf(w, "   Status: %s → %s\n", c.OldStatus, c.NewStatus)
+		}
+		if len(c.Levels) > 0 {
+			fmt.Fprintf(w, "   Levels: %s\n", formatLevels(c.Levels))
+		}
+		if c.OldErrorRate != c.NewErrorRate {
+			fmt.Fprintf(w, "   Error rate: %.2f%% → %.2f%%\n", c.OldErrorRate*100, c.NewErrorRate*100)
+		}
+		if len(c.NewTemplates) > 0 {
+			fmt.Fprintf(w, "   New templates (%d):\n", len(c.NewTemplates))
+			for _, t := range c.NewTemplates {
+				fmt.Fprintf(w, "     %6d  %s\n", t.Count, t.Pattern)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nTotal: %d added, %d removed, %d changed, %d regressions\n",
+		len(r.Added), len(r.Removed), len(r.Changed), r.Regressions)
+}
+
+func formatLevels(levels map[string]LevelDelta) string {
+	var names []string
+	for _, name := range reporter.LevelOrder {
+		if _, ok := levels[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var other []string
+	for name := range levels {
+		if !slices.Contains(reporter.LevelOrder, name) {
+			other = append(other, name)
+		}
+	}
+	sort.Strings(other)
+	names = append(names, other...)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		d := levels[name]
+		parts = append(parts, fmt.Sprintf("%s %d → %d (%+d)", name, d.Old, d.New, d.New-d.Old))
+	}
+	return strings.Join(parts, ", ")
+}
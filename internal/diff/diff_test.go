@@ -0,0 +1,94 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"loganalyzer/internal/reporter"
+)
+
+func result(id, status string, levels map[string]int, templates ...string) reporter.AnalysisResult {
+	r := reporter.AnalysisResult{LogID: id, Status: status}
+	if levels != nil {
+		r.Stats = reporter.NewLogStats()
+		r.Stats.Levels = levels
+		for _, n := range levels {
+			r.Stats.Lines += n
+		}
+	}
+	for _, pattern := range templates {
+		r.Templates = append(r.Templates, reporter.Template{Pattern: pattern, Count: 1})
+	}
+	return r
+}
+
+func TestCompare(t *testing.T) {
+	old := []reporter.AnalysisResult{
+		result("web", "OK", map[string]int{"INFO": 90, "ERROR": 10}, "timeout after <NUM>ms"),
+		result("api", "OK", map[string]int{"INFO": 50}),
+		result("db", "OK", map[string]int{"INFO": 5}),
+		result("legacy", "OK", nil),
+	}
+	new := []reporter.AnalysisResult{
+		result("web", "OK", map[string]int{"INFO": 80, "ERROR": 20}, "timeout after <NUM>ms", "connection refused by <IP>"),
+		result("api", "FAILURE", nil),
+		result("db", "OK", map[string]int{"INFO": 5}),
+		result("cache", "OK", map[string]int{"INFO": 1}),
+	}
+
+	report := Compare(old, new)
+
+	if len(report.Added) != 1 || report.Added[0] != "cache" {
+		t.Errorf("Added = %v, want [cache]", report.Added)
+	}
+	if len(report.Removed) != 1 || report.Removed[0] != "legacy" {
+		t.Errorf("Removed = %v, want [legacy]", report.Removed)
+	}
+	if len(report.Changed) != 2 {
+		t.Fatalf("Changed = %+v, want web and api", report.Changed)
+	}
+	if report.Regressions != 2 {
+		t.Errorf("Regressions = %d, want 2", report.Regressions)
+	}
+
+	web := report.Changed[0]
+	if web.LogID != "web" || web.Levels["ERROR"] != (LevelDelta{Old: 10, New: 20}) {
+		t.Errorf("web change = %+v", web)
+	}
+	if web.OldErrorRate != 0.1 || web.NewErrorRate != 0.2 {
+		t.Errorf("web error rate = %v → %v, want 0.1 → 0.2", web.OldErrorRate, web.NewErrorRate)
+	}
+	if len(web.NewTemplates) != 1 || web.NewTemplates[0].Pattern != "connection refused by <IP>" {
+		t.Errorf("web new templates = %+v", web.NewTemplates)
+	}
+
+	api := report.Changed[1]
+	if !api.StatusChanged() || !api.Regression {
+		t.Errorf("api change = %+v, want status regression", api)
+	}
+}
+
+func TestReportPrint(t *testing.T) {
+	var buf bytes.Buffer
+	Compare(nil, nil).Print(&buf)
+	if !strings.Contains(buf.String(), "No differences.") {
+		t.Errorf("Print() of empty diff = %q", buf.String())
+	}
+
+	buf.Reset()
+	old := []reporter.AnalysisResult{result("web", "OK", map[string]int{"ERROR": 1, "INFO": 9})}
+	new := []reporter.AnalysisResult{result("web", "OK", map[string]int{"ERROR": 3, "INFO": 7})}
+	Compare(old, new).Print(&buf)
+
+	for _, want := range []string{
+		"✗ [web]",
+		"Levels: ERROR 1 → 3 (+2), INFO 9 → 7 (-2)",
+		"Error rate: 10.00% → 30.00%",
+		"0 added, 0 removed, 1 changed, 1 regressions",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Print() output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package entry
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accessPattern matches the common and combined log formats used by nginx and
+// Apache, optionally followed by extra fields such as $request_time.
+var accessPattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?(.*)$`)
+
+const accessTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessParser parses nginx/Apache access logs. The level is derived from the
+// HTTP status: 5xx is ERROR, 4xx is WARN and everything else INFO.
+type AccessParser struct{}
+
+func (p *AccessParser) Parse(line string) (Entry, error) {
+	m := accessPattern.FindStringSubmatch(line)
+	if m == nil {
+		return fallback(line), fmt.Errorf("line does not match access log format")
+	}
+
+	fields := map[string]string{
+		"remote_addr": m[1],
+		"user":        m[2],
+		"request":     m[4],
+		"status":      m[5],
+		"bytes":       m[6],
+	}
+	if method, rest, ok := strings.Cut(m[4], " "); ok {
+		fields["method"] = method
+		path, protocol, _ := strings.Cut(rest, " ")
+		fields["path"] = path
+		fields["protocol"] = protocol
+	}
+	if m[7] != "" || m[8] != "" {
+		fields["referer"] = m[7]
+		fields["user_agent"] = m[8]
+	}
+	if extra := strings.Fields(m[9]); len(extra) > 0 {
+		if _, err := strconv.ParseFloat(extra[0], 64); err == nil {
+			fields["request_time"] = extra[0]
+		}
+	}
+
+	e := Entry{
+		Raw:     line,
+		Level:   statusLevel(m[5]),
+		Message: m[4],
+		Fields:  fields,
+	}
+	if ts, err := time.Parse(accessTimeLayout, m[3]); err == nil {
+		e.Time = ts
+	}
+
+	return e, nil
+}
+
+func statusLevel(status string) Level {
+	switch {
+	case strings.HasPrefix(status, "5"):
+		return LevelError
+	case strings.HasPrefix(status, "4"):
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
+}
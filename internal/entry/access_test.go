@@ -0,0 +1,42 @@
+package entry
+
+import (
+	"testing"
+)
+
+func TestAccessParser(t *testing.T) {
+	p := &AccessParser{}
+
+	line := `192.168.1.1 - frank [01/Jan/2024:10:00:00 +0000] "GET /api/users?id=1 HTTP/1.1" 503 1234 "-" "Mozilla/5.0" 0.250`
+	e, err := p.Parse(line)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"remote_addr":  "192.168.1.1",
+		"user":         "frank",
+		"method":       "GET",
+		"path":         "/api/users?id=1",
+		"status":       "503",
+		"bytes":        "1234",
+		"user_agent":   "Mozilla/5.0",
+		"request_time": "0.250",
+	}
+	for key, want := range expected {
+		if got := e.Fields[key]; got != want {
+			t.Errorf("Parse() field %s = %q, want %q", key, got, want)
+		}
+	}
+
+	if e.Level != LevelError {
+		t.Errorf("Parse() level = %v, want ERROR", e.Level)
+	}
+	if e.Time.IsZero() || e.Time.Hour() != 10 {
+		t.Errorf("Parse() time = %v, want 10:00 UTC", e.Time)
+	}
+
+	if _, err := p.Parse("not an access log line"); err == nil {
+		t.Error("Parse() expected error for malformed line, got nil")
+	}
+}
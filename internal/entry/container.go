@@ -0,0 +1,248 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DockerParser parses the json-file logs of Docker, one JSON object per line
+// such as {"log":"text\n","stream":"stderr","time":"2024-05-24T10:00:00.1Z"}.
+// Lines longer than 16KiB are written by Docker in several objects, all but
+// the last without the trailing newline; SplitRecords joins them into one
+// record.
+//
+// The entry message is the reassembled line, also used as Raw, and its time
+// the one recorded by the runtime. A line that is itself a JSON object is
+// unwrapped like a JSONParser line: its fields are added to the entry and its
+// level and message are used. The "stream" field is stdout or stderr, the
+// "attrs" of the log driver are kept as "attrs.NAME" fields, and Metadata,
+// usually found with ContainerMetadata, is added to every entry.
+type DockerParser struct {
+	Location *time.Location
+	Metadata map[string]string
+}
+
+// dockerLine is a line of a json-file log.
+type dockerLine struct {
+	Log    string            `json:"log"`
+	Stream string            `json:"stream"`
+	Time   string            `json:"time"`
+	Attrs  map[string]string `json:"attrs"`
+}
+
+func (p *DockerParser) Parse(record string) (Entry, error) {
+	var parts []containerLine
+	for _, line := range strings.Split(record, "\n") {
+		part, err := parseDockerLine([]byte(line))
+		if err != nil {
+			return fallback(record), err
+		}
+		parts = append(parts, part)
+	}
+	return containerEntry(parts, p.Location, p.Metadata), nil
+}
+
+// SplitRecords joins the lines Docker split in several objects.
+func (p *DockerParser) SplitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	return splitPartialLines(data, atEOF, func(line []byte) (string, bool) {
+		part, err := parseDockerLine(line)
+		return part.stream, err == nil && part.partial
+	})
+}
+
+func parseDockerLine(line []byte) (containerLine, error) {
+	var l dockerLine
+	if err := json.Unmarshal(line, &l); err != nil {
+		return containerLine{}, fmt.Errorf("invalid docker log line: %w", err)
+	}
+	ts, err := time.Parse(time.RFC3339Nano, l.Time)
+	if err != nil {
+		return containerLine{}, fmt.Errorf("invalid docker log time %q", l.Time)
+	}
+	return containerLine{
+		text:    l.Log,
+		stream:  l.Stream,
+		time:    ts,
+		partial: !strings.HasSuffix(l.Log, "\n"),
+		attrs:   l.Attrs,
+	}, nil
+}
+
+// CRIParser parses the logs written by CRI runtimes such as containerd and
+// CRI-O for Kubernetes, "TIME STREAM TAG MESSAGE" lines where TAG is P for
+// the parts of a split line and F for the final one. SplitRecords joins the
+// parts into one record. Entries are built like those of DockerParser.
+type CRIParser struct {
+	Location *time.Location
+	Metadata map[string]string
+}
+
+func (p *CRIParser) Parse(record string) (Entry, error) {
+	var parts []containerLine
+	for _, line := range strings.Split(record, "\n") {
+		part, err := parseCRILine(line)
+		if err != nil {
+			return fallback(record), err
+		}
+		parts = append(parts, part)
+	}
+	return containerEntry(parts, p.Location, p.Metadata), nil
+}
+
+// SplitRecords joins the parts of the lines the runtime split.
+func (p *CRIParser) SplitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	return splitPartialLines(data, atEOF, func(line []byte) (string, bool) {
+		part, err := parseCRILine(string(line))
+		return part.stream, err == nil && part.partial
+	})
+}
+
+func parseCRILine(line string) (containerLine, error) {
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 3 {
+		return containerLine{}, fmt.Errorf("invalid CRI log line")
+	}
+	ts, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		return containerLine{}, fmt.Errorf("invalid CRI log time %q", fields[0])
+	}
+	// The tag is a list of ':' separated flags; only the first is defined.
+	tag, _, _ := strings.Cut(fields[2], ":")
+	if tag != "P" && tag != "F" {
+		return containerLine{}, fmt.Errorf("invalid CRI log tag %q", fields[2])
+	}
+
+	part := containerLine{stream: fields[1], time: ts, partial: tag == "P"}
+	if len(fields) == 4 {
+		part.text = fields[3]
+	}
+	return part, nil
+}
+
+// containerLine is a line, or part of a line, of a container log.
+type containerLine struct {
+	text    string
+	stream  string
+	time    time.Time
+	partial bool
+	attrs   map[string]string
+}
+
+// splitPartialLines returns a record per line, where a line marked partial
+// is joined with the following lines of its stream up to the first complete
+// one. A line of another stream ends the record early, leaving the partial
+// line as it is.
+func splitPartialLines(data []byte, atEOF bool, partial func(line []byte) (stream string, partial bool)) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	end := 0
+	first := true
+	var stream string
+	for {
+		i := bytes.IndexByte(data[end:], '\n')
+		if i < 0 {
+			if !atEOF {
+				return 0, nil, nil
+			}
+			if end == len(data) {
+				return end, data[:end-1], nil
+			}
+			return len(data), data, nil
+		}
+		line := data[end : end+i]
+
+		lineStream, more := partial(line)
+		if first {
+			stream, first = lineStream, false
+		} else if lineStream != stream {
+			return end, data[:end-1], nil
+		}
+		end += i + 1
+		if !more {
+			return end, data[:end-1], nil
+		}
+	}
+}
+
+// containerEntry builds the entry of a reassembled line.
+func containerEntry(parts []containerLine, loc *time.Location, metadata map[string]string) Entry {
+	var text strings.Builder
+	for _, part := range parts {
+		text.WriteString(part.text)
+	}
+	message := strings.TrimSuffix(strings.TrimSuffix(text.String(), "\n"), "\r")
+
+	fields := make(map[string]string)
+	e := Entry{
+		Raw:     message,
+		Time:    parts[0].time,
+		Message: message,
+		Fields:  fields,
+	}
+
+	if inner, ok := innerJSON(message, loc); ok {
+		for name, value := range inner.Fields {
+			fields[name] = value
+		}
+		e.Level = inner.Level
+		if inner.Message != "" {
+			e.Message = inner.Message
+		}
+	} else {
+		e.Level = DetectLevel(message)
+	}
+
+	for name, value := range parts[0].attrs {
+		fields["attrs."+name] = value
+	}
+	for name, value := range metadata {
+		fields[name] = value
+	}
+	fields["stream"] = parts[0].stream
+
+	return e
+}
+
+// innerJSON parses a message that is a JSON object.
+func innerJSON(message string, loc *time.Location) (Entry, bool) {
+	if !strings.HasPrefix(message, "{") {
+		return Entry{}, false
+	}
+	inner, err := (&JSONParser{Location: loc}).Parse(message)
+	return inner, err == nil
+}
+
+var (
+	// /var/lib/docker/containers/ID/ID-json.log
+	dockerLogPath = regexp.MustCompile(`/containers/([0-9a-f]{64})/[0-9a-f]{64}-json\.log(?:\.\d+)?$`)
+	// /var/log/containers/POD_NAMESPACE_CONTAINER-ID.log
+	kubeContainerLogPath = regexp.MustCompile(`/containers/([^/_]+)_([^/_]+)_([^/]+)-([0-9a-f]{64})\.log$`)
+	// /var/log/pods/NAMESPACE_POD_UID/CONTAINER/N.log
+	kubePodLogPath = regexp.MustCompile(`/pods/([^/_]+)_([^/_]+)_([^/]+)/([^/]+)/\d+\.log(?:\.\S+)?$`)
+)
+
+// ContainerMetadata returns the fields found in the path of a container log
+// at the standard locations of Docker and Kubernetes: "container_id" for
+// Docker, and "namespace", "pod", "container" and "container_id" or "pod_uid"
+// for Kubernetes. It returns nil for other paths.
+func ContainerMetadata(logPath string) map[string]string {
+	logPath = path.Clean(strings.ReplaceAll(logPath, "\\", "/"))
+
+	if m := dockerLogPath.FindStringSubmatch(logPath); m != nil {
+		return map[string]string{"container_id": m[1]}
+	}
+	if m := kubeContainerLogPath.FindStringSubmatch(logPath); m != nil {
+		return map[string]string{"pod": m[1], "namespace": m[2], "container": m[3], "container_id": m[4]}
+	}
+	if m := kubePodLogPath.FindStringSubmatch(logPath); m != nil {
+		return map[string]string{"namespace": m[1], "pod": m[2], "pod_uid": m[3], "container": m[4]}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package entry
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scanRecords splits input with the parser and parses every record.
+func scanRecords(t *testing.T, p interface {
+	Parser
+	RecordSplitter
+}, input string) []Entry {
+	t.Helper()
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	// A tiny buffer makes the splitter ask for more data mid-record.
+	scanner.Buffer(make([]byte, 0, 4), 4096)
+	scanner.Split(p.SplitRecords)
+
+	var entries []Entry
+	for scanner.Scan() {
+		e, err := p.Parse(scanner.Text())
+		if err != nil {
+			t.Fatalf("Parse(%q) unexpected error: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	return entries
+}
+
+func TestDockerParser(t *testing.T) {
+	input := `{"log":"GET /health 200\n","stream":"stdout","time":"2024-05-24T10:00:00.123456789Z"}` + "\n" +
+		`{"log":"ERROR a very ","stream":"stderr","time":"2024-05-24T10:00:01Z"}` + "\n" +
+		`{"log":"long line\n","stream":"stderr","time":"2024-05-24T10:00:01.5Z"}` + "\n" +
+		`{"log":"{\"level\":\"warn\",\"msg\":\"slow query\",\"ms\":812}\n","stream":"stdout","time":"2024-05-24T10:00:02Z","attrs":{"tag":"db"}}` + "\n" +
+		`{"log":"cut ","stream":"stdout","time":"2024-05-24T10:00:03Z"}` + "\n" +
+		`{"log":"other stream\n","stream":"stderr","time":"2024-05-24T10:00:03Z"}` + "\n"
+
+	p := &DockerParser{Metadata: map[string]string{"container_id": "abc"}}
+	entries := scanRecords(t, p, input)
+
+	tests := []struct {
+		message string
+		raw     string
+		level   Level
+		stream  string
+		time    time.Time
+	}{
+		{"GET /health 200", "GET /health 200", LevelUnknown, "stdout", time.Date(2024, 5, 24, 10, 0, 0, 123456789, time.UTC)},
+		{"ERROR a very long line", "ERROR a very long line", LevelError, "stderr", time.Date(2024, 5, 24, 10, 0, 1, 0, time.UTC)},
+		{"slow query", `{"level":"warn","msg":"slow query","ms":812}`, LevelWarn, "stdout", time.Date(2024, 5, 24, 10, 0, 2, 0, time.UTC)},
+		{"cut ", "cut ", LevelUnknown, "stdout", time.Date(2024, 5, 24, 10, 0, 3, 0, time.UTC)},
+		{"other stream", "other stream", LevelUnknown, "stderr", time.Date(2024, 5, 24, 10, 0, 3, 0, time.UTC)},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		e := entries[i]
+		if e.Message != tt.message || e.Raw != tt.raw {
+			t.Errorf("entry %d message = %q, raw = %q, want %q and %q", i, e.Message, e.Raw, tt.message, tt.raw)
+		}
+		if e.Level != tt.level {
+			t.Errorf("entry %d level = %v, want %v", i, e.Level, tt.level)
+		}
+		if e.Fields["stream"] != tt.stream || e.Fields["container_id"] != "abc" {
+			t.Errorf("entry %d stream = %q, container_id = %q", i, e.Fields["stream"], e.Fields["container_id"])
+		}
+		if !e.Time.Equal(tt.time) {
+			t.Errorf("entry %d time = %v, want %v", i, e.Time, tt.time)
+		}
+	}
+	if entries[2].Fields["ms"] != "812" || entries[2].Fields["attrs.tag"] != "db" {
+		t.Errorf("inner JSON fields = %v", entries[2].Fields)
+	}
+
+	if _, err := p.Parse("plain text"); err == nil {
+		t.Error("Parse() expected error for non-JSON line, got nil")
+	}
+	if _, err := p.Parse(`{"log":"x\n","stream":"stdout"}`); err == nil {
+		t.Error("Parse() expected error for a line without time, got nil")
+	}
+}
+
+func TestCRIParser(t *testing.T) {
+	input := "2024-05-24T10:00:00.000000001Z stdout F starting server\n" +
+		"2024-05-24T10:00:01Z stderr P panic: runtime \n" +
+		"2024-05-24T10:00:01Z stderr P error: index \n" +
+		"2024-05-24T10:00:01Z stderr F out of range\n" +
+		`2024-05-24T10:00:02+02:00 stdout F {"severity":"ERROR","message":"payment failed"}` + "\n" +
+		"2024-05-24T10:00:03Z stdout F\n" +
+		"2024-05-24T10:00:04Z stdout P unterminated"
+
+	p := &CRIParser{}
+	entries := scanRecords(t, p, input)
+
+	tests := []struct {
+		message string
+		level   Level
+		stream  string
+	}{
+		{"starting server", LevelUnknown, "stdout"},
+		{"panic: runtime error: index out of range", LevelFatal, "stderr"},
+		{"payment failed", LevelError, "stdout"},
+		{"", LevelUnknown, "stdout"},
+		{"unterminated", LevelUnknown, "stdout"},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(tests))
+	}
+	for i, tt := range tests {
+		e := entries[i]
+		if e.Message != tt.message || e.Level != tt.level || e.Fields["stream"] != tt.stream {
+			t.Errorf("entry %d = %q %v %q, want %q %v %q", i, e.Message, e.Level, e.Fields["stream"], tt.message, tt.level, tt.stream)
+		}
+	}
+	if want := time.Date(2024, 5, 24, 8, 0, 2, 0, time.UTC); !entries[2].Time.Equal(want) {
+		t.Errorf("entry time = %v, want %v", entries[2].Time, want)
+	}
+
+	for _, line := range []string{"not a cri line", "2024-05-24T10:00:00Z stdout X text", "yesterday stdout F text"} {
+		if _, err := p.Parse(line); err == nil {
+			t.Errorf("Parse(%q) expected error, got nil", line)
+		}
+	}
+}
+
+func TestContainerMetadata(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		path string
+		want map[string]string
+	}{
+		{
+			path: "/var/lib/docker/containers/" + id + "/" + id + "-json.log",
+			want: map[string]string{"container_id": id},
+		},
+		{
+			path: "ssh://node1/var/log/containers/api-7d9f_shop_server-" + id + ".log",
+			want: map[string]string{"pod": "api-7d9f", "namespace": "shop", "container": "server", "container_id": id},
+		},
+		{
+			path: "/var/log/pods/shop_api-7d9f_0f4c2b1e-1111-2222-3333-444455556666/server/0.log",
+			want: map[string]string{"namespace": "shop", "pod": "api-7d9f", "pod_uid": "0f4c2b1e-1111-2222-3333-444455556666", "container": "server"},
+		},
+		{path: "/var/log/app.log", want: nil},
+	}
+
+	for _, tt := range tests {
+		got := ContainerMetadata(tt.path)
+		if len(got) != len(tt.want) {
+			t.Errorf("ContainerMetadata(%q) = %v, want %v", tt.path, got, tt.want)
+			continue
+		}
+		for key, value := range tt.want {
+			if got[key] != value {
+				t.Errorf("ContainerMetadata(%q)[%s] = %q, want %q", tt.path, key, got[key], value)
+			}
+		}
+	}
+
+	p := ForLog("docker", "/var/lib/docker/containers/"+id+"/"+id+"-json.log", nil)
+	if docker, ok := p.(*DockerParser); !ok || docker.Metadata["container_id"] != id {
+		t.Errorf("ForLog() = %#v, want a DockerParser with the container ID", p)
+	}
+}
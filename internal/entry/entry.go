@@ -0,0 +1,121 @@
+package entry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Level is the normalized severity of a log entry. Levels are ordered so they
+// can be compared, from LevelUnknown (lowest) to LevelFatal (highest).
+type Level int
+
+const (
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = map[Level]string{
+	LevelUnknown: "UNKNOWN",
+	LevelDebug:   "DEBUG",
+	LevelInfo:    "INFO",
+	LevelWarn:    "WARN",
+	LevelError:   "ERROR",
+	LevelFatal:   "FATAL",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return levelNames[LevelUnknown]
+}
+
+// Levels returns every level from lowest to highest.
+func Levels() []Level {
+	return []Level{LevelUnknown, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+}
+
+// ParseLevel maps the common spellings of a severity (case-insensitive) onto
+// a Level.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace", "debug", "dbg":
+		return LevelDebug, nil
+	case "info", "information", "informational", "notice":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error", "err":
+		return LevelError, nil
+	case "fatal", "panic", "crit", "critical", "alert", "emerg", "emergency":
+		return LevelFatal, nil
+	case "unknown":
+		return LevelUnknown, nil
+	}
+	return LevelUnknown, fmt.Errorf("unknown level %q", s)
+}
+
+// Entry is a single parsed log record, independent of the source format.
+type Entry struct {
+	Raw     string
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  map[string]string
+}
+
+// Parser turns a raw line into an Entry. When the line does not match the
+// expected format, Parse returns an error together with a best-effort Entry
+// built from the raw text so the line can still be counted.
+type Parser interface {
+	Parse(line string) (Entry, error)
+}
+
+// RecordSplitter is implemented by the parsers of formats whose records can
+// span several lines. The input is then split with SplitRecords, a
+// bufio.SplitFunc, and each record is passed to Parse whole.
+type RecordSplitter interface {
+	SplitRecords(data []byte, atEOF bool) (advance int, token []byte, err error)
+}
+
+var (
+	upperLevelPattern = regexp.MustCompile(`\b(FATAL|PANIC|CRITICAL|CRIT|ERROR|ERR|WARNING|WARN|INFO|NOTICE|DEBUG|TRACE)\b`)
+	keyedLevelPattern = regexp.MustCompile(`(?i)(?:\[|<|level=|lvl=|severity=)"?(fatal|panic|critical|crit|error|err|warning|warn|info|notice|debug|trace)\b`)
+)
+
+// DetectLevel guesses the level of free-form text from well-known markers such
+// as "ERROR", "[warn]" or "level=info". It returns LevelUnknown when none is
+// found.
+func DetectLevel(text string) Level {
+	if strings.HasPrefix(text, "panic: ") {
+		return LevelFatal
+	}
+
+	if m := keyedLevelPattern.FindStringSubmatch(text); m != nil {
+		level, _ := ParseLevel(m[1])
+		return level
+	}
+
+	if m := upperLevelPattern.FindStringSubmatch(text); m != nil {
+		level, _ := ParseLevel(m[1])
+		return level
+	}
+
+	return LevelUnknown
+}
+
+// fallback builds the Entry returned alongside a parse error.
+func fallback(line string) Entry {
+	return Entry{
+		Raw:     line,
+		Level:   DetectLevel(line),
+		Message: line,
+		Fields:  map[string]string{},
+	}
+}
@@ -0,0 +1,131 @@
+package entry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Level
+		expectError bool
+	}{
+		{input: "ERROR", expected: LevelError},
+		{input: "warning", expected: LevelWarn},
+		{input: "Info", expected: LevelInfo},
+		{input: "trace", expected: LevelDebug},
+		{input: "crit", expected: LevelFatal},
+		{input: "verbose", expected: LevelUnknown, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLevel(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Errorf("ParseLevel(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Level
+	}{
+		{line: "2024-01-01 12:00:00 ERROR database unreachable", expected: LevelError},
+		{line: "2024/01/01 12:00:00 [warn] 123#0: upstream timed out", expected: LevelWarn},
+		{line: `time=2024-01-01 level=debug msg="cache hit"`, expected: LevelDebug},
+		{line: "panic: runtime error: index out of range", expected: LevelFatal},
+		{line: "no error markers here", expected: LevelUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := DetectLevel(tt.line); got != tt.expected {
+				t.Errorf("DetectLevel(%q) = %v, want %v", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextParser(t *testing.T) {
+	e, err := (&TextParser{}).Parse("2024-03-05 10:11:12,345 ERROR payment failed")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if e.Level != LevelError {
+		t.Errorf("Parse() level = %v, want ERROR", e.Level)
+	}
+	if e.Time.IsZero() || e.Time.Hour() != 10 || e.Time.Minute() != 11 {
+		t.Errorf("Parse() time = %v, want 10:11", e.Time)
+	}
+	if e.Message != "ERROR payment failed" {
+		t.Errorf("Parse() message = %q", e.Message)
+	}
+}
+
+func TestTextParserLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	e, _ := (&TextParser{Location: loc}).Parse("2024-03-05 10:11:12 INFO started")
+
+	want := time.Date(2024, 3, 5, 8, 11, 12, 0, time.UTC)
+	if !e.Time.Equal(want) {
+		t.Errorf("Parse() time = %v, want %v", e.Time, want)
+	}
+
+	e, _ = ForTypeIn("custom application", loc).Parse("2024-03-05T10:11:12Z INFO started")
+	if !e.Time.Equal(want.Add(2 * time.Hour)) {
+		t.Errorf("Parse() with explicit zone = %v, want %v", e.Time, want.Add(2*time.Hour))
+	}
+}
+
+func TestForType(t *testing.T) {
+	tests := []struct {
+		logType  string
+		expected Parser
+	}{
+		{logType: "nginx access", expected: &AccessParser{}},
+		{logType: "apache", expected: &AccessParser{}},
+		{logType: "nginx error", expected: &TextParser{}},
+		{logType: "jsonl", expected: &JSONParser{}},
+		{logType: "system log", expected: &SyslogParser{}},
+		{logType: "custom application", expected: &TextParser{}},
+		{logType: "docker json-file", expected: &DockerParser{}},
+		{logType: "containerd", expected: &CRIParser{}},
+		{logType: "k8s cri", expected: &CRIParser{}},
+		{logType: "critical app", expected: &TextParser{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.logType, func(t *testing.T) {
+			got := ForType(tt.logType)
+			if gotType, wantType := typeName(got), typeName(tt.expected); gotType != wantType {
+				t.Errorf("ForType(%q) = %s, want %s", tt.logType, gotType, wantType)
+			}
+		})
+	}
+}
+
+func typeName(p Parser) string {
+	switch p.(type) {
+	case *AccessParser:
+		return "access"
+	case *JSONParser:
+		return "json"
+	case *SyslogParser:
+		return "syslog"
+	case *TextParser:
+		return "text"
+	case *DockerParser:
+		return "docker"
+	case *CRIParser:
+		return "cri"
+	}
+	return "unknown"
+}
@@ -0,0 +1,221 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxJournalField bounds the size announced by a binary field of the export
+// format, so a corrupted size cannot make the reader wait for gigabytes.
+const maxJournalField = 1 << 24
+
+// JournalParser parses systemd journal records written by `journalctl -o
+// export` or `journalctl -o json`; both can be mixed in one input. Binary
+// fields, which the export format writes with a length prefix and the JSON
+// format as an array of bytes, are decoded as-is.
+//
+// Every journal field is kept under its own name, and the common ones are
+// mapped like syslog entries: MESSAGE is the message, PRIORITY the level and
+// "severity", __REALTIME_TIMESTAMP the time, and _HOSTNAME, _SYSTEMD_UNIT,
+// SYSLOG_IDENTIFIER and _PID the "hostname", "unit", "app" and "pid" fields.
+// Raw is the record in the short "hostname app[pid]: message" form.
+type JournalParser struct{}
+
+func (p *JournalParser) Parse(record string) (Entry, error) {
+	var fields map[string]string
+	var err error
+	if strings.HasPrefix(record, "{") {
+		fields, err = parseJournalJSON(record)
+	} else {
+		fields, err = parseJournalExport(record)
+	}
+	if err != nil {
+		return fallback(record), err
+	}
+	return journalEntry(fields), nil
+}
+
+// SplitRecords splits the input into records: a JSON object per line, or
+// export records terminated by an empty line.
+func (p *JournalParser) SplitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	start := 0
+	for start < len(data) && data[start] == '\n' {
+		start++
+	}
+	rec := data[start:]
+	if len(rec) == 0 {
+		return len(data), nil, nil
+	}
+
+	if rec[0] == '{' {
+		if i := bytes.IndexByte(rec, '\n'); i >= 0 {
+			return start + i + 1, rec[:i], nil
+		}
+		if atEOF {
+			return len(data), rec, nil
+		}
+		return start, nil, nil
+	}
+
+	pos := 0
+	for {
+		i := bytes.IndexByte(rec[pos:], '\n')
+		if i < 0 {
+			break
+		}
+		line := rec[pos : pos+i]
+		if len(line) == 0 {
+			return start + pos + 1, rec[:pos], nil
+		}
+		if bytes.IndexByte(line, '=') >= 0 {
+			pos += i + 1
+			continue
+		}
+
+		// Binary field: NAME\n, little-endian uint64 size, data, \n.
+		sizeAt := pos + i + 1
+		if len(rec) < sizeAt+8 {
+			break
+		}
+		size := binary.LittleEndian.Uint64(rec[sizeAt:])
+		if size > maxJournalField {
+			return 0, nil, fmt.Errorf("journal field %s announces %d bytes", line, size)
+		}
+		end := sizeAt + 8 + int(size) + 1
+		if len(rec) < end {
+			break
+		}
+		pos = end
+	}
+
+	if atEOF {
+		return len(data), rec, nil
+	}
+	return start, nil, nil
+}
+
+// parseJournalExport parses a record of the export format.
+func parseJournalExport(record string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for len(record) > 0 {
+		line, rest, _ := strings.Cut(record, "\n")
+		if line == "" {
+			record = rest
+			continue
+		}
+		if name, value, found := strings.Cut(line, "="); found {
+			fields[name] = value
+			record = rest
+			continue
+		}
+
+		if len(rest) < 8 {
+			return nil, fmt.Errorf("binary journal field %s is truncated", line)
+		}
+		size := binary.LittleEndian.Uint64([]byte(rest[:8]))
+		if size > uint64(len(rest)-8) {
+			return nil, fmt.Errorf("binary journal field %s is truncated", line)
+		}
+		fields[line] = rest[8 : 8+size]
+		record = strings.TrimPrefix(rest[8+size:], "\n")
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty journal record")
+	}
+	return fields, nil
+}
+
+// parseJournalJSON parses a record of the JSON format. Fields repeated in the
+// record, which journalctl writes as arrays of values, keep their first value.
+func parseJournalJSON(record string) (map[string]string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(record), &obj); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	fields := make(map[string]string, len(obj))
+	for name, raw := range obj {
+		value, err := journalJSONValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid journal field %s: %w", name, err)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// journalJSONValue decodes a field value: a string, null for a field too
+// large to be exported, an array of bytes for binary data, or an array of
+// such values for a repeated field.
+func journalJSONValue(raw json.RawMessage) (string, error) {
+	var s *string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if s == nil {
+			return "", nil
+		}
+		return *s, nil
+	}
+
+	var data []byte
+	var numbers []int
+	if err := json.Unmarshal(raw, &numbers); err == nil {
+		for _, n := range numbers {
+			if n < 0 || n > 255 {
+				return "", fmt.Errorf("byte value %d out of range", n)
+			}
+			data = append(data, byte(n))
+		}
+		return string(data), nil
+	}
+
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	return journalJSONValue(values[0])
+}
+
+func journalEntry(fields map[string]string) Entry {
+	e := Entry{
+		Message: fields["MESSAGE"],
+		Fields:  fields,
+	}
+
+	if priority, err := strconv.Atoi(fields["PRIORITY"]); err == nil && priority >= 0 && priority <= 7 {
+		e.Level = SeverityLevel(priority)
+		fields["severity"] = syslogSeverityNames[priority]
+	} else {
+		e.Level = DetectLevel(e.Message)
+	}
+
+	if usec, err := strconv.ParseInt(fields["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		e.Time = time.UnixMicro(usec)
+	}
+
+	fields["hostname"] = fields["_HOSTNAME"]
+	fields["unit"] = firstField(fields, []string{"_SYSTEMD_UNIT", "_SYSTEMD_USER_UNIT"})
+	fields["app"] = firstField(fields, []string{"SYSLOG_IDENTIFIER", "_COMM"})
+	fields["pid"] = firstField(fields, []string{"_PID", "SYSLOG_PID"})
+
+	var raw strings.Builder
+	if host := fields["hostname"]; host != "" {
+		raw.WriteString(host + " ")
+	}
+	raw.WriteString(fields["app"])
+	if pid := fields["pid"]; pid != "" {
+		raw.WriteString("[" + pid + "]")
+	}
+	raw.WriteString(": " + e.Message)
+	e.Raw = raw.String()
+
+	return e
+}
@@ -0,0 +1,155 @@
+package entry
+
+import (
+	"bufio"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+// binaryField encodes a field the way `journalctl -o export` writes values
+// that are not printable text.
+func binaryField(name, value string) string {
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(value)))
+	return name + "\n" + string(size) + value + "\n"
+}
+
+func TestJournalParser(t *testing.T) {
+	p := &JournalParser{}
+
+	tests := []struct {
+		name    string
+		record  string
+		level   Level
+		unit    string
+		host    string
+		message string
+		raw     string
+		time    time.Time
+	}{
+		{
+			name: "Export",
+			record: "__REALTIME_TIMESTAMP=1716544800123456\n" +
+				"PRIORITY=3\n" +
+				"_HOSTNAME=web1\n" +
+				"_SYSTEMD_UNIT=nginx.service\n" +
+				"SYSLOG_IDENTIFIER=nginx\n" +
+				"_PID=812\n" +
+				"MESSAGE=upstream timed out\n",
+			level:   LevelError,
+			unit:    "nginx.service",
+			host:    "web1",
+			message: "upstream timed out",
+			raw:     "web1 nginx[812]: upstream timed out",
+			time:    time.UnixMicro(1716544800123456),
+		},
+		{
+			name: "Export with binary message",
+			record: "PRIORITY=4\n" +
+				"_SYSTEMD_UNIT=app.service\n" +
+				binaryField("MESSAGE", "line one\nline two\x00") +
+				"_COMM=app\n",
+			level:   LevelWarn,
+			unit:    "app.service",
+			message: "line one\nline two\x00",
+			raw:     "app: line one\nline two\x00",
+		},
+		{
+			name:    "JSON",
+			record:  `{"__REALTIME_TIMESTAMP":"1716544800000000","PRIORITY":"6","_HOSTNAME":"db1","_SYSTEMD_UNIT":"postgresql.service","SYSLOG_IDENTIFIER":"postgres","_PID":"77","MESSAGE":"checkpoint complete"}`,
+			level:   LevelInfo,
+			unit:    "postgresql.service",
+			host:    "db1",
+			message: "checkpoint complete",
+			raw:     "db1 postgres[77]: checkpoint complete",
+			time:    time.UnixMicro(1716544800000000),
+		},
+		{
+			name:    "JSON with binary and repeated fields",
+			record:  `{"PRIORITY":"2","MESSAGE":[107,101,114,110,101,108,10,112,97,110,105,99],"_SYSTEMD_UNIT":["a.service","b.service"],"_COMM":null}`,
+			level:   LevelFatal,
+			unit:    "a.service",
+			message: "kernel\npanic",
+			raw:     ": kernel\npanic",
+		},
+		{
+			name:    "Without priority",
+			record:  `{"MESSAGE":"ERROR disk full","SYSLOG_IDENTIFIER":"backup"}`,
+			level:   LevelError,
+			message: "ERROR disk full",
+			raw:     "backup: ERROR disk full",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := p.Parse(tt.record)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if e.Level != tt.level {
+				t.Errorf("Parse() level = %v, want %v", e.Level, tt.level)
+			}
+			if e.Message != tt.message {
+				t.Errorf("Parse() message = %q, want %q", e.Message, tt.message)
+			}
+			if e.Raw != tt.raw {
+				t.Errorf("Parse() raw = %q, want %q", e.Raw, tt.raw)
+			}
+			if e.Fields["unit"] != tt.unit || e.Fields["hostname"] != tt.host {
+				t.Errorf("Parse() unit = %q, hostname = %q", e.Fields["unit"], e.Fields["hostname"])
+			}
+			if !e.Time.Equal(tt.time) {
+				t.Errorf("Parse() time = %v, want %v", e.Time, tt.time)
+			}
+		})
+	}
+
+	if _, err := p.Parse(`{"MESSAGE": [300]}`); err == nil {
+		t.Error("Parse() accepted a byte value out of range")
+	}
+	if _, err := p.Parse("MESSAGE\n\x05\x00\x00"); err == nil {
+		t.Error("Parse() accepted a truncated binary field")
+	}
+}
+
+func TestJournalSplitRecords(t *testing.T) {
+	input := "PRIORITY=6\nMESSAGE=first\n\n" +
+		"PRIORITY=3\n" + binaryField("MESSAGE", "multi\n\nline") + "\n" +
+		`{"PRIORITY":"4","MESSAGE":"json"}` + "\n" +
+		"\n" +
+		"MESSAGE=last without separator\n"
+
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	// A tiny buffer makes the splitter ask for more data mid-record.
+	scanner.Buffer(make([]byte, 0, 4), 1024)
+	p := &JournalParser{}
+	scanner.Split(p.SplitRecords)
+
+	var messages []string
+	for scanner.Scan() {
+		e, err := p.Parse(scanner.Text())
+		if err != nil {
+			t.Fatalf("Parse(%q) unexpected error: %v", scanner.Text(), err)
+		}
+		messages = append(messages, e.Message)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	want := []string{"first", "multi\n\nline", "json", "last without separator"}
+	if strings.Join(messages, "|") != strings.Join(want, "|") {
+		t.Errorf("records = %q, want %q", messages, want)
+	}
+}
+
+func TestForTypeJournal(t *testing.T) {
+	for _, logType := range []string{"journal", "systemd journal", "journal json"} {
+		if _, ok := ForType(logType).(*JournalParser); !ok {
+			t.Errorf("ForType(%q) = %T, want *JournalParser", logType, ForType(logType))
+		}
+	}
+}
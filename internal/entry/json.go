@@ -0,0 +1,112 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	jsonLevelKeys   = []string{"level", "severity", "lvl", "log.level"}
+	jsonMessageKeys = []string{"msg", "message", "log"}
+	jsonTimeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+)
+
+// JSONParser parses one JSON object per line (JSON lines). Nested objects are
+// flattened into dotted field names, e.g. {"http":{"status":500}} becomes
+// the field "http.status". Timestamps without a zone are read in Location
+// (local time when nil).
+type JSONParser struct {
+	Location *time.Location
+}
+
+func (p *JSONParser) Parse(line string) (Entry, error) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+
+	var obj map[string]any
+	if err := decoder.Decode(&obj); err != nil {
+		return fallback(line), fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	fields := make(map[string]string, len(obj))
+	flatten("", obj, fields)
+
+	e := Entry{
+		Raw:     line,
+		Message: firstField(fields, jsonMessageKeys),
+		Fields:  fields,
+	}
+
+	if level := firstField(fields, jsonLevelKeys); level != "" {
+		e.Level, _ = ParseLevel(level)
+	}
+	if e.Level == LevelUnknown {
+		e.Level = DetectLevel(e.Message)
+	}
+
+	if ts := firstField(fields, jsonTimeKeys); ts != "" {
+		e.Time = parseJSONTime(ts, p.Location)
+	}
+
+	return e, nil
+}
+
+func flatten(prefix string, value any, out map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, out)
+		}
+	case string:
+		out[prefix] = v
+	case json.Number:
+		out[prefix] = v.String()
+	case bool:
+		out[prefix] = strconv.FormatBool(v)
+	case nil:
+		out[prefix] = ""
+	default:
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(v); err == nil {
+			out[prefix] = strings.TrimSpace(buf.String())
+		}
+	}
+}
+
+func firstField(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseJSONTime accepts RFC 3339 strings and Unix timestamps in seconds or
+// milliseconds.
+func parseJSONTime(value string, loc *time.Location) time.Time {
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		if f > 1e12 {
+			return time.UnixMilli(int64(f))
+		}
+		sec := int64(f)
+		return time.Unix(sec, int64((f-float64(sec))*1e9))
+	}
+
+	if ts, _, ok := parseLeadingTime(value, loc); ok {
+		return ts
+	}
+
+	return time.Time{}
+}
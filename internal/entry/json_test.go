@@ -0,0 +1,45 @@
+package entry
+
+import (
+	"testing"
+)
+
+func TestJSONParser(t *testing.T) {
+	p := &JSONParser{}
+
+	e, err := p.Parse(`{"time":"2024-01-01T10:00:00Z","level":"warn","msg":"slow query","http":{"status":500},"retry":true}`)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if e.Level != LevelWarn {
+		t.Errorf("Parse() level = %v, want WARN", e.Level)
+	}
+	if e.Message != "slow query" {
+		t.Errorf("Parse() message = %q, want %q", e.Message, "slow query")
+	}
+	if e.Fields["http.status"] != "500" {
+		t.Errorf("Parse() field http.status = %q, want 500", e.Fields["http.status"])
+	}
+	if e.Fields["retry"] != "true" {
+		t.Errorf("Parse() field retry = %q, want true", e.Fields["retry"])
+	}
+	if e.Time.IsZero() {
+		t.Error("Parse() did not parse time")
+	}
+
+	e, err = p.Parse(`{"ts":1704103200.5,"message":"ERROR boom"}`)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if e.Level != LevelError {
+		t.Errorf("Parse() level from message = %v, want ERROR", e.Level)
+	}
+	if e.Time.Unix() != 1704103200 {
+		t.Errorf("Parse() epoch time = %v", e.Time)
+	}
+
+	if _, err := p.Parse("plain text"); err == nil {
+		t.Error("Parse() expected error for non-JSON line, got nil")
+	}
+}
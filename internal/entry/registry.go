@@ -0,0 +1,95 @@
+package entry
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type parserFactory struct {
+	name  string
+	match func(logType string) bool
+	new   func(loc *time.Location) Parser
+}
+
+// factories are checked in order; the first one matching the configured log
+// type wins. Free-form text is the fallback for every other type.
+var factories = []parserFactory{
+	{
+		name:  "journal",
+		match: func(t string) bool { return strings.Contains(t, "journal") },
+		new:   func(*time.Location) Parser { return &JournalParser{} },
+	},
+	{
+		name:  "docker",
+		match: func(t string) bool { return strings.Contains(t, "docker") },
+		new:   func(loc *time.Location) Parser { return &DockerParser{Location: loc} },
+	},
+	{
+		name: "cri",
+		match: func(t string) bool {
+			return hasWord(t, "cri") || strings.Contains(t, "cri-o") || strings.Contains(t, "containerd") || strings.Contains(t, "kubernetes")
+		},
+		new: func(loc *time.Location) Parser { return &CRIParser{Location: loc} },
+	},
+	{
+		name:  "json",
+		match: func(t string) bool { return strings.Contains(t, "json") },
+		new:   func(loc *time.Location) Parser { return &JSONParser{Location: loc} },
+	},
+	{
+		name: "access",
+		match: func(t string) bool {
+			if strings.Contains(t, "access") {
+				return true
+			}
+			return (strings.Contains(t, "nginx") || strings.Contains(t, "apache")) && !strings.Contains(t, "error")
+		},
+		new: func(*time.Location) Parser { return &AccessParser{} },
+	},
+	{
+		name:  "syslog",
+		match: func(t string) bool { return strings.Contains(t, "syslog") || strings.Contains(t, "system") },
+		new:   func(loc *time.Location) Parser { return &SyslogParser{Location: loc} },
+	},
+}
+
+// ForType returns a parser for the configured log type. The type is matched
+// loosely (e.g. "nginx access", "JSON lines", "system log") so existing
+// free-form descriptions keep working.
+func ForType(logType string) Parser {
+	return ForTypeIn(logType, nil)
+}
+
+// ForTypeIn is like ForType, but timestamps that carry no zone are read in
+// loc instead of the local time zone.
+func ForTypeIn(logType string, loc *time.Location) Parser {
+	t := strings.ToLower(logType)
+	for _, f := range factories {
+		if f.match(t) {
+			return f.new(loc)
+		}
+	}
+	return &TextParser{Location: loc}
+}
+
+// ForLog is like ForTypeIn, and the parsers of container logs also add the
+// metadata found in the path of the log to every entry.
+func ForLog(logType, logPath string, loc *time.Location) Parser {
+	p := ForTypeIn(logType, loc)
+	switch p := p.(type) {
+	case *DockerParser:
+		p.Metadata = ContainerMetadata(logPath)
+	case *CRIParser:
+		p.Metadata = ContainerMetadata(logPath)
+	}
+	return p
+}
+
+// hasWord reports whether t contains word delimited by non-letters, so "cri"
+// is found in "k8s cri" but not in "critical".
+func hasWord(t, word string) bool {
+	words := strings.FieldsFunc(t, func(r rune) bool { return !unicode.IsLetter(r) })
+	return slices.Contains(words, word)
+}
@@ -0,0 +1,135 @@
+package entry
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG (RFC 5424)
+	rfc5424Pattern = regexp.MustCompile(`^<(\d{1,3})>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]"]|"(?:[^"\\]|\\.)*")*\])+)(?: (.*))?$`)
+	// [<PRI>]Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG (RFC 3164, /var/log/syslog)
+	rfc3164Pattern = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+	// ISO 8601 timestamps written by rsyslog's high-precision template.
+	isoSyslogPattern = regexp.MustCompile(`^(?:<(\d{1,3})>)?(\d{4}-\d{2}-\d{2}T\S+) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+)
+
+var syslogSeverityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// SyslogParser parses RFC 5424 and RFC 3164 syslog lines, with or without the
+// leading <PRI>. The level comes from the PRI severity when present.
+// RFC 3164 timestamps carry no zone and are read in Location (local time
+// when nil).
+type SyslogParser struct {
+	Location *time.Location
+
+	// now is used to infer the year of RFC 3164 timestamps; nil means time.Now.
+	now func() time.Time
+}
+
+func (p *SyslogParser) Parse(line string) (Entry, error) {
+	if m := rfc5424Pattern.FindStringSubmatch(line); m != nil {
+		e := syslogEntry(line, m[1], m[8])
+		e.Fields["hostname"] = nilValue(m[3])
+		e.Fields["app"] = nilValue(m[4])
+		e.Fields["pid"] = nilValue(m[5])
+		e.Fields["msgid"] = nilValue(m[6])
+		if m[7] != "-" {
+			e.Fields["structured_data"] = m[7]
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, m[2]); err == nil {
+			e.Time = ts
+		}
+		return e, nil
+	}
+
+	if m := rfc3164Pattern.FindStringSubmatch(line); m != nil {
+		e := syslogEntry(line, m[1], m[6])
+		e.Fields["hostname"] = m[3]
+		e.Fields["app"] = m[4]
+		e.Fields["pid"] = m[5]
+		e.Time = p.parseStamp(m[2])
+		return e, nil
+	}
+
+	if m := isoSyslogPattern.FindStringSubmatch(line); m != nil {
+		e := syslogEntry(line, m[1], m[6])
+		e.Fields["hostname"] = m[3]
+		e.Fields["app"] = m[4]
+		e.Fields["pid"] = m[5]
+		if ts, err := time.Parse(time.RFC3339Nano, m[2]); err == nil {
+			e.Time = ts
+		}
+		return e, nil
+	}
+
+	return fallback(line), fmt.Errorf("line does not match syslog format")
+}
+
+func syslogEntry(line, pri, message string) Entry {
+	e := Entry{
+		Raw:     line,
+		Message: message,
+		Fields:  map[string]string{},
+	}
+
+	if value, err := strconv.Atoi(pri); err == nil && value <= 191 {
+		severity := value % 8
+		e.Fields["facility"] = strconv.Itoa(value / 8)
+		e.Fields["severity"] = syslogSeverityNames[severity]
+		e.Level = SeverityLevel(severity)
+	} else {
+		e.Level = DetectLevel(message)
+	}
+
+	return e
+}
+
+// SeverityLevel maps a numeric syslog severity (0-7) onto a Level.
+func SeverityLevel(severity int) Level {
+	switch {
+	case severity < 0:
+		return LevelUnknown
+	case severity <= 2:
+		return LevelFatal
+	case severity == 3:
+		return LevelError
+	case severity == 4:
+		return LevelWarn
+	case severity <= 6:
+		return LevelInfo
+	case severity == 7:
+		return LevelDebug
+	}
+	return LevelUnknown
+}
+
+// parseStamp parses an RFC 3164 timestamp, which has no year. The current year
+// is assumed unless that would put the entry more than a day in the future.
+func (p *SyslogParser) parseStamp(stamp string) time.Time {
+	now := time.Now()
+	if p.now != nil {
+		now = p.now()
+	}
+
+	ts, err := time.ParseInLocation("Jan _2 15:04:05", stamp, location(p.Location))
+	if err != nil {
+		return time.Time{}
+	}
+
+	ts = ts.AddDate(now.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts
+}
+
+func nilValue(value string) string {
+	if value == "-" {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
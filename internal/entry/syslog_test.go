@@ -0,0 +1,78 @@
+package entry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyslogParser(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.Local)
+	p := &SyslogParser{now: func() time.Time { return now }}
+
+	tests := []struct {
+		name    string
+		line    string
+		level   Level
+		app     string
+		message string
+		year    int
+	}{
+		{
+			name:    "RFC 5424",
+			line:    `<165>1 2024-03-09T22:14:15.003Z mymachine evntslog - ID47 [exampleSDID@32473 iut="3"] An application event`,
+			level:   LevelInfo,
+			app:     "evntslog",
+			message: "An application event",
+			year:    2024,
+		},
+		{
+			name:    "RFC 3164 with PRI",
+			line:    `<11>Mar  9 22:14:15 host sshd[123]: Failed password for root`,
+			level:   LevelError,
+			app:     "sshd",
+			message: "Failed password for root",
+			year:    2024,
+		},
+		{
+			name:    "RFC 3164 from the previous year",
+			line:    `Dec 31 23:59:59 host kernel: eth0 link down`,
+			level:   LevelUnknown,
+			app:     "kernel",
+			message: "eth0 link down",
+			year:    2023,
+		},
+		{
+			name:    "ISO timestamp",
+			line:    `2024-03-09T22:14:15.123456+00:00 host cron[42]: ERROR job failed`,
+			level:   LevelError,
+			app:     "cron",
+			message: "ERROR job failed",
+			year:    2024,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := p.Parse(tt.line)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if e.Level != tt.level {
+				t.Errorf("Parse() level = %v, want %v", e.Level, tt.level)
+			}
+			if e.Fields["app"] != tt.app {
+				t.Errorf("Parse() app = %q, want %q", e.Fields["app"], tt.app)
+			}
+			if e.Message != tt.message {
+				t.Errorf("Parse() message = %q, want %q", e.Message, tt.message)
+			}
+			if e.Time.Year() != tt.year {
+				t.Errorf("Parse() year = %d, want %d", e.Time.Year(), tt.year)
+			}
+		})
+	}
+
+	if _, err := p.Parse("definitely not syslog"); err == nil {
+		t.Error("Parse() expected error for malformed line, got nil")
+	}
+}
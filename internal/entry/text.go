@@ -0,0 +1,67 @@
+package entry
+
+import (
+	"strings"
+	"time"
+)
+
+// textTimeLayouts are tried against the start of a free-form line.
+var textTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.000000",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05,000",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05.000000",
+	"2006/01/02 15:04:05",
+}
+
+// TextParser handles free-form application logs. It never fails: the level is
+// guessed from the text and a leading timestamp is used when one is present.
+// Timestamps without a zone are read in Location (local time when nil).
+type TextParser struct {
+	Location *time.Location
+}
+
+func (p *TextParser) Parse(line string) (Entry, error) {
+	e := fallback(line)
+
+	if ts, rest, ok := parseLeadingTime(line, p.Location); ok {
+		e.Time = ts
+		e.Message = strings.TrimSpace(rest)
+	}
+
+	return e, nil
+}
+
+// parseLeadingTime looks for a known timestamp layout at the start of line and
+// returns the time and the remainder of the line. Layouts without a zone are
+// parsed in loc.
+func parseLeadingTime(line string, loc *time.Location) (time.Time, string, bool) {
+	for _, layout := range textTimeLayouts {
+		if layout == time.RFC3339Nano {
+			token, rest, _ := strings.Cut(line, " ")
+			if ts, err := time.Parse(time.RFC3339Nano, token); err == nil {
+				return ts, rest, true
+			}
+			continue
+		}
+
+		if len(line) < len(layout) {
+			continue
+		}
+		if ts, err := time.ParseInLocation(layout, line[:len(layout)], location(loc)); err == nil {
+			return ts, line[len(layout):], true
+		}
+	}
+
+	return time.Time{}, line, false
+}
+
+// location returns loc, or the local time zone when loc is nil.
+func location(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,458 @@
+// Package groupby computes SQL-like summaries of log entries: the entries are
+// grouped by the values of some fields, and each group gets aggregates such as
+// count, sum, avg and percentiles of numeric fields. Tables are mergeable, so
+// logs can be summarized concurrently and their partial tables combined.
+package groupby
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"loganalyzer/internal/entry"
+	"loganalyzer/internal/query"
+	"loganalyzer/internal/sketch"
+)
+
+// The aggregate functions.
+const (
+	FuncCount      = "count"
+	FuncSum        = "sum"
+	FuncAvg        = "avg"
+	FuncMin        = "min"
+	FuncMax        = "max"
+	FuncPercentile = "p"
+)
+
+// Missing is the key of the entries without a group-by field.
+const Missing = "-"
+
+// aggregatePattern matches "func", "func(field)" and "pNN(field)".
+var aggregatePattern = regexp.MustCompile(`^([A-Za-z]+)([0-9]+(?:\.[0-9]+)?)?(?:\(\s*([^()\s]+)\s*\))?$`)
+
+// Aggregate is an aggregate function applied to the entries of each group.
+// Count counts the entries, or those having Field when it is set; the other
+// functions take the numeric values of Field, ignoring the entries where it
+// is missing or not a number.
+type Aggregate struct {
+	Func  string
+	Field string
+	// Percentile is the percentile of FuncPercentile, e.g. 95 or 99.9.
+	Percentile float64
+}
+
+// Name returns the aggregate as written in a specification, e.g. "count" or
+// "p95(request_time)".
+func (a Aggregate) Name() string {
+	name := a.Func
+	if a.Func == FuncPercentile {
+		name += strconv.FormatFloat(a.Percentile, 'f', -1, 64)
+	}
+	if a.Field != "" {
+		name += "(" + a.Field + ")"
+	}
+	return name
+}
+
+// ParseAggregates reads a comma-separated list of aggregates such as
+// "count,avg(bytes),p95(request_time)".
+func ParseAggregates(spec string) ([]Aggregate, error) {
+	var aggregates []Aggregate
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		aggregate, err := parseAggregate(part)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = append(aggregates, aggregate)
+	}
+	if len(aggregates) == 0 {
+		return nil, fmt.Errorf("no aggregates (use e.g. count, avg(bytes), p95(request_time))")
+	}
+	return aggregates, nil
+}
+
+func parseAggregate(text string) (Aggregate, error) {
+	m := aggregatePattern.FindStringSubmatch(text)
+	if m == nil {
+		return Aggregate{}, fmt.Errorf("invalid aggregate %q (use count, sum, avg, min, max or pNN, with a field in parentheses)", text)
+	}
+	a := Aggregate{Func: strings.ToLower(m[1]), Field: m[3]}
+
+	switch {
+	case a.Func == FuncCount && m[2] == "":
+		return a, nil
+	case (a.Func == FuncSum || a.Func == FuncAvg || a.Func == FuncMin || a.Func == FuncMax) && m[2] == "":
+	case a.Func == FuncPercentile && m[2] != "":
+		p, _ := strconv.ParseFloat(m[2], 64)
+		if p > 100 {
+			return Aggregate{}, fmt.Errorf("invalid aggregate %q: percentile must be between 0 and 100", text)
+		}
+		a.Percentile = p
+	default:
+		return Aggregate{}, fmt.Errorf("unknown aggregate %q (use count, sum, avg, min, max or pNN)", text)
+	}
+
+	if a.Field == "" {
+		return Aggregate{}, fmt.Errorf("aggregate %q needs a field, e.g. %s(request_time)", text, text)
+	}
+	return a, nil
+}
+
+// ParseFields reads a comma-separated list of group-by fields.
+func ParseFields(spec string) []string {
+	var fields []string
+	for _, field := range strings.Split(spec, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Table accumulates the groups of entries and their aggregates. A Table is
+// not safe for concurrent use; use one per goroutine and Merge them.
+type Table struct {
+	groupBy    []string
+	aggregates []Aggregate
+	groups     map[string]*group
+	entries    int
+	// mask rewrites the group-by values before grouping, see MaskKeys.
+	mask func(field, value string) string
+}
+
+// group is the state of the aggregates of one group, by aggregate index.
+type group struct {
+	key      []string
+	counts   []int
+	sums     []float64
+	mins     []float64
+	maxs     []float64
+	sketches []*sketch.Sketch
+}
+
+// NewTable returns an empty table grouping by the fields, or putting every
+// entry in a single group when there are none.
+func NewTable(groupBy []string, aggregates []Aggregate) *Table {
+	return &Table{
+		groupBy:    groupBy,
+		aggregates: aggregates,
+		groups:     make(map[string]*group),
+	}
+}
+
+// MaskKeys sets a function rewriting the value of each group-by field, e.g.
+// to redact it, before the entries are grouped. Groups are then keyed, and
+// sorted, by the rewritten values only. Missing values are not rewritten.
+func (t *Table) MaskKeys(fn func(field, value string) string) {
+	t.mask = fn
+}
+
+// Add adds an entry to its group.
+func (t *Table) Add(e entry.Entry) {
+	t.entries++
+
+	key := make([]string, len(t.groupBy))
+	for i, field := range t.groupBy {
+		value, ok := query.Field(e, field)
+		switch {
+		case !ok || value == "":
+			value = Missing
+		case t.mask != nil:
+			value = t.mask(field, value)
+		}
+		key[i] = value
+	}
+	g := t.group(key)
+
+	for i, a := range t.aggregates {
+		if a.Field == "" {
+			g.counts[i]++
+			continue
+		}
+		value, ok := query.Field(e, a.Field)
+		if !ok {
+			continue
+		}
+		if a.Func == FuncCount {
+			g.counts[i]++
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			continue
+		}
+		g.add(i, a, n)
+	}
+}
+
+// Merge adds the groups of other, a table with the same group-by fields and
+// aggregates, to t.
+func (t *Table) Merge(other *Table) {
+	t.entries += other.entries
+	for _, o := range other.groups {
+		g := t.group(o.key)
+		for i, a := range t.aggregates {
+			if o.counts[i] == 0 {
+				continue
+			}
+			if g.counts[i] == 0 {
+				g.mins[i], g.maxs[i] = o.mins[i], o.maxs[i]
+			} else {
+				g.mins[i], g.maxs[i] = math.Min(g.mins[i], o.mins[i]), math.Max(g.maxs[i], o.maxs[i])
+			}
+			g.counts[i] += o.counts[i]
+			g.sums[i] += o.sums[i]
+			if a.Func == FuncPercentile {
+				// Sketches of the same table always share their accuracy.
+				_ = g.sketches[i].Merge(o.sketches[i])
+			}
+		}
+	}
+}
+
+// Entries returns the number of entries added.
+func (t *Table) Entries() int {
+	return t.entries
+}
+
+func (t *Table) group(key []string) *group {
+	id := strings.Join(key, "\x00")
+	g, ok := t.groups[id]
+	if !ok {
+		n := len(t.aggregates)
+		g = &group{
+			key:      key,
+			counts:   make([]int, n),
+			sums:     make([]float64, n),
+			mins:     make([]float64, n),
+			maxs:     make([]float64, n),
+			sketches: make([]*sketch.Sketch, n),
+		}
+		for i, a := range t.aggregates {
+			if a.Func == FuncPercentile {
+				g.sketches[i] = sketch.New()
+			}
+		}
+		t.groups[id] = g
+	}
+	return g
+}
+
+func (g *group) add(i int, a Aggregate, value float64) {
+	if g.counts[i] == 0 {
+		g.mins[i], g.maxs[i] = value, value
+	} else {
+		g.mins[i], g.maxs[i] = math.Min(g.mins[i], value), math.Max(g.maxs[i], value)
+	}
+	g.counts[i]++
+	g.sums[i] += value
+	if a.Func == FuncPercentile {
+		g.sketches[i].Add(value)
+	}
+}
+
+// value returns the aggregate i of the group, nil when it has no value, e.g.
+// the average of a field no entry of the group had.
+func (g *group) value(i int, a Aggregate) *float64 {
+	if a.Func == FuncCount {
+		v := float64(g.counts[i])
+		return &v
+	}
+	if g.counts[i] == 0 {
+		return nil
+	}
+
+	var v float64
+	switch a.Func {
+	case FuncSum:
+		v = g.sums[i]
+	case FuncAvg:
+		v = g.sums[i] / float64(g.counts[i])
+	case FuncMin:
+		v = g.mins[i]
+	case FuncMax:
+		v = g.maxs[i]
+	case FuncPercentile:
+		v = g.sketches[i].Quantile(a.Percentile / 100)
+	}
+	return &v
+}
+
+// Result is the summary of a table, as printed or saved.
+type Result struct {
+	GroupBy    []string `json:"group_by,omitempty"`
+	Aggregates []string `json:"aggregates"`
+	Entries    int      `json:"entries"`
+	Groups     int      `json:"groups"`
+	Rows       []Row    `json:"rows"`
+}
+
+// Row is a group: its values of the group-by fields, then of the aggregates,
+// in the order of Result.GroupBy and Result.Aggregates. A nil value is an
+// aggregate without values in the group.
+type Row struct {
+	Key    []string   `json:"key,omitempty"`
+	Values []*float64 `json:"values"`
+}
+
+// Result returns the top rows of the table, top being 0 for all of them.
+// Rows are sorted by the aggregate or group-by field named by sortBy, the
+// largest aggregate values first and field values in ascending order; the
+// first aggregate is used when sortBy is empty.
+func (t *Table) Result(sortBy string, top int) (*Result, error) {
+	column, byKey, err := t.sortColumn(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		GroupBy: t.groupBy,
+		Entries: t.entries,
+		Groups:  len(t.groups),
+		Rows:    make([]Row, 0, len(t.groups)),
+	}
+	for _, a := range t.aggregates {
+		result.Aggregates = append(result.Aggregates, a.Name())
+	}
+	for _, g := range t.groups {
+		row := Row{Key: g.key, Values: make([]*float64, len(t.aggregates))}
+		for i, a := range t.aggregates {
+			row.Values[i] = g.value(i, a)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	sort.Slice(result.Rows, func(i, j int) bool {
+		a, b := result.Rows[i], result.Rows[j]
+		if !byKey {
+			va, vb := a.Values[column], b.Values[column]
+			switch {
+			case va != nil && vb == nil:
+				return true
+			case va == nil && vb != nil:
+				return false
+			case va != nil && *va != *vb:
+				return *va > *vb
+			}
+		} else if a.Key[column] != b.Key[column] {
+			return lessKey(a.Key[column], b.Key[column])
+		}
+		return strings.Join(a.Key, "\x00") < strings.Join(b.Key, "\x00")
+	})
+
+	if top > 0 && len(result.Rows) > top {
+		result.Rows = result.Rows[:top]
+	}
+	return result, nil
+}
+
+// sortColumn returns the index of the aggregate, or of the group-by field
+// when byKey is set, named by sortBy.
+func (t *Table) sortColumn(sortBy string) (column int, byKey bool, err error) {
+	if sortBy == "" {
+		return 0, false, nil
+	}
+	for i, a := range t.aggregates {
+		if a.Name() == sortBy {
+			return i, false, nil
+		}
+	}
+	for i, field := range t.groupBy {
+		if field == sortBy {
+			return i, true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("cannot sort by %q: not an aggregate or group-by field", sortBy)
+}
+
+// lessKey orders field values numerically when both are numbers, e.g. status
+// codes or hours.
+func lessKey(a, b string) bool {
+	na, errA := strconv.ParseFloat(a, 64)
+	nb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil && na != nb {
+		return na < nb
+	}
+	return a < b
+}
+
+// Print writes the rows as an aligned table, followed by the number of
+// groups shown.
+func (r *Result) Print(w io.Writer) {
+	header := append(append([]string{}, r.GroupBy...), r.Aggregates...)
+	cells := [][]string{header}
+	for _, row := range r.Rows {
+		cells = append(cells, r.cells(row))
+	}
+
+	widths := make([]int, len(header))
+	for _, line := range cells {
+		for i, cell := range line {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	for _, line := range cells {
+		parts := make([]string, len(line))
+		for i, cell := range line {
+			pad := strings.Repeat(" ", widths[i]-len([]rune(cell)))
+			if i < len(r.GroupBy) {
+				parts[i] = cell + pad
+			} else {
+				parts[i] = pad + cell
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(parts, "  "), " "))
+	}
+
+	fmt.Fprintf(w, "\n%d of %d groups, %d entries\n", len(r.Rows), r.Groups, r.Entries)
+}
+
+// WriteCSV writes the rows as CSV with a header line. Aggregates without
+// values are empty.
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string{}, r.GroupBy...), r.Aggregates...)); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		record := append([]string{}, row.Key...)
+		for _, v := range row.Values {
+			if v == nil {
+				record = append(record, "")
+			} else {
+				record = append(record, strconv.FormatFloat(*v, 'f', -1, 64))
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cells returns the printed values of a row.
+func (r *Result) cells(row Row) []string {
+	cells := append([]string{}, row.Key...)
+	for _, v := range row.Values {
+		cells = append(cells, formatValue(v))
+	}
+	return cells
+}
+
+// formatValue prints a value with at most 3 decimals, "-" when it is nil.
+func formatValue(v *float64) string {
+	if v == nil {
+		return Missing
+	}
+	return strconv.FormatFloat(math.Round(*v*1000)/1000, 'f', -1, 64)
+}
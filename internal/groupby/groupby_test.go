@@ -0,0 +1,212 @@
+package groupby
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"loganalyzer/internal/entry"
+)
+
+func TestParseAggregates(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{spec: "count", want: []string{"count"}},
+		{spec: "COUNT, p95(request_time), avg( bytes ),p99.9(request_time)", want: []string{"count", "p95(request_time)", "avg(bytes)", "p99.9(request_time)"}},
+		{spec: "count(user_agent),sum(bytes),min(bytes),max(bytes)", want: []string{"count(user_agent)", "sum(bytes)", "min(bytes)", "max(bytes)"}},
+		{spec: "", wantErr: true},
+		{spec: "avg", wantErr: true},
+		{spec: "p95", wantErr: true},
+		{spec: "p101(request_time)", wantErr: true},
+		{spec: "median(request_time)", wantErr: true},
+		{spec: "sum95(bytes)", wantErr: true},
+		{spec: "count5", wantErr: true},
+		{spec: "avg(bytes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			aggregates, err := ParseAggregates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAggregates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, a := range aggregates {
+				names = append(names, a.Name())
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("ParseAggregates() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func accessEntry(status, path, requestTime string) entry.Entry {
+	fields := map[string]string{"status": status, "path": path}
+	if requestTime != "" {
+		fields["request_time"] = requestTime
+	}
+	return entry.Entry{Level: entry.LevelInfo, Fields: fields}
+}
+
+func TestTable(t *testing.T) {
+	aggregates, err := ParseAggregates("count,avg(request_time),max(request_time),p50(request_time)")
+	if err != nil {
+		t.Fatalf("ParseAggregates() error = %v", err)
+	}
+	table := NewTable([]string{"status", "path"}, aggregates)
+	table.Add(accessEntry("200", "/api", "0.1"))
+	table.Add(accessEntry("200", "/api", "0.3"))
+	table.Add(accessEntry("200", "/api", "0.2"))
+	table.Add(accessEntry("500", "/api", "2.5"))
+	table.Add(accessEntry("404", "/missing", "-"))
+	table.Add(entry.Entry{Raw: "no fields"})
+
+	result, err := table.Result("", 0)
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	if result.Entries != 6 || result.Groups != 4 {
+		t.Errorf("Result() entries = %d, groups = %d, want 6 and 4", result.Entries, result.Groups)
+	}
+
+	var got []string
+	for _, row := range result.Rows {
+		got = append(got, row.Key[0]+" "+row.Key[1]+":"+formatRow(row))
+	}
+	want := []string{
+		"200 /api:3 0.2 0.3 0.2",
+		"- -:1 - - -",
+		"404 /missing:1 - - -",
+		"500 /api:1 2.5 2.5 2.5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Result() rows =\n%q\nwant\n%q", got, want)
+	}
+
+	byStatus, err := table.Result("status", 2)
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	if len(byStatus.Rows) != 2 || byStatus.Rows[0].Key[0] != "-" || byStatus.Rows[1].Key[0] != "200" {
+		t.Errorf("Result() sorted by status = %v", byStatus.Rows)
+	}
+
+	byMax, err := table.Result("max(request_time)", 1)
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	if byMax.Rows[0].Key[0] != "500" {
+		t.Errorf("Result() sorted by max = %v, want status 500 first", byMax.Rows)
+	}
+
+	if _, err := table.Result("bytes", 0); err == nil {
+		t.Error("Result() accepted an unknown sort column")
+	}
+}
+
+func TestTableMerge(t *testing.T) {
+	aggregates, err := ParseAggregates("count,sum(request_time),min(request_time),p95(request_time)")
+	if err != nil {
+		t.Fatalf("ParseAggregates() error = %v", err)
+	}
+
+	whole := NewTable([]string{"status"}, aggregates)
+	parts := []*Table{NewTable([]string{"status"}, aggregates), NewTable([]string{"status"}, aggregates)}
+	for i := 0; i < 200; i++ {
+		e := accessEntry(strconv.Itoa(200+i%3*100), "/", strconv.Itoa(i))
+		whole.Add(e)
+		parts[i%7/4].Add(e)
+	}
+
+	merged := NewTable([]string{"status"}, aggregates)
+	merged.Merge(parts[0])
+	merged.Merge(parts[1])
+
+	want, _ := whole.Result("", 0)
+	got, _ := merged.Result("", 0)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("merged Result() = %+v, want %+v", got, want)
+	}
+}
+
+func TestTableMaskKeys(t *testing.T) {
+	aggregates, err := ParseAggregates("count")
+	if err != nil {
+		t.Fatalf("ParseAggregates() error = %v", err)
+	}
+	table := NewTable([]string{"path"}, aggregates)
+	masked := map[string]string{"/b": "<1>", "/a": "<2>"}
+	table.MaskKeys(func(field, value string) string {
+		if field != "path" {
+			t.Errorf("mask called for field %q, want path", field)
+		}
+		return masked[value]
+	})
+	table.Add(accessEntry("200", "/a", ""))
+	table.Add(accessEntry("200", "/b", ""))
+	table.Add(accessEntry("200", "/b", ""))
+	table.Add(entry.Entry{Raw: "no fields"})
+
+	result, err := table.Result("path", 0)
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	var got []string
+	for _, row := range result.Rows {
+		got = append(got, row.Key[0]+":"+formatRow(row))
+	}
+	// Sorted by the masked values, the missing key left as it is.
+	if want := []string{"-:1", "<1>:2", "<2>:1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Result() rows = %q, want %q", got, want)
+	}
+}
+
+func TestResultPrint(t *testing.T) {
+	aggregates, err := ParseAggregates("count,p95(request_time)")
+	if err != nil {
+		t.Fatalf("ParseAggregates() error = %v", err)
+	}
+	table := NewTable([]string{"path"}, aggregates)
+	table.Add(accessEntry("200", "/api/orders", "0.25"))
+	table.Add(accessEntry("200", "/api/orders", "0.5"))
+	table.Add(accessEntry("200", "/", ""))
+
+	result, err := table.Result("", 0)
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	result.Print(&buf)
+	want := "path         count  p95(request_time)\n" +
+		"/api/orders      2                0.5\n" +
+		"/                1                  -\n" +
+		"\n2 of 2 groups, 3 entries\n"
+	if buf.String() != want {
+		t.Errorf("Print() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := result.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	if want := "path,count,p95(request_time)\n/api/orders,2,0.5\n/,1,\n"; buf.String() != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func formatRow(row Row) string {
+	text := ""
+	for i, v := range row.Values {
+		if i > 0 {
+			text += " "
+		}
+		text += formatValue(v)
+	}
+	return text
+}
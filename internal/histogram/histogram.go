@@ -0,0 +1,84 @@
+package histogram
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"loganalyzer/internal/reporter"
+)
+
+// Builder counts entries per level in fixed time buckets. Buckets are aligned
+// on multiples of the bucket size (e.g. 10:05, 10:10 for 5m) in UTC.
+type Builder struct {
+	size    time.Duration
+	buckets map[int64]*reporter.Bucket
+	untimed int
+}
+
+func NewBuilder(size time.Duration) *Builder {
+	return &Builder{
+		size:    size,
+		buckets: make(map[int64]*reporter.Bucket),
+	}
+}
+
+// Add counts an entry. Entries without a timestamp are only counted as untimed.
+func (b *Builder) Add(ts time.Time, level string) {
+	if ts.IsZero() {
+		b.untimed++
+		return
+	}
+
+	start := ts.UTC().Truncate(b.size)
+	key := start.UnixNano()
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &reporter.Bucket{Start: start, Levels: make(map[string]int)}
+		b.buckets[key] = bucket
+	}
+	bucket.Total++
+	bucket.Levels[level]++
+}
+
+// Histogram returns a copy of the non-empty buckets in chronological order,
+// or nil when no entry had a timestamp.
+func (b *Builder) Histogram() *reporter.Histogram {
+	if len(b.buckets) == 0 {
+		return nil
+	}
+
+	keys := make([]int64, 0, len(b.buckets))
+	for key := range b.buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	h := &reporter.Histogram{
+		Bucket:  shortDuration(b.size),
+		Untimed: b.untimed,
+		Buckets: make([]reporter.Bucket, 0, len(keys)),
+	}
+	for _, key := range keys {
+		bucket := *b.buckets[key]
+		bucket.Levels = make(map[string]int, len(bucket.Levels))
+		for level, count := range b.buckets[key].Levels {
+			bucket.Levels[level] = count
+		}
+		h.Buckets = append(h.Buckets, bucket)
+	}
+	return h
+}
+
+// shortDuration formats d without zero trailing units ("5m" rather than "5m0s").
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package histogram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	base := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+
+	b := NewBuilder(5 * time.Minute)
+	b.Add(base.Add(12*time.Minute), "ERROR")
+	b.Add(base.Add(1*time.Minute), "INFO")
+	b.Add(base.Add(3*time.Minute), "ERROR")
+	b.Add(base.Add(14*time.Minute), "INFO")
+	b.Add(time.Time{}, "INFO")
+
+	h := b.Histogram()
+	if h == nil {
+		t.Fatal("Histogram() returned nil")
+	}
+
+	if h.Bucket != "5m" {
+		t.Errorf("Histogram() bucket = %q, want 5m", h.Bucket)
+	}
+	if h.Untimed != 1 {
+		t.Errorf("Histogram() untimed = %d, want 1", h.Untimed)
+	}
+	if len(h.Buckets) != 2 {
+		t.Fatalf("Histogram() returned %d buckets, want 2", len(h.Buckets))
+	}
+
+	if !h.Buckets[0].Start.Equal(base) || h.Buckets[0].Total != 2 || h.Buckets[0].Levels["ERROR"] != 1 {
+		t.Errorf("first bucket = %+v", h.Buckets[0])
+	}
+	if !h.Buckets[1].Start.Equal(base.Add(10*time.Minute)) || h.Buckets[1].Total != 2 {
+		t.Errorf("second bucket = %+v", h.Buckets[1])
+	}
+
+	if NewBuilder(time.Minute).Histogram() != nil {
+		t.Error("Histogram() of an empty builder should be nil")
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:                "1m",
+		5 * time.Minute:            "5m",
+		time.Hour:                  "1h",
+		90 * time.Second:           "1m30s",
+		time.Hour + 30*time.Minute: "1h30m",
+		30 * time.Second:           "30s",
+	}
+
+	for d, want := range tests {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/reporter"
+)
+
+func TestNewRecords(t *testing.T) {
+	ok := reporter.CreateSuccessResult("web", "/var/log/web.log")
+	ok.Stats = reporter.NewLogStats()
+	ok.Stats.Lines = 10
+	ok.Stats.Levels["ERROR"] = 2
+	ok.Alerts = []reporter.Alert{{Rule: "errors"}}
+
+	results := []reporter.AnalysisResult{
+		ok,
+		reporter.CreateFailureResult("db", "/var/log/db.log", "File not found", "missing"),
+		reporter.CreateSkippedResult("old", "/var/log/old.log", "disabled in configuration"),
+	}
+
+	records := NewRecords(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), results)
+	if len(records) != 2 {
+		t.Fatalf("NewRecords() = %d records, want 2 (skipped logs excluded)", len(records))
+	}
+	if records[0].Errors() != 2 || records[0].ErrorRate != 0.2 || records[0].Alerts != 1 {
+		t.Errorf("NewRecords()[0] = %+v", records[0])
+	}
+	if records[1].Status != "FAILURE" {
+		t.Errorf("NewRecords()[1].Status = %s, want FAILURE", records[1].Status)
+	}
+}
+
+func TestStoreAppendLoadPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	store := NewStore(path)
+
+	if records, err := store.Load(nil); err != nil || len(records) != 0 {
+		t.Fatalf("Load() of missing file = %v, %v; want empty history", records, err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 10, 0, 0, 0, time.UTC) }
+	for d := 1; d <= 3; d++ {
+		if err := store.Append([]Record{{Run: day(d), LogID: "web", Status: "OK"}, {Run: day(d), LogID: "db", Status: "OK"}}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// A line cut short by a crash must not hide the rest of the history.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+	file.WriteString(`{"run":"2024-01-0`)
+	file.Close()
+
+	web, err := store.Load(func(r Record) bool { return r.LogID == "web" })
+	if err != nil || len(web) != 3 {
+		t.Fatalf("Load(web) = %d records, %v; want 3", len(web), err)
+	}
+
+	removed, err := store.Prune(day(3))
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if removed != 4 {
+		t.Errorf("Prune() removed %d, want 4", removed)
+	}
+
+	all, err := store.Load(nil)
+	if err != nil || len(all) != 2 || !all[0].Run.Equal(day(3)) {
+		t.Errorf("Load() after prune = %+v, %v", all, err)
+	}
+}
+
+func TestStoreConcurrentAppendPrune(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := NewStore(path)
+	old := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	recent := old.AddDate(0, 1, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := store.Append([]Record{{Run: old, LogID: "web", Status: "OK"}, {Run: recent, LogID: "web", Status: "OK"}}); err != nil {
+				t.Errorf("Append() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// Another process sharing the file.
+			if _, err := NewStore(path).Prune(recent); err != nil {
+				t.Errorf("Prune() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, err := store.Load(func(r Record) bool { return r.Run.Equal(recent) })
+	if err != nil || len(all) != 20 {
+		t.Errorf("Load() = %d recent records, %v; want all 20 kept by the prunes", len(all), err)
+	}
+
+	// Nothing older than the cutoff: the file is not rewritten.
+	if _, err := store.Prune(old); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	before, _ := os.Stat(path)
+	if removed, err := store.Prune(old); err != nil || removed != 0 {
+		t.Fatalf("Prune() = %d, %v; want nothing removed", removed, err)
+	}
+	if after, _ := os.Stat(path); !os.SameFile(before, after) {
+		t.Error("Prune() rewrote the file without removing any record")
+	}
+}
+
+func TestTrends(t *testing.T) {
+	at := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	records := []Record{
+		{Run: at(1, 8), LogID: "web", Status: "OK", Lines: 100, Levels: map[string]int{"ERROR": 5, "WARN": 1}},
+		{Run: at(1, 20), LogID: "web", Status: "FAILURE"},
+		{Run: at(2, 8), LogID: "web", Status: "FAILURE"},
+		{Run: at(2, 20), LogID: "web", Status: "OK", Lines: 50, Levels: map[string]int{"FATAL": 1}},
+		{Run: at(3, 8), LogID: "web", Status: "OK", Lines: 10},
+		{Run: at(1, 8), LogID: "api", Status: "OK"},
+	}
+
+	trends := Trends(records, time.UTC)
+	if len(trends) != 2 || trends[0].LogID != "api" {
+		t.Fatalf("Trends() = %+v, want api then web", trends)
+	}
+
+	web := trends[1]
+	if len(web.Days) != 3 {
+		t.Fatalf("web days = %+v, want 3", web.Days)
+	}
+	if d := web.Days[0]; d.Date != "2024-01-01" || d.Runs != 2 || d.Failures != 1 || d.Errors != 5 || d.Warnings != 1 {
+		t.Errorf("web day 1 = %+v", d)
+	}
+	if web.Days[0].ErrorRate() != 0.05 {
+		t.Errorf("web day 1 error rate = %v, want 0.05", web.Days[0].ErrorRate())
+	}
+	if web.Current != (Streak{Status: "OK", Runs: 2}) {
+		t.Errorf("web current streak = %+v, want 2 OK runs", web.Current)
+	}
+	if web.LongestFailure != 2 {
+		t.Errorf("web longest failure streak = %d, want 2", web.LongestFailure)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package history
+
+import "os"
+
+// lockFile does not lock on platforms without flock: concurrent processes
+// sharing a history file are not serialized there.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
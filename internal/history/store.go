@@ -0,0 +1,215 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"loganalyzer/internal/alert"
+	"loganalyzer/internal/reporter"
+)
+
+// Record is the outcome of one log in one analyze run, as stored in the
+// history file. Runs are identified by their start time.
+type Record struct {
+	Run       time.Time      `json:"run"`
+	LogID     string         `json:"log_id"`
+	Status    string         `json:"status"`
+	Lines     int            `json:"lines,omitempty"`
+	Levels    map[string]int `json:"levels,omitempty"`
+	ErrorRate float64        `json:"error_rate,omitempty"`
+	Alerts    int            `json:"alerts,omitempty"`
+	Anomalies int            `json:"anomalies,omitempty"`
+}
+
+// Errors returns the number of ERROR and FATAL entries of the record.
+func (r Record) Errors() int {
+	return r.Levels["ERROR"] + r.Levels["FATAL"]
+}
+
+// NewRecords converts the results of a run into history records. Skipped logs
+// are not recorded so they do not break success streaks.
+func NewRecords(run time.Time, results []reporter.AnalysisResult) []Record {
+	records := make([]Record, 0, len(results))
+	for _, result := range results {
+		if result.Status == "SKIPPED" {
+			continue
+		}
+
+		record := Record{
+			Run:       run.UTC(),
+			LogID:     result.LogID,
+			Status:    result.Status,
+			Alerts:    len(result.Alerts),
+			Anomalies: len(result.Anomalies),
+		}
+		if result.Stats != nil {
+			record.Lines = result.Stats.Lines
+			record.Levels = result.Stats.Levels
+			record.ErrorRate = alert.MetricValue(result.Stats, "error_rate")
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// Store is an append-only JSONL file of history records.
+type Store struct {
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the location of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds records to the end of the history file, creating it when
+// needed. The records are written with a single write so a run is never
+// interleaved with another one, under the lock of the store so a concurrent
+// Prune cannot drop them.
+func (s *Store) Append(records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return appendRecords(s.path, records)
+}
+
+// lock takes the exclusive lock of the store, held by Append and Prune in
+// every process, and returns the function releasing it. The lock is a
+// separate file because Prune replaces the history file.
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directories for %s: %w", s.path, err)
+	}
+	path := s.path + ".lock"
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history lock %s: %w", path, err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock history file %s: %w", s.path, err)
+	}
+	return func() {
+		unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+func appendRecords(path string, records []Record) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode history record: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directories for %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write history file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load returns the records accepted by keep (all records when keep is nil) in
+// file order. A missing file is an empty history. Lines that cannot be decoded,
+// such as one cut short by a crash, are skipped.
+func (s *Store) Load(keep func(Record) bool) ([]Record, error) {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if keep == nil || keep(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file %s: %w", s.path, err)
+	}
+
+	return records, nil
+}
+
+// Prune removes the records of runs before cutoff and returns how many were
+// removed. The file is rewritten to a temporary file which then replaces it,
+// under the lock of the store so records appended meanwhile are kept. It is
+// left as is when no record is older than cutoff.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	all, err := s.Load(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]Record, 0, len(all))
+	for _, record := range all {
+		if !record.Run.Before(cutoff) {
+			kept = append(kept, record)
+		}
+	}
+	removed := len(all) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("failed to remove stale %s: %w", tmp, err)
+	}
+	if err := appendRecords(tmp, kept); err != nil {
+		return 0, err
+	}
+	if len(kept) == 0 {
+		if err := os.WriteFile(tmp, nil, 0644); err != nil {
+			return 0, fmt.Errorf("failed to write history file %s: %w", tmp, err)
+		}
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return 0, fmt.Errorf("failed to replace history file %s: %w", s.path, err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,97 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Day aggregates the records of one log over a calendar day.
+type Day struct {
+	Date     string `json:"date"`
+	Runs     int    `json:"runs"`
+	Failures int    `json:"failures"`
+	Lines    int    `json:"lines"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Alerts   int    `json:"alerts"`
+}
+
+// ErrorRate returns the share of ERROR and FATAL entries over the day.
+func (d Day) ErrorRate() float64 {
+	if d.Lines == 0 {
+		return 0
+	}
+	return float64(d.Errors) / float64(d.Lines)
+}
+
+// Streak is a run of consecutive analyze runs with the same outcome.
+type Streak struct {
+	Status string `json:"status"`
+	Runs   int    `json:"runs"`
+}
+
+// Trend summarizes the history of one log.
+type Trend struct {
+	LogID          string `json:"log_id"`
+	Days           []Day  `json:"days"`
+	Current        Streak `json:"current_streak"`
+	LongestFailure int    `json:"longest_failure_streak"`
+}
+
+// Trends groups the records by log and builds each log's daily aggregates, in
+// the given time zone, and streaks. Logs are sorted by ID.
+func Trends(records []Record, loc *time.Location) []Trend {
+	byLog := make(map[string][]Record)
+	for _, record := range records {
+		byLog[record.LogID] = append(byLog[record.LogID], record)
+	}
+
+	ids := make([]string, 0, len(byLog))
+	for id := range byLog {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	trends := make([]Trend, 0, len(ids))
+	for _, id := range ids {
+		trends = append(trends, trendOf(id, byLog[id], loc))
+	}
+	return trends
+}
+
+func trendOf(logID string, records []Record, loc *time.Location) Trend {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Run.Before(records[j].Run)
+	})
+
+	trend := Trend{LogID: logID}
+	failures := 0
+	for _, record := range records {
+		date := record.Run.In(loc).Format("2006-01-02")
+		if len(trend.Days) == 0 || trend.Days[len(trend.Days)-1].Date != date {
+			trend.Days = append(trend.Days, Day{Date: date})
+		}
+		day := &trend.Days[len(trend.Days)-1]
+		day.Runs++
+		day.Lines += record.Lines
+		day.Errors += record.Errors()
+		day.Warnings += record.Levels["WARN"]
+		day.Alerts += record.Alerts
+
+		if record.Status == "FAILURE" {
+			day.Failures++
+			failures++
+			trend.LongestFailure = max(trend.LongestFailure, failures)
+		} else {
+			failures = 0
+		}
+
+		if trend.Current.Status == record.Status {
+			trend.Current.Runs++
+		} else {
+			trend.Current = Streak{Status: record.Status, Runs: 1}
+		}
+	}
+
+	return trend
+}
@@ -0,0 +1,223 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/reporter"
+)
+
+// MaxMessageSize bounds a syslog message. Longer messages are truncated over
+// TCP and cut by the datagram size over UDP.
+const MaxMessageSize = 64 * 1024
+
+// maxOctetCountDigits is the number of digits of the longest valid frame
+// length of the octet-counting framing.
+const maxOctetCountDigits = len("65536")
+
+// Receiver accepts syslog messages over UDP and TCP and aggregates them as a
+// single log. It is safe for concurrent use: messages from every connection
+// are added under one lock.
+type Receiver struct {
+	mu         sync.Mutex
+	aggregator *parser.Aggregator
+	messages   int
+	// onError receives the errors of connections, which do not stop the
+	// receiver.
+	onError func(error)
+}
+
+// NewReceiver returns a receiver adding the messages to aggregator.
+func NewReceiver(aggregator *parser.Aggregator) *Receiver {
+	return &Receiver{aggregator: aggregator, onError: func(error) {}}
+}
+
+// OnError sets the function called with the errors of single connections.
+func (r *Receiver) OnError(fn func(error)) {
+	r.onError = fn
+}
+
+// Add aggregates one message.
+func (r *Receiver) Add(message []byte) {
+	message = bytes.TrimRight(message, "\r\n\x00")
+	if len(message) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages++
+	r.aggregator.AddLine(string(message))
+}
+
+// Messages returns the number of messages received so far.
+func (r *Receiver) Messages() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages
+}
+
+// Snapshot returns the result of the messages received so far, with the
+// anomalies detected and the alerts fired.
+func (r *Receiver) Snapshot() reporter.AnalysisResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.aggregator.Snapshot()
+}
+
+// ServeUDP reads one message per datagram until ctx is done.
+func (r *Receiver) ServeUDP(ctx context.Context, conn net.PacketConn) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	buf := make([]byte, MaxMessageSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if n > 0 {
+			r.Add(buf[:n])
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// ServeTCP accepts connections until ctx is done. A TLS listener from
+// tls.NewListener receives syslog over TLS (RFC 5425).
+func (r *Receiver) ServeTCP(ctx context.Context, listener net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			closeConn := context.AfterFunc(ctx, func() { conn.Close() })
+			defer closeConn()
+			defer conn.Close()
+
+			if err := ReadFrames(conn, r.Add); err != nil && ctx.Err() == nil {
+				r.onError(fmt.Errorf("connection from %s: %w", conn.RemoteAddr(), err))
+			}
+		}()
+	}
+}
+
+// ReadFrames splits a syslog stream into messages and passes them to fn,
+// until the end of the stream. Each frame is either octet-counted
+// ("LEN SP MSG") or terminated by a newline (RFC 6587); senders may mix both.
+func ReadFrames(conn io.Reader, fn func([]byte)) error {
+	reader := bufio.NewReaderSize(conn, MaxMessageSize)
+
+	for {
+		length, ok, err := octetCount(reader)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if ok {
+			message := make([]byte, length)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				if err == io.ErrUnexpectedEOF {
+					return fmt.Errorf("connection closed in a %d byte frame", length)
+				}
+				return err
+			}
+			fn(message)
+			continue
+		}
+
+		message, err := readLine(reader)
+		if len(message) > 0 {
+			fn(message)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// octetCount consumes the "LEN SP" header of an octet-counted frame. It
+// reports false, consuming nothing, when the next frame is newline-terminated:
+// it does not start with a non-zero digit, or the digits are not followed by
+// a space, as in a message starting with a timestamp.
+func octetCount(reader *bufio.Reader) (int, bool, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return 0, false, err
+	}
+	if first[0] < '1' || first[0] > '9' {
+		return 0, false, nil
+	}
+
+	// Peek one byte at a time, so a short frame is not held back waiting
+	// for the bytes of the next one.
+	space := -1
+	for i := 1; i <= maxOctetCountDigits && space < 0; i++ {
+		head, err := reader.Peek(i + 1)
+		if err != nil {
+			return 0, false, nil
+		}
+		switch c := head[i]; {
+		case c == ' ':
+			space = i
+		case c < '0' || c > '9':
+			return 0, false, nil
+		}
+	}
+	if space < 0 {
+		return 0, false, nil
+	}
+	head, _ := reader.Peek(space)
+	length, err := strconv.Atoi(string(head))
+	if err != nil || length > MaxMessageSize {
+		return 0, false, nil
+	}
+
+	reader.Discard(space + 1)
+	return length, true, nil
+}
+
+// readLine returns the next newline-terminated message, truncated to
+// MaxMessageSize.
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	line, err := reader.ReadSlice('\n')
+	message := append([]byte(nil), line...)
+	for err == bufio.ErrBufferFull {
+		// Drop the rest of an oversized message.
+		_, err = reader.ReadSlice('\n')
+	}
+	return message, err
+}
@@ -0,0 +1,218 @@
+package listener
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+)
+
+func TestReadFrames(t *testing.T) {
+	tests := []struct {
+		name      string
+		stream    string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "Newline framing",
+			stream:   "<11>Mar  9 22:14:15 host sshd[1]: one\n<14>Mar  9 22:14:16 host sshd[1]: two\r\n",
+			expected: []string{"<11>Mar  9 22:14:15 host sshd[1]: one\n", "<14>Mar  9 22:14:16 host sshd[1]: two\r\n"},
+		},
+		{
+			name:     "Octet counting",
+			stream:   "9 <11>a\nb c5 <14>d",
+			expected: []string{"<11>a\nb c", "<14>d"},
+		},
+		{
+			name:     "Mixed framing",
+			stream:   "5 <11>a<14>b\n3 xyz",
+			expected: []string{"<11>a", "<14>b\n", "xyz"},
+		},
+		{
+			name:     "Leading timestamp is not a length",
+			stream:   "2024-03-09T22:14:15Z host app: started\n",
+			expected: []string{"2024-03-09T22:14:15Z host app: started\n"},
+		},
+		{
+			name:     "Last message without newline",
+			stream:   "<11>last",
+			expected: []string{"<11>last"},
+		},
+		{
+			name:     "Oversized message is truncated",
+			stream:   "<11>" + strings.Repeat("x", MaxMessageSize) + "\n<14>next\n",
+			expected: []string{"<11>" + strings.Repeat("x", MaxMessageSize-4), "<14>next\n"},
+		},
+		{
+			name:      "Truncated frame",
+			stream:    "20 <11>short",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := ReadFrames(strings.NewReader(tt.stream), func(message []byte) {
+				got = append(got, string(message))
+			})
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ReadFrames() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if tt.expectErr {
+				return
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("ReadFrames() messages = %d, want %d", len(got), len(tt.expected))
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("message %d = %.40q, want %.40q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func newTestReceiver(t *testing.T) *Receiver {
+	t.Helper()
+	analyzer := parser.NewAnalyzer(&config.Config{})
+	return NewReceiver(analyzer.NewAggregator(config.LogConfig{ID: "syslog", Path: "syslog", Type: "syslog"}))
+}
+
+// waitForMessages polls the receiver until it received n messages.
+func waitForMessages(t *testing.T, r *Receiver, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Messages() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d messages, want %d", r.Messages(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receiver := newTestReceiver(t)
+
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	done := make(chan error, 2)
+	go func() { done <- receiver.ServeUDP(ctx, udp) }()
+	go func() { done <- receiver.ServeTCP(ctx, tcp) }()
+
+	udpConn, err := net.Dial("udp", udp.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Dial(udp) error = %v", err)
+	}
+	defer udpConn.Close()
+	fmt.Fprint(udpConn, "<11>Mar  9 22:14:15 router1 sshd[42]: Failed password for root\n")
+
+	tcpConn, err := net.Dial("tcp", tcp.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial(tcp) error = %v", err)
+	}
+	fmt.Fprint(tcpConn, "<14>Mar  9 22:14:16 router1 cron[7]: job started\n")
+	message := "<12>Mar  9 22:14:17 router2 kernel: link down"
+	fmt.Fprintf(tcpConn, "%d %s", len(message), message)
+	tcpConn.Close()
+
+	waitForMessages(t, receiver, 3)
+
+	result := receiver.Snapshot()
+	if result.Status != "OK" || result.Stats == nil {
+		t.Fatalf("Snapshot() = %+v, want an OK result with stats", result)
+	}
+	stats := result.Stats
+	if stats.Lines != 3 || stats.ParseErrors != 0 {
+		t.Errorf("lines = %d, parse errors = %d, want 3 and 0", stats.Lines, stats.ParseErrors)
+	}
+	if stats.Levels["ERROR"] != 1 || stats.Levels["WARN"] != 1 || stats.Levels["INFO"] != 1 {
+		t.Errorf("levels = %v", stats.Levels)
+	}
+
+	cancel()
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	}
+}
+
+func TestReceiverTLS(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receiver := newTestReceiver(t)
+
+	cert, pool := selfSignedCert(t)
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	l := tls.NewListener(tcp, &tls.Config{Certificates: []tls.Certificate{cert}})
+	go receiver.ServeTCP(ctx, l)
+
+	conn, err := tls.Dial("tcp", tcp.Addr().String(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("tls.Dial() error = %v", err)
+	}
+	message := "<11>Mar  9 22:14:15 fw1 pf: blocked"
+	fmt.Fprintf(conn, "%d %s", len(message), message)
+	conn.Close()
+
+	waitForMessages(t, receiver, 1)
+	if levels := receiver.Snapshot().Stats.Levels; levels["ERROR"] != 1 {
+		t.Errorf("levels = %v, want 1 ERROR", levels)
+	}
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
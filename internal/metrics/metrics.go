@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+// Collector turns analysis results into metrics in the Prometheus text
+// exposition format. Gauges describe the latest analysis of each log;
+// counters accumulate over every analysis observed since startup.
+type Collector struct {
+	mu sync.Mutex
+
+	latest   map[string]logSample
+	failures map[failureKey]int
+	runs     int
+	runErrs  int
+	lastRun  time.Time
+	lastTook time.Duration
+}
+
+type logSample struct {
+	logType string
+	result  reporter.AnalysisResult
+}
+
+type failureKey struct {
+	logID   string
+	logType string
+	reason  string
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		latest:   make(map[string]logSample),
+		failures: make(map[failureKey]int),
+	}
+}
+
+// Observe records the results of an analysis of cfg that took the given time.
+// Skipped logs keep the values of their previous analysis, and the series of
+// logs no longer in cfg, e.g. after a reload, are dropped.
+func (c *Collector) Observe(cfg *config.Config, results []reporter.AnalysisResult, finished time.Time, took time.Duration) {
+	types := make(map[string]string, len(cfg.Logs))
+	for _, log := range cfg.Logs {
+		types[log.ID] = log.Type
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.runs++
+	c.lastRun = finished
+	c.lastTook = took
+
+	for id := range c.latest {
+		if _, ok := types[id]; !ok {
+			delete(c.latest, id)
+		}
+	}
+	for key := range c.failures {
+		if _, ok := types[key.logID]; !ok {
+			delete(c.failures, key)
+		}
+	}
+
+	for _, result := range results {
+		if result.Status == "SKIPPED" {
+			continue
+		}
+		logType := types[result.LogID]
+		c.latest[result.LogID] = logSample{logType: logType, result: result}
+		if result.Status == "FAILURE" {
+			c.failures[failureKey{logID: result.LogID, logType: logType, reason: result.Message}]++
+		}
+	}
+}
+
+// ObserveError records an analysis that could not run at all, such as one
+// with invalid alerting rules.
+func (c *Collector) ObserveError(finished time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runs++
+	c.runErrs++
+	c.lastRun = finished
+}
+
+// WriteTo writes every metric in the Prometheus text format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+
+	ids := make([]string, 0, len(c.latest))
+	for id := range c.latest {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	header(&b, "loganalyzer_log_lines", "gauge", "Lines read in the latest analysis of the log.")
+	for _, id := range ids {
+		s := c.latest[id]
+		if s.result.Stats != nil {
+			sample(&b, "loganalyzer_log_lines", labels(id, s.logType), float64(s.result.Stats.Lines))
+		}
+	}
+
+	header(&b, "loganalyzer_log_entries", "gauge", "Entries per level in the latest analysis of the log.")
+	for _, id := range ids {
+		s := c.latest[id]
+		if s.result.Stats == nil {
+			continue
+		}
+		for _, level := range sortedKeys(s.result.Stats.Levels) {
+			sample(&b, "loganalyzer_log_entries", labels(id, s.logType, "level", level), float64(s.result.Stats.Levels[level]))
+		}
+	}
+
+	header(&b, "loganalyzer_log_parse_errors", "gauge", "Lines that could not be parsed in the latest analysis of the log.")
+	for _, id := range ids {
+		s := c.latest[id]
+		if s.result.Stats != nil {
+			sample(&b, "loganalyzer_log_parse_errors", labels(id, s.logType), float64(s.result.Stats.ParseErrors))
+		}
+	}
+
+	header(&b, "loganalyzer_log_up", "gauge", "Whether the latest analysis of the log succeeded (1) or failed (0).")
+	for _, id := range ids {
+		s := c.latest[id]
+		up := 0.0
+		if s.result.Status != "FAILURE" {
+			up = 1
+		}
+		sample(&b, "loganalyzer_log_up", labels(id, s.logType), up)
+	}
+
+	header(&b, "loganalyzer_log_analysis_duration_seconds", "gauge", "Time spent on the latest analysis of the log.")
+	for _, id := range ids {
+		s := c.latest[id]
+		sample(&b, "loganalyzer_log_analysis_duration_seconds", labels(id, s.logType), s.result.DurationSeconds)
+	}
+
+	header(&b, "loganalyzer_log_failures_total", "counter", "Failed analyses of the log by reason, e.g. \"File not found.\".")
+	keys := make([]failureKey, 0, len(c.failures))
+	for k := range c.failures {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].logID != keys[j].logID {
+			return keys[i].logID < keys[j].logID
+		}
+		return keys[i].reason < keys[j].reason
+	})
+	for _, k := range keys {
+		sample(&b, "loganalyzer_log_failures_total", labels(k.logID, k.logType, "reason", k.reason), float64(c.failures[k]))
+	}
+
+	header(&b, "loganalyzer_analysis_runs_total", "counter", "Analyses run since startup.")
+	sample(&b, "loganalyzer_analysis_runs_total", "", float64(c.runs))
+	header(&b, "loganalyzer_analysis_errors_total", "counter", "Analyses that could not run since startup.")
+	sample(&b, "loganalyzer_analysis_errors_total", "", float64(c.runErrs))
+	if !c.lastRun.IsZero() {
+		header(&b, "loganalyzer_last_analysis_timestamp_seconds", "gauge", "Unix time of the end of the latest analysis.")
+		sample(&b, "loganalyzer_last_analysis_timestamp_seconds", "", float64(c.lastRun.UnixMilli())/1000)
+		header(&b, "loganalyzer_last_analysis_duration_seconds", "gauge", "Duration of the latest analysis of all logs.")
+		sample(&b, "loganalyzer_last_analysis_duration_seconds", "", c.lastTook.Seconds())
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func header(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, kind)
+}
+
+func sample(b *strings.Builder, name, labels string, value float64) {
+	fmt.Fprintf(b, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labels formats the log_id and type labels followed by extra name/value
+// pairs.
+func labels(logID, logType string, extra ...string) string {
+	pairs := append([]string{"log_id", logID, "type", logType}, extra...)
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", pairs[i], escapeLabel(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(value string) string {
+	return helpEscaper.Replace(value)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+func TestCollector(t *testing.T) {
+	cfg := &config.Config{Logs: []config.LogConfig{
+		{ID: "web", Path: "/var/log/web.log", Type: "nginx access"},
+		{ID: "db", Path: "/var/log/db.log", Type: "postgres"},
+		{ID: "old", Path: "/var/log/old.log", Type: "legacy"},
+	}}
+
+	web := reporter.CreateSuccessResult("web", "/var/log/web.log")
+	web.Stats = reporter.NewLogStats()
+	web.Stats.Lines = 10
+	web.Stats.ParseErrors = 1
+	web.Stats.Levels["ERROR"] = 2
+	web.Stats.Levels["INFO"] = 8
+	web.DurationSeconds = 0.25
+
+	results := []reporter.AnalysisResult{
+		web,
+		reporter.CreateFailureResult("db", "/var/log/db.log", "File not found.", "missing"),
+		reporter.CreateSkippedResult("old", "/var/log/old.log", "disabled in configuration"),
+	}
+
+	c := NewCollector()
+	finished := time.Unix(1700000000, 0)
+	c.Observe(cfg, results, finished, 2*time.Second)
+	c.Observe(cfg, results, finished, 2*time.Second)
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE loganalyzer_log_lines gauge\n",
+		`loganalyzer_log_lines{log_id="web",type="nginx access"} 10`,
+		`loganalyzer_log_entries{log_id="web",type="nginx access",level="ERROR"} 2`,
+		`loganalyzer_log_parse_errors{log_id="web",type="nginx access"} 1`,
+		`loganalyzer_log_up{log_id="db",type="postgres"} 0`,
+		`loganalyzer_log_analysis_duration_seconds{log_id="web",type="nginx access"} 0.25`,
+		`loganalyzer_log_failures_total{log_id="db",type="postgres",reason="File not found."} 2`,
+		"loganalyzer_analysis_runs_total 2\n",
+		"loganalyzer_last_analysis_timestamp_seconds 1.7e+09\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteTo() output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `log_id="old"`) {
+		t.Errorf("WriteTo() output includes the skipped log:\n%s", out)
+	}
+}
+
+func TestCollectorRemovedLog(t *testing.T) {
+	cfg := &config.Config{Logs: []config.LogConfig{
+		{ID: "web", Path: "/var/log/web.log", Type: "nginx access"},
+		{ID: "db", Path: "/var/log/db.log", Type: "postgres"},
+	}}
+	web := reporter.CreateSuccessResult("web", "/var/log/web.log")
+	web.Stats = reporter.NewLogStats()
+	db := reporter.CreateFailureResult("db", "/var/log/db.log", "File not found.", "missing")
+
+	c := NewCollector()
+	c.Observe(cfg, []reporter.AnalysisResult{web, db}, time.Now(), time.Second)
+
+	// The configuration is reloaded without the db log.
+	reloaded := &config.Config{Logs: cfg.Logs[:1]}
+	c.Observe(reloaded, []reporter.AnalysisResult{web}, time.Now(), time.Second)
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if out := b.String(); strings.Contains(out, `log_id="db"`) || !strings.Contains(out, `log_id="web"`) {
+		t.Errorf("WriteTo() output after the db log was removed:\n%s", out)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escapeLabel() = %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+// Package multiline assembles the lines of log entries that span several
+// lines, such as stack traces, before they are parsed, and recognizes the
+// stack traces of Java, Python and Go in the assembled entries.
+package multiline
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxLines bounds the lines of an entry when the rule sets no limit,
+// so a log whose lines all match a continuation pattern is not read as one
+// entry.
+const DefaultMaxLines = 500
+
+// presets are the continuation patterns of the stack traces of common
+// runtimes, for entries whose first line is a regular log line.
+var presets = map[string][]string{
+	"java": {
+		`^\s+at\s`,
+		`^\s+\.\.\. \d+ (?:more|common frames omitted)`,
+		`^\s*Caused by:`,
+		`^\s*Suppressed:`,
+		`^(?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable)\b`,
+	},
+	"python": {
+		`^Traceback \(most recent call last\):`,
+		`^\s+`,
+		`^$`,
+		`^During handling of the above exception, another exception occurred:`,
+		`^The above exception was the direct cause of the following exception:`,
+		`^(?:[a-zA-Z_]\w*\.)*[A-Z]\w*(?:Error|Exception|Exit|Interrupt|Warning)\b`,
+	},
+	"go": {
+		`^$`,
+		`^goroutine \d+ \[`,
+		`^\t`,
+		`^\[signal `,
+		`^created by `,
+		`^[\w./*()-]+\(.*\)$`,
+		`^exit status \d+$`,
+	},
+}
+
+// Presets returns the names of the built-in rules.
+func Presets() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rule decides which lines continue the current entry. A line continues it
+// when it matches one of the continuation patterns or, when the rule has a
+// start pattern, when it does not match the start pattern.
+type Rule struct {
+	start        *regexp.Regexp
+	continuation []*regexp.Regexp
+	maxLines     int
+}
+
+// New compiles a rule from a preset, whose continuation patterns are used in
+// addition to the given ones, a start pattern and continuation patterns. At
+// least one of them is required. maxLines bounds the lines of an entry,
+// DefaultMaxLines when 0.
+func New(preset, start string, continuation []string, maxLines int) (*Rule, error) {
+	if preset == "" && start == "" && len(continuation) == 0 {
+		return nil, fmt.Errorf("a preset, a start pattern or continuation patterns are required")
+	}
+	if maxLines < 0 {
+		return nil, fmt.Errorf("invalid max_lines %d", maxLines)
+	}
+
+	r := &Rule{maxLines: maxLines}
+	if r.maxLines == 0 {
+		r.maxLines = DefaultMaxLines
+	}
+
+	patterns := continuation
+	if preset != "" {
+		presetPatterns, ok := presets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q (use %s)", preset, strings.Join(Presets(), ", "))
+		}
+		patterns = append(append([]string{}, presetPatterns...), continuation...)
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continuation pattern %q: %w", pattern, err)
+		}
+		r.continuation = append(r.continuation, re)
+	}
+
+	if start != "" {
+		re, err := regexp.Compile(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start pattern %q: %w", start, err)
+		}
+		r.start = re
+	}
+
+	return r, nil
+}
+
+// Continues reports whether line continues the entry of the previous lines.
+func (r *Rule) Continues(line string) bool {
+	for _, re := range r.continuation {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return r.start != nil && !r.start.MatchString(line)
+}
+
+// Split is a bufio.SplitFunc returning the lines of an entry as one token,
+// joined by newlines. Trailing empty lines of an entry are dropped.
+func (r *Rule) Split(data []byte, atEOF bool) (int, []byte, error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	first := bytes.IndexByte(data, '\n')
+	if first < 0 {
+		if atEOF {
+			return len(data), trimEntry(data), nil
+		}
+		return 0, nil, nil
+	}
+
+	end := first + 1
+	for lines := 1; lines < r.maxLines; lines++ {
+		i := bytes.IndexByte(data[end:], '\n')
+		if i < 0 {
+			if !atEOF {
+				return 0, nil, nil
+			}
+			if end < len(data) && r.Continues(string(bytes.TrimSuffix(data[end:], []byte("\r")))) {
+				return len(data), trimEntry(data), nil
+			}
+			break
+		}
+		line := bytes.TrimSuffix(data[end:end+i], []byte("\r"))
+		if !r.Continues(string(line)) {
+			break
+		}
+		end += i + 1
+	}
+
+	return end, trimEntry(data[:end]), nil
+}
+
+// trimEntry drops the line terminators at the end of an entry.
+func trimEntry(data []byte) []byte {
+	return bytes.TrimRight(data, "\r\n")
+}
+
+var (
+	javaFrame     = regexp.MustCompile(`\n\s+at\s`)
+	javaException = regexp.MustCompile(`(?m)^(?:\s*Caused by: )?((?:[a-zA-Z_$][\w$]*\.)+[A-Z][\w$]*(?:Exception|Error|Throwable))\b`)
+	pythonHeader  = "Traceback (most recent call last):"
+	pythonError   = regexp.MustCompile(`^((?:[a-zA-Z_]\w*\.)*[A-Z]\w*)(?::|$)`)
+	goGoroutine   = regexp.MustCompile(`\ngoroutine \d+ \[`)
+	goPanic       = regexp.MustCompile(`(?m)^(panic|fatal error): (.*)$`)
+	goDetails     = regexp.MustCompile(`\s*\[.*$`)
+)
+
+// StackTrace reports whether an assembled entry contains a stack trace, and
+// returns the type of its exception: the outermost exception class for Java,
+// the exception raised last for Python, and for Go "panic", the runtime error
+// of a runtime panic or the message of a fatal error. The type is empty when
+// the trace does not name one.
+func StackTrace(text string) (string, bool) {
+	if strings.IndexByte(text, '\n') < 0 {
+		return "", false
+	}
+
+	if i := strings.Index(text, pythonHeader); i >= 0 {
+		kind := ""
+		for _, line := range strings.Split(text[i+len(pythonHeader):], "\n") {
+			if m := pythonError.FindStringSubmatch(line); m != nil {
+				kind = m[1]
+			}
+		}
+		return kind, true
+	}
+
+	if goGoroutine.MatchString(text) {
+		m := goPanic.FindStringSubmatch(text)
+		switch {
+		case m == nil:
+			return "", true
+		case m[1] == "fatal error":
+			return "fatal error: " + m[2], true
+		case strings.HasPrefix(m[2], "runtime error: "):
+			return goDetails.ReplaceAllString(m[2], ""), true
+		}
+		return "panic", true
+	}
+
+	if javaFrame.MatchString(text) {
+		if m := javaException.FindStringSubmatch(text); m != nil {
+			return m[1], true
+		}
+		return "", true
+	}
+
+	return "", false
+}
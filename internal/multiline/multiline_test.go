@@ -0,0 +1,189 @@
+package multiline
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func assemble(t *testing.T, rule *Rule, input string) []string {
+	t.Helper()
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	// A tiny buffer makes the splitter ask for more data mid-entry.
+	scanner.Buffer(make([]byte, 0, 4), 4096)
+	scanner.Split(rule.Split)
+
+	var entries []string
+	for scanner.Scan() {
+		entries = append(entries, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	return entries
+}
+
+const (
+	javaLog = "2024-05-24 10:00:00 INFO Started\n" +
+		"2024-05-24 10:00:01 ERROR Request failed\n" +
+		"java.lang.IllegalStateException: boom\n" +
+		"\tat com.example.Service.run(Service.java:42)\n" +
+		"\tat com.example.Main.main(Main.java:7)\n" +
+		"Caused by: java.io.IOException: disk full\n" +
+		"\tat com.example.Disk.write(Disk.java:9)\n" +
+		"\t... 2 more\n" +
+		"2024-05-24 10:00:02 INFO Done\n"
+
+	pythonLog = "2024-05-24 10:00:00 ERROR Job failed\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"job.py\", line 3, in <module>\n" +
+		"    run()\n" +
+		"KeyError: 'id'\n" +
+		"\n" +
+		"During handling of the above exception, another exception occurred:\n" +
+		"\n" +
+		"Traceback (most recent call last):\n" +
+		"  File \"job.py\", line 5, in <module>\n" +
+		"app.errors.JobError: cannot run\n" +
+		"2024-05-24 10:00:01 INFO Next job\n"
+
+	goLog = "2024/05/24 10:00:00 serving on :8080\n" +
+		"panic: runtime error: index out of range [5] with length 3\n" +
+		"\n" +
+		"goroutine 1 [running]:\n" +
+		"main.handler(0xc000010000)\n" +
+		"\t/src/main.go:12 +0x1d\n" +
+		"net/http.(*conn).serve(0xc000020000)\n" +
+		"\t/usr/local/go/src/net/http/server.go:2039 +0x3a\n" +
+		"created by net/http.(*Server).Serve in goroutine 1\n" +
+		"exit status 2\n"
+)
+
+func TestRuleSplitPresets(t *testing.T) {
+	tests := []struct {
+		preset string
+		input  string
+		want   []string
+	}{
+		{
+			preset: "java",
+			input:  javaLog,
+			want: []string{
+				"2024-05-24 10:00:00 INFO Started",
+				strings.Join(strings.Split(javaLog, "\n")[1:8], "\n"),
+				"2024-05-24 10:00:02 INFO Done",
+			},
+		},
+		{
+			preset: "python",
+			input:  pythonLog,
+			want: []string{
+				strings.Join(strings.Split(pythonLog, "\n")[:11], "\n"),
+				"2024-05-24 10:00:01 INFO Next job",
+			},
+		},
+		{
+			preset: "go",
+			input:  goLog,
+			want: []string{
+				"2024/05/24 10:00:00 serving on :8080",
+				strings.TrimSuffix(goLog[strings.Index(goLog, "panic"):], "\n"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			rule, err := New(tt.preset, "", nil, 0)
+			if err != nil {
+				t.Fatalf("New() unexpected error: %v", err)
+			}
+			got := assemble(t, rule, tt.input)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSplitStartAndContinuation(t *testing.T) {
+	input := "[10:00] first\n  indented\nnot a start\n[10:01] second\r\n> quoted\n[10:02] last\nwithout newline"
+
+	rule, err := New("", `^\[\d\d:\d\d\]`, nil, 0)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	want := []string{"[10:00] first\n  indented\nnot a start", "[10:01] second\r\n> quoted", "[10:02] last\nwithout newline"}
+	if got := assemble(t, rule, input); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("start entries = %q, want %q", got, want)
+	}
+
+	rule, err = New("", "", []string{`^\s`, `^>`}, 0)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	want = []string{"[10:00] first\n  indented", "not a start", "[10:01] second\r\n> quoted", "[10:02] last", "without newline"}
+	if got := assemble(t, rule, input); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("continuation entries = %q, want %q", got, want)
+	}
+
+	rule, err = New("", "", []string{`^\s`}, 2)
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	want = []string{"a\n b", " c", "d"}
+	if got := assemble(t, rule, "a\n b\n c\nd\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("max_lines entries = %q, want %q", got, want)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		preset       string
+		start        string
+		continuation []string
+		maxLines     int
+	}{
+		{name: "Empty"},
+		{name: "Unknown preset", preset: "ruby"},
+		{name: "Invalid start", start: "("},
+		{name: "Invalid continuation", continuation: []string{"["}},
+		{name: "Negative max_lines", preset: "java", maxLines: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.preset, tt.start, tt.continuation, tt.maxLines); err == nil {
+				t.Error("New() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		kind  string
+		trace bool
+	}{
+		{name: "Java", text: strings.Join(strings.Split(javaLog, "\n")[1:8], "\n"), kind: "java.lang.IllegalStateException", trace: true},
+		{name: "Python", text: strings.TrimSpace(pythonLog[:strings.Index(pythonLog, "2024-05-24 10:00:01")]), kind: "app.errors.JobError", trace: true},
+		{name: "Go runtime error", text: goLog[strings.Index(goLog, "panic"):], kind: "runtime error: index out of range", trace: true},
+		{name: "Go panic", text: "panic: boom\n\ngoroutine 7 [running]:\nmain.main()", kind: "panic", trace: true},
+		{name: "Go fatal error", text: "fatal error: concurrent map writes\n\ngoroutine 9 [running]:", kind: "fatal error: concurrent map writes", trace: true},
+		{name: "Java without exception line", text: "ERROR failed\n    at x.y(Z.java:1)", trace: true},
+		{name: "Single line", text: "ERROR java.lang.IllegalStateException: boom"},
+		{name: "Multi-line without trace", text: "first line\nsecond line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, ok := StackTrace(tt.text)
+			if ok != tt.trace || kind != tt.kind {
+				t.Errorf("StackTrace() = %q, %v, want %q, %v", kind, ok, tt.kind, tt.trace)
+			}
+		})
+	}
+}
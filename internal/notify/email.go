@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+const defaultSMTPPort = 25
+
+// smtpTimeout bounds the connection and the session with the relay when the
+// context has no deadline.
+const smtpTimeout = 30 * time.Second
+
+// emailSink sends the message as a plain text email through an SMTP relay.
+type emailSink struct {
+	smtp config.SMTPConfig
+}
+
+func (s *emailSink) addr() string {
+	port := s.smtp.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	return net.JoinHostPort(s.smtp.Host, strconv.Itoa(port))
+}
+
+func (s *emailSink) Payload(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.smtp.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Title)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Text(), "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
+
+// Send delivers the payload in an SMTP session bounded by the deadline of
+// ctx, or smtpTimeout without one. Canceling ctx closes the connection, so
+// no delivery is left running after Send returns.
+func (s *emailSink) Send(ctx context.Context, payload []byte) error {
+	if err := s.send(ctx, payload); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("smtp delivery failed: %w", err)
+	}
+	return nil
+}
+
+func (s *emailSink) send(ctx context.Context, payload []byte) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// The session of smtp.SendMail, on a connection with a deadline.
+	client, err := smtp.NewClient(conn, s.smtp.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.smtp.Host}); err != nil {
+			return err
+		}
+	}
+	if s.smtp.Username != "" {
+		auth := smtp.PlainAuth("", s.smtp.Username, s.smtp.SMTPPassword(), s.smtp.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.smtp.From); err != nil {
+		return err
+	}
+	for _, to := range s.smtp.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+// startSMTPStub accepts a single SMTP session and sends the DATA section on
+// the returned channel.
+func startSMTPStub(t *testing.T) (string, int, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 stub ready")
+
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 stub")
+			case strings.HasPrefix(command, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				messages <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(command, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, portStr, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	return host, port, messages
+}
+
+func TestNotifyEmail(t *testing.T) {
+	host, port, messages := startSMTPStub(t)
+
+	d, _ := newTestDispatcher(t, []config.NotifierConfig{{
+		Name: "oncall",
+		Type: "email",
+		SMTP: &config.SMTPConfig{
+			Host: host,
+			Port: port,
+			From: "loganalyzer@example.com",
+			To:   []string{"oncall@example.com"},
+		},
+	}})
+
+	if err := d.Notify(context.Background(), testResults()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	message := <-messages
+	if !strings.Contains(message, "Subject: LogAnalyzer: 1 failed log, 1 alert fired") {
+		t.Errorf("email missing subject: %s", message)
+	}
+	if !strings.Contains(message, "[db] /var/log/db.log: File not found.") {
+		t.Errorf("email missing failure details: %s", message)
+	}
+}
+
+func TestNotifyEmailStalledRelay(t *testing.T) {
+	// A relay that accepts the connection and never greets.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+	host, portStr, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	retries := 0
+	d, _ := newTestDispatcher(t, []config.NotifierConfig{{
+		Name:    "oncall",
+		Type:    "email",
+		Retries: &retries,
+		SMTP:    &config.SMTPConfig{Host: host, Port: port, From: "loganalyzer@example.com", To: []string{"oncall@example.com"}},
+	}})
+	d.timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	err = d.Notify(context.Background(), testResults())
+	if err == nil {
+		t.Error("Notify() expected a timeout error, got nil")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Notify() returned after %s, want the timeout to stop it", elapsed)
+	}
+}
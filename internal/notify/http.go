@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+// httpSink posts a JSON body to a webhook URL. Generic webhooks render the
+// body from a user template; Slack webhooks default to {"text": ...}.
+type httpSink struct {
+	url         string
+	headers     map[string]string
+	template    *template.Template
+	defaultBody func(Message) any
+	client      *http.Client
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func newHTTPSink(cfg config.NotifierConfig) (*httpSink, error) {
+	sink := &httpSink{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if cfg.Type == "slack" {
+		sink.defaultBody = func(m Message) any { return map[string]string{"text": m.Text()} }
+	} else {
+		sink.defaultBody = func(m Message) any { return m }
+	}
+
+	if cfg.Template != "" {
+		tmpl, err := template.New(cfg.Name).Funcs(templateFuncs).Parse(cfg.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		sink.template = tmpl
+	}
+
+	return sink, nil
+}
+
+func (s *httpSink) Payload(msg Message) ([]byte, error) {
+	if s.template == nil {
+		return json.Marshal(s.defaultBody(msg))
+	}
+
+	var buf bytes.Buffer
+	if err := s.template.Execute(&buf, msg); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template did not render valid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *httpSink) Send(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return permanent(fmt.Errorf("failed to build request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanent(err)
+	}
+	return err
+}
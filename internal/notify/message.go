@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+// AlertEvent is a fired alert together with the log it fired for.
+type AlertEvent struct {
+	LogID string `json:"log_id"`
+	reporter.Alert
+}
+
+// Message summarizes the failures and alerts of a run for the notifiers.
+type Message struct {
+	Title    string                    `json:"title"`
+	Total    int                       `json:"total"`
+	Failures []reporter.AnalysisResult `json:"failures"`
+	Alerts   []AlertEvent              `json:"alerts"`
+}
+
+// NewMessage collects the failed results and the fired alerts of a run.
+func NewMessage(results []reporter.AnalysisResult) Message {
+	msg := Message{
+		Total:    len(results),
+		Failures: []reporter.AnalysisResult{},
+		Alerts:   []AlertEvent{},
+	}
+
+	for _, result := range results {
+		if result.Status == "FAILURE" {
+			msg.Failures = append(msg.Failures, result)
+		}
+		for _, alert := range result.Alerts {
+			msg.Alerts = append(msg.Alerts, AlertEvent{LogID: result.LogID, Alert: alert})
+		}
+	}
+
+	msg.Title = msg.title()
+	return msg
+}
+
+// Empty reports whether there is nothing to notify about.
+func (m Message) Empty() bool {
+	return len(m.Failures) == 0 && len(m.Alerts) == 0
+}
+
+// Filter keeps only the parts of the message the given triggers subscribe to.
+func (m Message) Filter(triggers []string) Message {
+	filtered := Message{
+		Total:    m.Total,
+		Failures: []reporter.AnalysisResult{},
+		Alerts:   []AlertEvent{},
+	}
+
+	for _, trigger := range triggers {
+		switch trigger {
+		case config.NotifyOnFailure:
+			filtered.Failures = m.Failures
+		case config.NotifyOnAlert:
+			filtered.Alerts = m.Alerts
+		}
+	}
+
+	filtered.Title = filtered.title()
+	return filtered
+}
+
+func (m Message) title() string {
+	var parts []string
+	if n := len(m.Failures); n > 0 {
+		parts = append(parts, plural(n, "failed log", "failed logs"))
+	}
+	if n := len(m.Alerts); n > 0 {
+		parts = append(parts, plural(n, "alert fired", "alerts fired"))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("LogAnalyzer: %s analyzed, no issues", plural(m.Total, "log", "logs"))
+	}
+	return fmt.Sprintf("LogAnalyzer: %s (%s analyzed)", strings.Join(parts, ", "), plural(m.Total, "log", "logs"))
+}
+
+// Text renders the message as plain text, used for Slack and email bodies.
+func (m Message) Text() string {
+	var b strings.Builder
+	b.WriteString(m.Title)
+	b.WriteString("\n")
+
+	if len(m.Failures) > 0 {
+		b.WriteString("\nFailures:\n")
+		for _, f := range m.Failures {
+			fmt.Fprintf(&b, "- [%s] %s: %s", f.LogID, f.FilePath, f.Message)
+			if f.ErrorDetails != "" {
+				fmt.Fprintf(&b, " (%s)", f.ErrorDetails)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(m.Alerts) > 0 {
+		b.WriteString("\nAlerts:\n")
+		for _, a := range m.Alerts {
+			fmt.Fprintf(&b, "- %s [%s] %s: %s (%s = %g)\n",
+				strings.ToUpper(a.Severity), a.LogID, a.Rule, a.Message, a.Metric, a.Value)
+		}
+	}
+
+	return b.String()
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, pluralForm)
+}
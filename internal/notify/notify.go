@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+// Sink delivers a notification. Payload renders the message in the sink's
+// wire format and Send delivers a rendered payload, so dry runs can print the
+// exact payload without sending it.
+type Sink interface {
+	Payload(msg Message) ([]byte, error)
+	Send(ctx context.Context, payload []byte) error
+}
+
+// DefaultTimeout bounds the delivery to each notifier, retries included, so
+// a stalled relay or webhook cannot hang the run.
+const DefaultTimeout = time.Minute
+
+type notifier struct {
+	config config.NotifierConfig
+	sink   Sink
+}
+
+// Dispatcher sends the run summary to every configured notifier, retrying
+// failed deliveries with exponential backoff.
+type Dispatcher struct {
+	notifiers []notifier
+	dryRun    bool
+	out       io.Writer
+	// after waits before a retry; time.After, replaced in tests.
+	after   func(time.Duration) <-chan time.Time
+	timeout time.Duration
+}
+
+func NewDispatcher(cfgs []config.NotifierConfig) (*Dispatcher, error) {
+	d := &Dispatcher{
+		notifiers: make([]notifier, 0, len(cfgs)),
+		out:       os.Stdout,
+		after:     time.After,
+		timeout:   DefaultTimeout,
+	}
+
+	for _, cfg := range cfgs {
+		sink, err := newSink(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %s: %w", cfg.Name, err)
+		}
+		d.notifiers = append(d.notifiers, notifier{config: cfg, sink: sink})
+	}
+
+	return d, nil
+}
+
+func newSink(cfg config.NotifierConfig) (Sink, error) {
+	switch cfg.Type {
+	case "webhook", "slack":
+		return newHTTPSink(cfg)
+	case "email":
+		if cfg.SMTP == nil {
+			return nil, fmt.Errorf("missing smtp settings")
+		}
+		return &emailSink{smtp: *cfg.SMTP}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier type: %s", cfg.Type)
+}
+
+// SetDryRun makes Notify print the payloads instead of sending them.
+func (d *Dispatcher) SetDryRun(dryRun bool) {
+	d.dryRun = dryRun
+}
+
+// SetOutput sets where dry-run payloads and delivery messages are written.
+func (d *Dispatcher) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// Notify delivers the failures and alerts found in results. Notifiers whose
+// triggers match nothing in this run are not contacted. Delivery errors of all
+// notifiers are joined into the returned error.
+func (d *Dispatcher) Notify(ctx context.Context, results []reporter.AnalysisResult) error {
+	if d == nil || len(d.notifiers) == 0 {
+		return nil
+	}
+
+	msg := NewMessage(results)
+	if msg.Empty() {
+		return nil
+	}
+
+	var errs []error
+	for _, n := range d.notifiers {
+		filtered := msg.Filter(n.config.Triggers())
+		if filtered.Empty() {
+			continue
+		}
+
+		payload, err := n.sink.Payload(filtered)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notifier %s: %w", n.config.Name, err))
+			continue
+		}
+
+		if d.dryRun {
+			fmt.Fprintf(d.out, "--- Notification payload for %s (%s) ---\n%s\n", n.config.Name, n.config.Type, payload)
+			continue
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		err = d.send(sendCtx, n, payload)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notifier %s: %w", n.config.Name, err))
+			continue
+		}
+		fmt.Fprintf(d.out, "✓ Notification sent via %s\n", n.config.Name)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) send(ctx context.Context, n notifier, payload []byte) error {
+	delay := n.config.RetryInterval()
+	retries := n.config.RetryCount()
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-d.after(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			delay *= 2
+		}
+
+		err = n.sink.Send(ctx, payload)
+		if err == nil {
+			return nil
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", retries+1, err)
+}
+
+// permanentError marks a delivery error that retrying cannot fix, such as a
+// 4xx response from a webhook.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+func testResults() []reporter.AnalysisResult {
+	ok := reporter.CreateSuccessResult("web", "/var/log/web.log")
+	ok.Stats = reporter.NewLogStats()
+	ok.Alerts = []reporter.Alert{{Rule: "panics", Severity: "critical", Message: "Go panic", Metric: "pattern:panic:", Value: 2}}
+
+	return []reporter.AnalysisResult{
+		ok,
+		reporter.CreateFailureResult("db", "/var/log/db.log", "File not found.", "file not found or inaccessible: /var/log/db.log"),
+	}
+}
+
+func newTestDispatcher(t *testing.T, cfgs []config.NotifierConfig) (*Dispatcher, *bytes.Buffer) {
+	t.Helper()
+
+	d, err := NewDispatcher(cfgs)
+	if err != nil {
+		t.Fatalf("NewDispatcher() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	d.SetOutput(&out)
+	d.after = func(time.Duration) <-chan time.Time {
+		now := make(chan time.Time, 1)
+		now <- time.Now()
+		return now
+	}
+	return d, &out
+}
+
+func TestNotifyWebhookRetries(t *testing.T) {
+	var attempts int32
+	var received []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Token") != "secret" {
+			t.Errorf("missing custom header, got %q", r.Header.Get("X-Token"))
+		}
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	d, _ := newTestDispatcher(t, []config.NotifierConfig{{
+		Name:     "hook",
+		Type:     "webhook",
+		URL:      server.URL,
+		Headers:  map[string]string{"X-Token": "secret"},
+		Template: `{"summary": {{json .Title}}, "failed": {{len .Failures}}, "first_alert": {{json (index .Alerts 0).Rule}}}`,
+	}})
+
+	if err := d.Notify(context.Background(), testResults()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(received, &body); err != nil {
+		t.Fatalf("webhook body is not JSON: %v (%s)", err, received)
+	}
+	if body["failed"] != float64(1) || body["first_alert"] != "panics" {
+		t.Errorf("unexpected webhook body: %s", received)
+	}
+}
+
+func TestNotifyCanceledDuringBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	d, _ := newTestDispatcher(t, []config.NotifierConfig{{Name: "hook", Type: "webhook", URL: server.URL}})
+	ctx, cancel := context.WithCancel(context.Background())
+	// The backoff never ends by itself; canceling must stop it.
+	d.after = func(time.Duration) <-chan time.Time {
+		cancel()
+		return nil
+	}
+
+	if err := d.Notify(ctx, testResults()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() error = %v, want context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt before the cancellation, got %d", attempts)
+	}
+}
+
+func TestNotifyGivesUpOnClientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	d, _ := newTestDispatcher(t, []config.NotifierConfig{{Name: "slack", Type: "slack", URL: server.URL}})
+
+	if err := d.Notify(context.Background(), testResults()); err == nil {
+		t.Error("Notify() expected error for 404 response, got nil")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt for a 404, got %d", attempts)
+	}
+}
+
+func TestNotifyDryRun(t *testing.T) {
+	d, out := newTestDispatcher(t, []config.NotifierConfig{
+		{Name: "slack", Type: "slack", URL: "http://127.0.0.1:1/never"},
+		{Name: "alerts-only", Type: "webhook", URL: "http://127.0.0.1:1/never", On: []string{config.NotifyOnAlert}},
+	})
+	d.SetDryRun(true)
+
+	if err := d.Notify(context.Background(), testResults()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	output := out.String()
+	if !strings.Contains(output, "Notification payload for slack (slack)") {
+		t.Errorf("dry run did not print slack payload: %s", output)
+	}
+	if !strings.Contains(output, `"text":"LogAnalyzer: 1 failed log, 1 alert fired`) {
+		t.Errorf("dry run slack payload missing text: %s", output)
+	}
+
+	alertsOnly := output[strings.Index(output, "alerts-only"):]
+	if strings.Contains(alertsOnly, "File not found.") {
+		t.Errorf("alert-only notifier received failures: %s", alertsOnly)
+	}
+}
+
+func TestNewDispatcherInvalidTemplate(t *testing.T) {
+	_, err := NewDispatcher([]config.NotifierConfig{{Name: "hook", Type: "webhook", URL: "https://example.com", Template: "{{.Title"}})
+	if err == nil {
+		t.Error("NewDispatcher() expected error for invalid template, got nil")
+	}
+}
+
+func TestNotifyNothingToReport(t *testing.T) {
+	d, out := newTestDispatcher(t, []config.NotifierConfig{{Name: "slack", Type: "slack", URL: "http://127.0.0.1:1/never"}})
+
+	results := []reporter.AnalysisResult{reporter.CreateSuccessResult("web", "/var/log/web.log")}
+	if err := d.Notify(context.Background(), results); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Notify() wrote output for a clean run: %s", out.String())
+	}
+}
@@ -0,0 +1,163 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+var tokenNames = map[tokenKind]string{
+	tokenEOF:    "end of query",
+	tokenIdent:  "name",
+	tokenString: "string",
+	tokenNumber: "number",
+	tokenOp:     "operator",
+	tokenAnd:    "AND",
+	tokenOr:     "OR",
+	tokenNot:    "NOT",
+	tokenLParen: `"("`,
+	tokenRParen: `")"`,
+}
+
+func (k tokenKind) String() string {
+	return tokenNames[k]
+}
+
+// token is a lexeme of a query. Pos is the byte offset of its first
+// character, and Text its value: the unquoted content of a string, the
+// spelling of anything else.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF, tokenLParen, tokenRParen:
+		return t.kind.String()
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// operators are the comparison operators, longest first so "<=" is not read
+// as "<" followed by "=".
+var operators = []string{"==", "!=", "<=", ">=", "!~", "=~", "=", "<", ">", "~"}
+
+// lex splits a query into tokens, ending with a tokenEOF.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for {
+		for pos < len(src) {
+			r, size := utf8.DecodeRuneInString(src[pos:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			pos += size
+		}
+		if pos == len(src) {
+			return append(tokens, token{kind: tokenEOF, pos: pos}), nil
+		}
+
+		start := pos
+		c := src[pos]
+		switch {
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: start})
+			pos++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: start})
+			pos++
+		case c == '"' || c == '\'':
+			text, end, err := lexString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: start})
+			pos = end
+		case isWordByte(c):
+			end := pos + 1
+			for end < len(src) && isWordByte(src[end]) {
+				end++
+			}
+			word := src[pos:end]
+			kind := tokenIdent
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			default:
+				if isNumber(word) {
+					kind = tokenNumber
+				}
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: start})
+			pos = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[pos:])
+				return nil, &Error{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: start})
+			pos += len(op)
+		}
+	}
+}
+
+// lexString reads the string starting with the quote at src[pos] and
+// returns its unescaped content and the offset after the closing quote.
+// Backslash escapes the quote and the backslash; other escapes are kept, so
+// regular expressions such as "\d+" need no doubling.
+func lexString(src string, pos int) (string, int, error) {
+	quote := src[pos]
+	var b strings.Builder
+	for i := pos + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(src) && (src[i+1] == quote || src[i+1] == '\\'):
+			b.WriteByte(src[i+1])
+			i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, &Error{Pos: pos, Msg: "unterminated string"}
+}
+
+// isWordByte reports whether c can appear in a name or a bare value, such
+// as "http.status", "log-level", "2024-05-24T10:00:00Z" or "10.0.0.1".
+func isWordByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c == ':' || c == '/' || c == '@' || c == '+' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= utf8.RuneSelf
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex(`level>=WARN and (http.status != 404 OR path ~ "^/api\d+ \"v2\"") AND NOT ua=='bot'`)
+	if err != nil {
+		t.Fatalf("lex() unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.kind.String()+":"+tok.text)
+	}
+	want := []string{
+		"name:level", "operator:>=", "name:WARN", "AND:and", `"(":(`,
+		"name:http.status", "operator:!=", "number:404", "OR:OR",
+		"name:path", "operator:~", `string:^/api\d+ "v2"`, `")":)`,
+		"AND:AND", "NOT:NOT", "name:ua", "operator:==", "string:bot", "end of query:",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("lex() =\n%q\nwant\n%q", got, want)
+	}
+	if tokens[2].pos != 7 || tokens[len(tokens)-1].pos != 82 {
+		t.Errorf("lex() positions = %d and %d, want 7 and 82", tokens[2].pos, tokens[len(tokens)-1].pos)
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+	}{
+		{src: `path ~ "^/api`, pos: 7},
+		{src: `status > 500 & level = ERROR`, pos: 13},
+		{src: `status ! 500`, pos: 7},
+	}
+
+	for _, tt := range tests {
+		_, err := lex(tt.src)
+		var qerr *Error
+		if !errors.As(err, &qerr) || qerr.Pos != tt.pos {
+			t.Errorf("lex(%q) error = %v, want an error at %d", tt.src, err, tt.pos)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+// Package query implements the filter language of --where, which selects
+// parsed log entries, e.g.
+//
+//	level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"
+//
+// A query is made of comparisons "NAME OP VALUE" combined with AND, OR, NOT
+// and parentheses; AND binds tighter than OR. A bare NAME tests that the
+// field is set. Names are matched case-sensitively against the entry fields,
+// except for the entry properties level, message (msg), raw (line) and time.
+// Values are bare words, numbers or quoted strings.
+//
+// The operators are = (==), !=, <, <=, >, >=, ~ (=~) for a regular
+// expression match and !~. The level is compared in severity order and the
+// time as a time (RFC 3339, "2006-01-02 15:04:05" or "2006-01-02"). Other
+// fields are compared as numbers when the value is an unquoted number, and
+// fail the ordering comparisons if they are not numbers themselves, otherwise
+// as strings. A missing field is empty: it is not equal to any non-empty value
+// and fails every ordering comparison.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"loganalyzer/internal/entry"
+)
+
+// Error is a syntax error in a query, at byte offset Pos.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos+1, e.Msg)
+}
+
+// Pointer returns the query with a caret under the error position, to print
+// after the error message.
+func (e *Error) Pointer(src string) string {
+	return "  " + src + "\n  " + strings.Repeat(" ", len([]rune(src[:min(e.Pos, len(src))]))) + "^"
+}
+
+// Query is a compiled query. It is safe for concurrent use.
+type Query struct {
+	src  string
+	root node
+}
+
+// Parse compiles a query. Syntax errors are returned as *Error.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s, expected AND, OR or end of query", t.describe())}
+	}
+
+	return &Query{src: src, root: root}, nil
+}
+
+// String returns the source of the query.
+func (q *Query) String() string {
+	return q.src
+}
+
+// Match reports whether the entry satisfies the query. A nil Query matches
+// every entry.
+func (q *Query) Match(e entry.Entry) bool {
+	if q == nil {
+		return true
+	}
+	return q.root.match(e)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseOr parses "and (OR and)*".
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses "not (AND not)*".
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+// parseNot parses "NOT not | primary".
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses "( or )" or a comparison.
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &Error{Pos: closing.pos, Msg: fmt.Sprintf("expected \")\" to close the \"(\" at column %d, got %s", t.pos+1, closing.describe())}
+		}
+		return inner, nil
+	case tokenIdent:
+		return p.parseComparison(t)
+	case tokenEOF:
+		return nil, &Error{Pos: t.pos, Msg: "expected a condition, got end of query"}
+	}
+	return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("expected a field name, got %s", t.describe())}
+}
+
+// parseComparison parses the operator and value following a field name, if
+// any.
+func (p *parser) parseComparison(field token) (node, error) {
+	if p.peek().kind != tokenOp {
+		return existsNode{field: field.text}, nil
+	}
+	op := p.next()
+
+	value := p.next()
+	if value.kind != tokenIdent && value.kind != tokenString && value.kind != tokenNumber {
+		return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("expected a value after %q, got %s", op.text, value.describe())}
+	}
+
+	c := &comparison{field: field.text, op: op.text, value: value.text}
+	switch c.op {
+	case "==":
+		c.op = "="
+	case "=~":
+		c.op = "~"
+	}
+
+	switch {
+	case c.op == "~" || c.op == "!~":
+		re, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("invalid regular expression: %v", err)}
+		}
+		c.re = re
+	case isLevelField(c.field):
+		level, err := entry.ParseLevel(value.text)
+		if err != nil {
+			return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("unknown level %q (use DEBUG, INFO, WARN, ERROR or FATAL)", value.text)}
+		}
+		c.level = level
+	case isTimeField(c.field):
+		ts, ok := parseTime(value.text)
+		if !ok {
+			return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("invalid time %q (use RFC 3339, \"2006-01-02 15:04:05\" or \"2006-01-02\")", value.text)}
+		}
+		c.time = ts
+	default:
+		if value.kind == tokenNumber {
+			c.number, _ = strconv.ParseFloat(value.text, 64)
+			c.isNumber = true
+		}
+	}
+
+	return c, nil
+}
+
+type node interface {
+	match(e entry.Entry) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(e entry.Entry) bool { return n.left.match(e) && n.right.match(e) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(e entry.Entry) bool { return n.left.match(e) || n.right.match(e) }
+
+type notNode struct{ operand node }
+
+func (n notNode) match(e entry.Entry) bool { return !n.operand.match(e) }
+
+type existsNode struct{ field string }
+
+func (n existsNode) match(e entry.Entry) bool {
+	value, _ := Field(e, n.field)
+	return value != ""
+}
+
+type comparison struct {
+	field    string
+	op       string
+	value    string
+	re       *regexp.Regexp
+	level    entry.Level
+	time     time.Time
+	number   float64
+	isNumber bool
+}
+
+func (c *comparison) match(e entry.Entry) bool {
+	switch {
+	case c.re != nil:
+		value, _ := Field(e, c.field)
+		return c.re.MatchString(value) == (c.op == "~")
+	case isLevelField(c.field):
+		return compare(int(e.Level)-int(c.level), c.op)
+	case isTimeField(c.field):
+		if e.Time.IsZero() {
+			return c.op == "!="
+		}
+		return compare(e.Time.Compare(c.time), c.op)
+	}
+
+	value, ok := Field(e, c.field)
+	if c.isNumber {
+		if n, err := strconv.ParseFloat(value, 64); ok && err == nil {
+			switch {
+			case n < c.number:
+				return compare(-1, c.op)
+			case n > c.number:
+				return compare(1, c.op)
+			}
+			return compare(0, c.op)
+		}
+	}
+	switch c.op {
+	case "=":
+		return value == c.value
+	case "!=":
+		return value != c.value
+	}
+	if !ok || c.isNumber {
+		return false
+	}
+	return compare(strings.Compare(value, c.value), c.op)
+}
+
+// compare applies an operator to the sign of a comparison.
+func compare(sign int, op string) bool {
+	switch op {
+	case "=":
+		return sign == 0
+	case "!=":
+		return sign != 0
+	case "<":
+		return sign < 0
+	case "<=":
+		return sign <= 0
+	case ">":
+		return sign > 0
+	case ">=":
+		return sign >= 0
+	}
+	return false
+}
+
+// Field returns the value of a named field of the entry, as queries see it,
+// and whether the entry has it.
+func Field(e entry.Entry, name string) (string, bool) {
+	switch name {
+	case "level":
+		return e.Level.String(), true
+	case "message", "msg":
+		return e.Message, true
+	case "raw", "line":
+		return e.Raw, true
+	case "time":
+		if e.Time.IsZero() {
+			return "", false
+		}
+		return e.Time.Format(time.RFC3339Nano), true
+	}
+	value, ok := e.Fields[name]
+	return value, ok
+}
+
+func isLevelField(name string) bool {
+	return name == "level"
+}
+
+func isTimeField(name string) bool {
+	return name == "time"
+}
+
+// isNumber reports whether a word is a decimal number, which "inf" and
+// "nan", also read by strconv.ParseFloat, are not.
+func isNumber(word string) bool {
+	if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) && r != 'e' && r != 'E' }) >= 0 {
+		return false
+	}
+	_, err := strconv.ParseFloat(word, 64)
+	return err == nil
+}
+
+var timeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// parseTime reads an RFC 3339 time, or a local time in one of timeLayouts.
+func parseTime(value string) (time.Time, bool) {
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return ts, true
+	}
+	for _, layout := range timeLayouts {
+		if ts, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
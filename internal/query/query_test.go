@@ -0,0 +1,115 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/entry"
+)
+
+func TestMatch(t *testing.T) {
+	access := entry.Entry{
+		Raw:     `10.0.0.1 - - [24/May/2024:10:00:00 +0000] "GET /api/orders HTTP/1.1" 503 12 "-" "curl/8.0"`,
+		Time:    time.Date(2024, 5, 24, 10, 0, 0, 0, time.UTC),
+		Level:   entry.LevelError,
+		Message: "GET /api/orders HTTP/1.1",
+		Fields:  map[string]string{"status": "503", "path": "/api/orders", "user_agent": "curl/8.0", "bytes": "12", "method": "GET"},
+	}
+	bot := entry.Entry{
+		Level:  entry.LevelWarn,
+		Fields: map[string]string{"status": "404", "path": "/robots.txt", "user_agent": "Googlebot/2.1", "bytes": "-"},
+	}
+	text := entry.Entry{Raw: "INFO started", Level: entry.LevelInfo, Message: "INFO started", Fields: map[string]string{}}
+
+	tests := []struct {
+		query string
+		want  [3]bool // access, bot, text
+	}{
+		{`level>=WARN AND status>=500 AND path ~ "^/api" AND NOT user_agent ~ "bot"`, [3]bool{true, false, false}},
+		{`level >= warn`, [3]bool{true, true, false}},
+		{`level = info`, [3]bool{false, false, true}},
+		{`level < ERROR`, [3]bool{false, true, true}},
+		{`status = 503`, [3]bool{true, false, false}},
+		{`status == "503.0"`, [3]bool{false, false, false}},
+		{`status = 503.0`, [3]bool{true, false, false}},
+		{`status != 503`, [3]bool{false, true, true}},
+		{`status < 500`, [3]bool{false, true, false}},
+		{`bytes > 0`, [3]bool{true, false, false}},
+		{`method = GET OR level = INFO`, [3]bool{true, false, true}},
+		{`method = GET OR level = INFO AND status = 404`, [3]bool{true, false, false}},
+		{`(method = GET OR level = INFO) AND NOT status = 503`, [3]bool{false, false, true}},
+		{`user_agent !~ "(?i)bot"`, [3]bool{true, false, true}},
+		{`user_agent`, [3]bool{true, true, false}},
+		{`NOT NOT user_agent`, [3]bool{true, true, false}},
+		{`message ~ started OR raw ~ "^10\."`, [3]bool{true, false, true}},
+		{`time >= 2024-05-24T09:00:00Z`, [3]bool{true, false, false}},
+		{`time != 2024-05-24T10:00:00Z`, [3]bool{false, true, true}},
+		{`path > /api`, [3]bool{true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			got := [3]bool{q.Match(access), q.Match(bot), q.Match(text)}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var q *Query
+	if !q.Match(text) {
+		t.Error("nil Query does not match")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		pos     int
+		message string
+	}{
+		{query: ``, pos: 0, message: "column 1: expected a condition, got end of query"},
+		{query: `level >=`, pos: 8, message: `column 9: expected a value after ">=", got end of query`},
+		{query: `level = LOUD`, pos: 8, message: `column 9: unknown level "LOUD" (use DEBUG, INFO, WARN, ERROR or FATAL)`},
+		{query: `status >= 500 AND`, pos: 17, message: "column 18: expected a condition, got end of query"},
+		{query: `status >= 500 level = ERROR`, pos: 14, message: `column 15: unexpected "level", expected AND, OR or end of query`},
+		{query: `(status >= 500 OR level = ERROR`, pos: 31, message: `column 32: expected ")" to close the "(" at column 1, got end of query`},
+		{query: `path ~ "(["`, pos: 7},
+		{query: `time > yesterday`, pos: 7},
+		{query: `AND level = ERROR`, pos: 0, message: `column 1: expected a field name, got "AND"`},
+		{query: `= 5`, pos: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if qerr.Pos != tt.pos {
+				t.Errorf("Parse() error position = %d, want %d (%v)", qerr.Pos, tt.pos, err)
+			}
+			if tt.message != "" && err.Error() != tt.message {
+				t.Errorf("Parse() error = %q, want %q", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestErrorPointer(t *testing.T) {
+	src := `level = LOUD`
+	_, err := Parse(src)
+	var qerr *Error
+	if !errors.As(err, &qerr) {
+		t.Fatalf("Parse() error = %v, want *Error", err)
+	}
+	if got, want := qerr.Pointer(src), "  level = LOUD\n          ^"; got != want {
+		t.Errorf("Pointer() =\n%s\nwant\n%s", got, want)
+	}
+}
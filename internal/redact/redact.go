@@ -0,0 +1,250 @@
+// Package redact masks sensitive data, such as email addresses, tokens,
+// credit card numbers and IP addresses, in the text that leaves the analyzer.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+)
+
+// tokenLength is the number of hex digits of the hash in a token.
+const tokenLength = 8
+
+// tokenPattern matches the tokens of masked values, which later rules must
+// leave alone.
+var tokenPattern = regexp.MustCompile(`<[a-z][a-z0-9_]*:[0-9a-f]{8}>`)
+
+// sensitiveField matches the names of fields holding a secret as a whole,
+// such as "password", "client_secret" or "apiKey".
+var sensitiveField = regexp.MustCompile(`(?i)(?:token|secret|passw(?:or)?d|pwd|api[_-]?key|authorization)$`)
+
+// rule is a pattern of a detector. When valid is set, matches it rejects
+// are left as they are.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(value string) bool
+}
+
+var builtinRules = map[string][]rule{
+	config.DetectorEmail: {
+		{pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	},
+	config.DetectorToken: {
+		{pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+		{pattern: regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+([A-Za-z0-9._~+/-]{8,}=*)`)},
+		{pattern: regexp.MustCompile(`(?i)\b(?:access_token|refresh_token|id_token|api_?key|token|secret|password|passwd|pwd)["']?\s*[=:]\s*["']?([^\s"'&,;]+)`)},
+	},
+	config.DetectorCreditCard: {
+		{pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhn},
+	},
+	config.DetectorIP: {
+		{pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), valid: isIP},
+		{pattern: regexp.MustCompile(`(?i)(?:^|[^\w:.])([0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7})`), valid: isIPv6},
+	},
+}
+
+// Redactor replaces sensitive values with tokens such as "<ip:3fa9c2d1>".
+// A token is a keyed hash of the value, so the same value is always masked
+// by the same token within a report. A nil Redactor leaves text unchanged.
+type Redactor struct {
+	key   []byte
+	rules []rule
+	// fields is set when the token detector is enabled, to mask the values
+	// of sensitive fields whatever they look like.
+	fields bool
+}
+
+// New returns a redactor for the configuration. Custom patterns run before
+// the built-in detectors.
+func New(cfg config.RedactionConfig) (*Redactor, error) {
+	r := &Redactor{key: cfg.Key()}
+	if r.key == nil {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			return nil, fmt.Errorf("failed to generate redaction key: %w", err)
+		}
+	}
+
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %s has invalid regex: %w", p.Name, err)
+		}
+		r.rules = append(r.rules, rule{name: p.Name, pattern: re})
+	}
+	for _, detector := range cfg.EnabledDetectors() {
+		rules, ok := builtinRules[detector]
+		if !ok {
+			return nil, fmt.Errorf("unknown redaction detector %q", detector)
+		}
+		for _, rl := range rules {
+			rl.name = detector
+			r.rules = append(r.rules, rl)
+		}
+		if detector == config.DetectorToken {
+			r.fields = true
+		}
+	}
+
+	return r, nil
+}
+
+// ForConfig returns the redactor of a "redaction" configuration section, one
+// with the built-in detectors when there is no section but enabled is set,
+// or nil when redaction is off.
+func ForConfig(cfg *config.RedactionConfig, enabled bool) (*Redactor, error) {
+	if cfg == nil && !enabled {
+		return nil, nil
+	}
+	if cfg == nil {
+		cfg = &config.RedactionConfig{}
+	}
+	return New(*cfg)
+}
+
+// String returns text with every sensitive value replaced by its token.
+func (r *Redactor) String(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, rl := range r.rules {
+		text = r.replace(rl, text)
+	}
+	return text
+}
+
+// Field returns the value of a parsed field with every sensitive value
+// replaced by its token. With the token detector, the whole value of a field
+// named like a secret, e.g. "password" or "api_key", is masked by the token
+// String gives it in "password=value".
+func (r *Redactor) Field(name, value string) string {
+	if r == nil || value == "" {
+		return value
+	}
+	if r.fields && sensitiveField.MatchString(name) {
+		return r.token(config.DetectorToken, value)
+	}
+	return r.String(value)
+}
+
+// replace masks the matches of a rule, or of its first capture group when
+// the pattern has one.
+func (r *Redactor) replace(rl rule, text string) string {
+	matches := rl.pattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+
+	tokens := tokenPattern.FindAllStringIndex(text, -1)
+	out := make([]byte, 0, len(text))
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) > 2 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		value := text[start:end]
+		if value == "" || overlaps(tokens, start, end) || (rl.valid != nil && !rl.valid(value)) {
+			continue
+		}
+		out = append(out, text[last:start]...)
+		out = append(out, r.token(rl.name, value)...)
+		last = end
+	}
+	return string(append(out, text[last:]...))
+}
+
+// overlaps reports whether text[start:end] overlaps one of the spans.
+func overlaps(spans [][]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) token(name, value string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(name + "\x00" + value))
+	return "<" + name + ":" + hex.EncodeToString(mac.Sum(nil))[:tokenLength] + ">"
+}
+
+// Result returns a copy of the result with its messages, error details,
+// templates, alerts and anomalies redacted.
+func (r *Redactor) Result(result reporter.AnalysisResult) reporter.AnalysisResult {
+	if r == nil {
+		return result
+	}
+
+	result.Message = r.String(result.Message)
+	result.ErrorDetails = r.String(result.ErrorDetails)
+
+	if result.Templates != nil {
+		templates := make([]reporter.Template, len(result.Templates))
+		for i, t := range result.Templates {
+			t.Pattern = r.String(t.Pattern)
+			t.Sample = r.String(t.Sample)
+			templates[i] = t
+		}
+		result.Templates = templates
+	}
+	if result.Alerts != nil {
+		alerts := make([]reporter.Alert, len(result.Alerts))
+		for i, a := range result.Alerts {
+			a.Message = r.String(a.Message)
+			alerts[i] = a
+		}
+		result.Alerts = alerts
+	}
+	if result.Anomalies != nil {
+		anomalies := make([]reporter.Anomaly, len(result.Anomalies))
+		for i, a := range result.Anomalies {
+			a.Message = r.String(a.Message)
+			anomalies[i] = a
+		}
+		result.Anomalies = anomalies
+	}
+
+	return result
+}
+
+// luhn reports whether the digits of value pass the Luhn checksum of card
+// numbers.
+func luhn(value string) bool {
+	sum, n := 0, 0
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && n <= 19 && sum%10 == 0
+}
+
+func isIP(value string) bool {
+	return net.ParseIP(value) != nil
+}
+
+// isIPv6 rejects the times and MAC addresses the IPv6 pattern also matches.
+func isIPv6(value string) bool {
+	return value != "::" && net.ParseIP(value) != nil
+}
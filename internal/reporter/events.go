@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event types written by EventSink.
+const (
+	EventStart   = "start"
+	EventResult  = "result"
+	EventSaved   = "saved"
+	EventSummary = "summary"
+)
+
+// Event is one line of the NDJSON event stream: the start of a run, the
+// result of a log, the report being saved, or the final summary.
+type Event struct {
+	Event   string          `json:"event"`
+	Time    time.Time       `json:"time"`
+	Config  string          `json:"config,omitempty"`
+	Logs    int             `json:"logs,omitempty"`
+	Result  *AnalysisResult `json:"result,omitempty"`
+	Path    string          `json:"path,omitempty"`
+	Summary *Summary        `json:"summary,omitempty"`
+}
+
+// Summary counts the results of a run.
+type Summary struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	Alerts     int `json:"alerts"`
+	Anomalies  int `json:"anomalies"`
+}
+
+// Summarize counts the results by status, with the alerts and anomalies.
+func Summarize(results []AnalysisResult) Summary {
+	s := Summary{Total: len(results)}
+	for _, result := range results {
+		switch result.Status {
+		case "FAILURE":
+			s.Failed++
+		case "SKIPPED":
+			s.Skipped++
+		default:
+			s.Successful++
+		}
+		s.Alerts += len(result.Alerts)
+		s.Anomalies += len(result.Anomalies)
+	}
+	return s
+}
+
+// EventSink writes events as NDJSON. As a Sink it emits a "result" event for
+// every result added to a Reporter.
+type EventSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+func NewEventSink(w io.Writer) *EventSink {
+	return &EventSink{w: w, now: time.Now}
+}
+
+// Emit writes one event, setting its time when unset.
+func (s *EventSink) Emit(event Event) error {
+	if event.Time.IsZero() {
+		event.Time = s.now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", event.Event, err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s event: %w", event.Event, err)
+	}
+	return nil
+}
+
+func (s *EventSink) Write(result AnalysisResult) error {
+	return s.Emit(Event{Event: EventResult, Result: &result})
+}
+
+func (s *EventSink) Close() error {
+	return nil
+}
@@ -0,0 +1,181 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Histogram is the number of entries per level in fixed time buckets. Only
+// non-empty buckets are listed; Untimed counts entries without a timestamp.
+type Histogram struct {
+	Bucket  string   `json:"bucket"`
+	Untimed int      `json:"untimed,omitempty"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Bucket holds the counts of one time bucket starting at Start.
+type Bucket struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Levels map[string]int `json:"levels"`
+}
+
+// sparklineWidth is the maximum number of columns of a sparkline.
+const sparklineWidth = 60
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// BucketSize returns the bucket duration of the histogram.
+func (h *Histogram) BucketSize() time.Duration {
+	d, err := time.ParseDuration(h.Bucket)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// MaxSeriesBuckets bounds the number of buckets of a dense series, so that a
+// single entry with a wrong timestamp (e.g. epoch 0 or a wrong year) cannot
+// stretch it over millions of empty buckets.
+const MaxSeriesBuckets = 10000
+
+// Span returns the start of the first bucket and the number of buckets of the
+// series: from the first to the last non-empty bucket or, when they are more
+// than MaxSeriesBuckets apart, the MaxSeriesBuckets buckets holding the most
+// entries. Buckets outside the span are left out of the series.
+func (h *Histogram) Span() (time.Time, int) {
+	size := h.BucketSize()
+	if len(h.Buckets) == 0 || size <= 0 {
+		return time.Time{}, 0
+	}
+
+	index := func(i int) int64 {
+		return int64(h.Buckets[i].Start.Sub(h.Buckets[0].Start) / size)
+	}
+	if last := index(len(h.Buckets) - 1); last < MaxSeriesBuckets {
+		return h.Buckets[0].Start, int(last) + 1
+	}
+
+	// Slide a window of MaxSeriesBuckets buckets over the sparse buckets and
+	// keep the one with the most entries.
+	bestFirst, bestLast, bestTotal := 0, 0, -1
+	total, first := 0, 0
+	for last := range h.Buckets {
+		total += h.Buckets[last].Total
+		for index(last)-index(first) >= MaxSeriesBuckets {
+			total -= h.Buckets[first].Total
+			first++
+		}
+		if total > bestTotal {
+			bestFirst, bestLast, bestTotal = first, last, total
+		}
+	}
+	return h.Buckets[bestFirst].Start, int(index(bestLast)-index(bestFirst)) + 1
+}
+
+// Series returns one value per bucket of the span, including empty buckets
+// in between. value extracts the count to use.
+func (h *Histogram) Series(value func(Bucket) int) []int {
+	first, n := h.Span()
+	if n == 0 {
+		return nil
+	}
+
+	size := h.BucketSize()
+	series := make([]int, n)
+	for _, b := range h.Buckets {
+		if i := b.Start.Sub(first) / size; i >= 0 && i < time.Duration(n) {
+			series[i] += value(b)
+		}
+	}
+	return series
+}
+
+// Sparkline renders values as a line of block characters, summing adjacent
+// values so the line is at most width characters long.
+func Sparkline(values []int, width int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	if width > 0 && len(values) > width {
+		values = resample(values, width)
+	}
+
+	maxValue := 0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case maxValue == 0 || v == 0:
+			b.WriteRune(' ')
+		default:
+			idx := (v*len(sparkBlocks) - 1) / maxValue
+			b.WriteRune(sparkBlocks[idx])
+		}
+	}
+	return b.String()
+}
+
+func resample(values []int, width int) []int {
+	out := make([]int, width)
+	for i, v := range values {
+		out[i*width/len(values)] += v
+	}
+	return out
+}
+
+func errorCount(b Bucket) int {
+	return b.Levels["ERROR"] + b.Levels["FATAL"]
+}
+
+func totalCount(b Bucket) int {
+	return b.Total
+}
+
+// printHistogram prints the volume and error sparklines of a log.
+func printHistogram(w io.Writer, h *Histogram) {
+	if h == nil || len(h.Buckets) == 0 {
+		return
+	}
+
+	first, n := h.Span()
+	last := first.Add(time.Duration(n) * h.BucketSize())
+
+	volume := h.Series(totalCount)
+	errors := h.Series(errorCount)
+
+	fmt.Fprintf(w, "   Timeline: %s → %s (%s buckets)\n", formatBucketTime(first), formatBucketTime(last), h.Bucket)
+	fmt.Fprintf(w, "   Volume |%s| peak %s\n", Sparkline(volume, sparklineWidth), formatPeak(h, volume))
+	if peak, _ := maxIndex(errors); peak > 0 {
+		fmt.Fprintf(w, "   Errors |%s| peak %s\n", Sparkline(errors, sparklineWidth), formatPeak(h, errors))
+	}
+}
+
+func formatPeak(h *Histogram, series []int) string {
+	peak, idx := maxIndex(series)
+	first, _ := h.Span()
+	at := first.Add(time.Duration(idx) * h.BucketSize())
+	return fmt.Sprintf("%d at %s", peak, formatBucketTime(at))
+}
+
+func maxIndex(series []int) (int, int) {
+	peak, idx := 0, 0
+	for i, v := range series {
+		if v > peak {
+			peak, idx = v, i
+		}
+	}
+	return peak, idx
+}
+
+func formatBucketTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
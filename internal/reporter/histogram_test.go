@@ -0,0 +1,65 @@
+package reporter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogramSeries(t *testing.T) {
+	base := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	h := &Histogram{
+		Bucket: "1m0s",
+		Buckets: []Bucket{
+			{Start: base, Total: 4, Levels: map[string]int{"INFO": 3, "ERROR": 1}},
+			{Start: base.Add(3 * time.Minute), Total: 2, Levels: map[string]int{"FATAL": 2}},
+		},
+	}
+
+	volume := h.Series(totalCount)
+	if len(volume) != 4 || volume[0] != 4 || volume[1] != 0 || volume[3] != 2 {
+		t.Errorf("Series(total) = %v, want [4 0 0 2]", volume)
+	}
+
+	errors := h.Series(errorCount)
+	if errors[0] != 1 || errors[3] != 2 {
+		t.Errorf("Series(errors) = %v, want [1 0 0 2]", errors)
+	}
+}
+
+func TestHistogramSeriesOutliers(t *testing.T) {
+	base := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	h := &Histogram{
+		Bucket: "1s",
+		Buckets: []Bucket{
+			{Start: time.Unix(0, 0).UTC(), Total: 1},
+			{Start: base, Total: 5},
+			{Start: base.Add(time.Hour), Total: 3},
+			{Start: base.AddDate(10, 0, 0), Total: 1},
+		},
+	}
+
+	first, n := h.Span()
+	if !first.Equal(base) || n != 3601 {
+		t.Errorf("Span() = %v, %d, want %v and 3601 buckets", first, n, base)
+	}
+	volume := h.Series(totalCount)
+	if len(volume) != 3601 || volume[0] != 5 || volume[3600] != 3 {
+		t.Errorf("Series(total) has %d buckets, want 3601 without the outliers", len(volume))
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got, want := Sparkline([]int{0, 1, 4, 8}, 10), " ▁▄█"; got != want {
+		t.Errorf("Sparkline() = %q, want %q", got, want)
+	}
+
+	long := make([]int, 120)
+	long[119] = 5
+	if got := []rune(Sparkline(long, 60)); len(got) != 60 || got[59] != '█' {
+		t.Errorf("Sparkline() resampled = %q", string(got))
+	}
+
+	if got := Sparkline(nil, 10); got != "" {
+		t.Errorf("Sparkline(nil) = %q, want empty string", got)
+	}
+}
@@ -2,64 +2,377 @@ package reporter
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 type AnalysisResult struct {
-	LogID        string `json:"log_id"`
-	FilePath     string `json:"file_path"`
-	Status       string `json:"status"`
-	Message      string `json:"message"`
-	ErrorDetails string `json:"error_details"`
+	LogID        string     `json:"log_id"`
+	FilePath     string     `json:"file_path"`
+	Status       string     `json:"status"`
+	Message      string     `json:"message"`
+	ErrorDetails string     `json:"error_details"`
+	Stats        *LogStats  `json:"stats,omitempty"`
+	Templates    []Template `json:"templates,omitempty"`
+	Histogram    *Histogram `json:"histogram,omitempty"`
+	Anomalies    []Anomaly  `json:"anomalies,omitempty"`
+	Alerts       []Alert    `json:"alerts,omitempty"`
+	// DurationSeconds is the time spent analyzing the log.
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
 }
 
+// LogStats holds the aggregates collected while scanning a log file.
+type LogStats struct {
+	Lines          int            `json:"lines"`
+	Bytes          int64          `json:"bytes"`
+	ParseErrors    int            `json:"parse_errors"`
+	Levels         map[string]int `json:"levels"`
+	StatusClasses  map[string]int `json:"status_classes,omitempty"`
+	PatternMatches map[string]int `json:"pattern_matches,omitempty"`
+	// Units counts the entries of each systemd unit and Priorities the
+	// entries of each syslog severity, for the logs that carry them.
+	Units      map[string]int `json:"units,omitempty"`
+	Priorities map[string]int `json:"priorities,omitempty"`
+	// Streams holds the stats of each output stream (stdout, stderr) of
+	// container logs.
+	Streams map[string]*StreamStats `json:"streams,omitempty"`
+	// StackTraces counts the entries holding a stack trace, and Exceptions
+	// the traces of each exception type.
+	StackTraces int            `json:"stack_traces,omitempty"`
+	Exceptions  map[string]int `json:"exceptions,omitempty"`
+}
+
+// StreamStats holds the aggregates of one output stream of a container log.
+type StreamStats struct {
+	Lines  int            `json:"lines"`
+	Levels map[string]int `json:"levels"`
+}
+
+// Template is a group of similar messages mined from a log, with the
+// variable parts replaced by placeholders.
+type Template struct {
+	Pattern string `json:"pattern"`
+	Count   int    `json:"count"`
+	Sample  string `json:"sample"`
+}
+
+// Anomaly is an abnormal time range detected in a log's histogram, such as an
+// error spike or a silence. Value is the observed count of the worst bucket
+// and Baseline the count expected from the preceding buckets.
+type Anomaly struct {
+	Kind     string    `json:"kind"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Score    float64   `json:"score"`
+	Value    float64   `json:"value"`
+	Baseline float64   `json:"baseline"`
+	Message  string    `json:"message"`
+}
+
+// Alert is a rule that fired for a log.
+type Alert struct {
+	Rule      string  `json:"rule"`
+	Severity  string  `json:"severity"`
+	Message   string  `json:"message"`
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Op        string  `json:"op"`
+	Threshold float64 `json:"threshold"`
+}
+
+// LevelOrder is the display order of levels, most severe first.
+var LevelOrder = []string{"FATAL", "ERROR", "WARN", "INFO", "DEBUG", "UNKNOWN"}
+
+func NewLogStats() *LogStats {
+	return &LogStats{
+		Levels:         make(map[string]int),
+		StatusClasses:  make(map[string]int),
+		PatternMatches: make(map[string]int),
+		Units:          make(map[string]int),
+		Priorities:     make(map[string]int),
+		Streams:        make(map[string]*StreamStats),
+		Exceptions:     make(map[string]int),
+	}
+}
+
+// Clone returns a copy of the stats that does not share their maps.
+func (s *LogStats) Clone() *LogStats {
+	clone := *s
+	clone.Levels = cloneCounts(s.Levels)
+	clone.StatusClasses = cloneCounts(s.StatusClasses)
+	clone.PatternMatches = cloneCounts(s.PatternMatches)
+	clone.Units = cloneCounts(s.Units)
+	clone.Priorities = cloneCounts(s.Priorities)
+	clone.Exceptions = cloneCounts(s.Exceptions)
+	if s.Streams != nil {
+		clone.Streams = make(map[string]*StreamStats, len(s.Streams))
+		for name, stream := range s.Streams {
+			clone.Streams[name] = &StreamStats{Lines: stream.Lines, Levels: cloneCounts(stream.Levels)}
+		}
+	}
+	return &clone
+}
+
+func cloneCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return nil
+	}
+	clone := make(map[string]int, len(counts))
+	for key, count := range counts {
+		clone[key] = count
+	}
+	return clone
+}
+
+// Reporter collects the results of an analysis. It is safe for concurrent
+// use, so results can be read while an analysis is still adding them, and
+// forwards each result to its sinks as it arrives.
 type Reporter struct {
-	results []AnalysisResult
+	mu       sync.RWMutex
+	results  []AnalysisResult
+	sinks    []Sink
+	sinkErrs []error
+	out      io.Writer
 }
 
 func NewReporter() *Reporter {
 	return &Reporter{
 		results: make([]AnalysisResult, 0),
+		out:     os.Stdout,
 	}
 }
 
+// SetOutput sets where the summary and messages are written, stdout by
+// default.
+func (r *Reporter) SetOutput(w io.Writer) {
+	r.out = w
+}
+
+// AddSink registers a sink receiving every result added from now on.
+func (r *Reporter) AddSink(sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks = append(r.sinks, sink)
+}
+
+// AddResult stores the result and writes it to every sink. Sink errors do not
+// stop the analysis; they are returned by Close.
 func (r *Reporter) AddResult(result AnalysisResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.results = append(r.results, result)
+	for _, sink := range r.sinks {
+		if err := sink.Write(result); err != nil {
+			r.sinkErrs = append(r.sinkErrs, err)
+		}
+	}
+}
+
+// Close closes the sinks and returns the errors met while writing to them.
+func (r *Reporter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := r.sinkErrs
+	for _, sink := range r.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	r.sinks = nil
+	r.sinkErrs = nil
+	return errors.Join(errs...)
 }
 
+// GetResults returns a copy of the results collected so far.
 func (r *Reporter) GetResults() []AnalysisResult {
-	return r.results
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	results := make([]AnalysisResult, len(r.results))
+	copy(results, r.results)
+	return results
+}
+
+// Result returns the result of the log with the given ID.
+func (r *Reporter) Result(logID string) (AnalysisResult, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, result := range r.results {
+		if result.LogID == logID {
+			return result, true
+		}
+	}
+	return AnalysisResult{}, false
 }
 
 func (r *Reporter) PrintSummary() {
-	fmt.Println("\n=== Analysis Summary ===")
+	w := r.out
+	fmt.Fprintln(w, "\n=== Analysis Summary ===")
+	results := r.GetResults()
 	
 	successCount := 0
 	failureCount := 0
+	skippedCount := 0
 	
-	for _, result := range r.results {
+	for _, result := range results {
 		status := "✓"
-		if result.Status == "FAILURE" {
+		switch result.Status {
+		case "FAILURE":
 			status = "✗"
 			failureCount++
-		} else {
+		case "SKIPPED":
+			status = "-"
+			skippedCount++
+		default:
 			successCount++
 		}
 		
-		fmt.Printf("%s [%s] %s: %s\n", status, result.LogID, result.FilePath, result.Message)
+		fmt.Fprintf(w, "%s [%s] %s: %s\n", status, result.LogID, result.FilePath, result.Message)
 		if result.ErrorDetails != "" {
-			fmt.Printf("   Error: %s\n", result.ErrorDetails)
+			fmt.Fprintf(w, "   Error: %s\n", result.ErrorDetails)
+		}
+		if result.Stats != nil {
+			fmt.Fprintf(w, "   Lines: %d%s\n", result.Stats.Lines, formatLevels(result.Stats.Levels))
+			if len(result.Stats.Units) > 0 {
+				fmt.Fprintf(w, "   Units: %s\n", formatTopCounts(result.Stats.Units, maxUnitsShown))
+			}
+			printStreams(w, result.Stats.Streams)
+			if result.Stats.StackTraces > 0 {
+				fmt.Fprintf(w, "   Stack traces: %d", result.Stats.StackTraces)
+				if len(result.Stats.Exceptions) > 0 {
+					fmt.Fprintf(w, " (%s)", formatTopCounts(result.Stats.Exceptions, maxExceptionsShown))
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		printHistogram(w, result.Histogram)
+		if len(result.Templates) > 0 {
+			fmt.Fprintln(w, "   Top templates:")
+			for _, tmpl := range result.Templates {
+				fmt.Fprintf(w, "   %6d  %s\n", tmpl.Count, tmpl.Pattern)
+			}
 		}
 	}
+
+	printAlerts(w, results)
+	printAnomalies(w, results)
 	
-	fmt.Printf("\nTotal: %d logs analyzed (%d successful, %d failed)\n", 
-		len(r.results), successCount, failureCount)
+	if skippedCount > 0 {
+		fmt.Fprintf(w, "\nTotal: %d logs analyzed (%d successful, %d failed), %d skipped\n",
+			successCount+failureCount, successCount, failureCount, skippedCount)
+		return
+	}
+
+	fmt.Fprintf(w, "\nTotal: %d logs analyzed (%d successful, %d failed)\n", 
+		len(results), successCount, failureCount)
+}
+
+func printAlerts(w io.Writer, results []AnalysisResult) {
+	alertCount := 0
+	for _, result := range results {
+		alertCount += len(result.Alerts)
+	}
+	if alertCount == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n=== Alerts (%d fired) ===\n", alertCount)
+	for _, result := range results {
+		for _, alert := range result.Alerts {
+			fmt.Fprintf(w, "! %s [%s] %s: %s\n", strings.ToUpper(alert.Severity), result.LogID, alert.Rule, alert.Message)
+			fmt.Fprintf(w, "   %s = %s (%s %s)\n", alert.Metric, formatValue(alert.Value), alert.Op, formatValue(alert.Threshold))
+		}
+	}
+}
+
+func printAnomalies(w io.Writer, results []AnalysisResult) {
+	anomalyCount := 0
+	for _, result := range results {
+		anomalyCount += len(result.Anomalies)
+	}
+	if anomalyCount == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\n=== Anomalies (%d detected) ===\n", anomalyCount)
+	for _, result := range results {
+		for _, anomaly := range result.Anomalies {
+			fmt.Fprintf(w, "~ %s [%s] %s → %s: %s\n", strings.ToUpper(anomaly.Kind), result.LogID,
+				formatBucketTime(anomaly.Start), formatBucketTime(anomaly.End), anomaly.Message)
+		}
+	}
+}
+
+func formatLevels(levels map[string]int) string {
+	var parts []string
+	for _, level := range LevelOrder {
+		if count := levels[level]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", level, count))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// printStreams prints a line per output stream of a container log.
+func printStreams(w io.Writer, streams map[string]*StreamStats) {
+	names := make([]string, 0, len(streams))
+	for name := range streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "   %s lines: %d%s\n", name, streams[name].Lines, formatLevels(streams[name].Levels))
+	}
+}
+
+// maxUnitsShown is the number of systemd units listed in the summary.
+const maxUnitsShown = 5
+
+// maxExceptionsShown is the number of exception types listed in the summary.
+const maxExceptionsShown = 5
+
+// formatTopCounts lists the n most frequent keys of counts, most frequent
+// first, e.g. "nginx.service 120, sshd.service 4 (+3 more)".
+func formatTopCounts(counts map[string]int, n int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var parts []string
+	for _, key := range keys[:min(n, len(keys))] {
+		parts = append(parts, fmt.Sprintf("%s %d", key, counts[key]))
+	}
+	text := strings.Join(parts, ", ")
+	if len(keys) > n {
+		text += fmt.Sprintf(" (+%d more)", len(keys)-n)
+	}
+	return text
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
 }
 
 func (r *Reporter) SaveToFile(outputPath string) error {
-	data, err := json.MarshalIndent(r.results, "", "  ")
+	data, err := json.MarshalIndent(r.GetResults(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal results to JSON: %w", err)
 	}
@@ -73,7 +386,7 @@ func (r *Reporter) SaveToFile(outputPath string) error {
 		return fmt.Errorf("failed to write results to file %s: %w", outputPath, err)
 	}
 
-	fmt.Printf("Analysis results saved to: %s\n", outputPath)
+	fmt.Fprintf(r.out, "Analysis results saved to: %s\n", outputPath)
 	return nil
 }
 
@@ -95,4 +408,28 @@ func CreateFailureResult(logID, filePath, message, errorDetails string) Analysis
 		Message:      message,
 		ErrorDetails: errorDetails,
 	}
-} 
\ No newline at end of file
+}
+
+func CreateSkippedResult(logID, filePath, reason string) AnalysisResult {
+	return AnalysisResult{
+		LogID:        logID,
+		FilePath:     filePath,
+		Status:       "SKIPPED",
+		Message:      fmt.Sprintf("Skipped: %s.", reason),
+		ErrorDetails: "",
+	}
+}
+
+// LoadResults reads a report previously written by SaveToFile.
+func LoadResults(path string) ([]AnalysisResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var results []AnalysisResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return results, nil
+}
@@ -2,8 +2,10 @@ package reporter
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -74,6 +76,32 @@ func TestGetResults(t *testing.T) {
 	}
 }
 
+func TestReporterConcurrentAccess(t *testing.T) {
+	reporter := NewReporter()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			reporter.AddResult(CreateSuccessResult(fmt.Sprintf("log%d", i), "/var/log/test.log"))
+		}(i)
+		go func() {
+			defer wg.Done()
+			reporter.GetResults()
+			reporter.Result("log0")
+		}()
+	}
+	wg.Wait()
+
+	if got := len(reporter.GetResults()); got != 50 {
+		t.Errorf("GetResults() returned %d results, want 50", got)
+	}
+	if _, ok := reporter.Result("log42"); !ok {
+		t.Error("Result(log42) not found")
+	}
+}
+
 func TestSaveToFile(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
 	if err != nil {
@@ -118,6 +146,35 @@ func TestSaveToFile(t *testing.T) {
 	}
 }
 
+func TestLoadResults(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "loganalyzer-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	reporter := NewReporter()
+	reporter.AddResult(CreateSuccessResult("log1", "/var/log/test1.log"))
+	reporter.AddResult(CreateFailureResult("log2", "/var/log/test2.log", "Test error", "Error details"))
+
+	outputPath := filepath.Join(tempDir, "results.json")
+	if err := reporter.SaveToFile(outputPath); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+
+	results, err := LoadResults(outputPath)
+	if err != nil {
+		t.Fatalf("LoadResults() error = %v", err)
+	}
+	if len(results) != 2 || results[1].Status != "FAILURE" {
+		t.Errorf("LoadResults() = %+v, want the saved results", results)
+	}
+
+	if _, err := LoadResults(filepath.Join(tempDir, "missing.json")); err == nil {
+		t.Error("LoadResults() expected error for missing file, got nil")
+	}
+}
+
 func TestCreateSuccessResult(t *testing.T) {
 	result := CreateSuccessResult("log1", "/var/log/test1.log")
 
@@ -165,3 +222,51 @@ func TestCreateFailureResult(t *testing.T) {
 		t.Errorf("CreateFailureResult() wrong ErrorDetails, got %s, want %s", result.ErrorDetails, "Error details")
 	}
 }
+
+func TestCreateSkippedResult(t *testing.T) {
+	result := CreateSkippedResult("log1", "/var/log/test1.log", "disabled in configuration")
+
+	if result.Status != "SKIPPED" {
+		t.Errorf("CreateSkippedResult() wrong Status, got %s, want %s", result.Status, "SKIPPED")
+	}
+
+	if result.Message != "Skipped: disabled in configuration." {
+		t.Errorf("CreateSkippedResult() wrong Message, got %s", result.Message)
+	}
+}
+
+func TestFormatLevels(t *testing.T) {
+	levels := map[string]int{"INFO": 10, "ERROR": 2, "DEBUG": 0}
+
+	if got, want := formatLevels(levels), " (ERROR 2, INFO 10)"; got != want {
+		t.Errorf("formatLevels() = %q, want %q", got, want)
+	}
+
+	if got := formatLevels(map[string]int{}); got != "" {
+		t.Errorf("formatLevels() = %q, want empty string", got)
+	}
+}
+
+func TestFormatTopCounts(t *testing.T) {
+	counts := map[string]int{"nginx.service": 120, "sshd.service": 4, "cron.service": 4, "app.service": 1}
+
+	if got, want := formatTopCounts(counts, 5), "nginx.service 120, cron.service 4, sshd.service 4, app.service 1"; got != want {
+		t.Errorf("formatTopCounts() = %q, want %q", got, want)
+	}
+	if got, want := formatTopCounts(counts, 2), "nginx.service 120, cron.service 4 (+2 more)"; got != want {
+		t.Errorf("formatTopCounts() = %q, want %q", got, want)
+	}
+}
+
+func TestLogStatsCloneStreams(t *testing.T) {
+	stats := NewLogStats()
+	stats.Streams["stderr"] = &StreamStats{Lines: 2, Levels: map[string]int{"ERROR": 2}}
+
+	clone := stats.Clone()
+	clone.Streams["stderr"].Lines++
+	clone.Streams["stderr"].Levels["ERROR"]++
+
+	if stats.Streams["stderr"].Lines != 2 || stats.Streams["stderr"].Levels["ERROR"] != 2 {
+		t.Errorf("Clone() shares stream stats: %+v", stats.Streams["stderr"])
+	}
+}
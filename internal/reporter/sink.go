@@ -0,0 +1,66 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sink receives every result as soon as it is added to a Reporter, so a long
+// or interrupted run still leaves a record of the logs analyzed so far.
+type Sink interface {
+	Write(result AnalysisResult) error
+	Close() error
+}
+
+// JSONLSink writes each result as one JSON line. Every result is written with
+// a single write, unbuffered, so what is on disk is always complete lines.
+type JSONLSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLSink writes results to w, e.g. os.Stdout for NDJSON streaming. w is
+// not closed by Close.
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+// NewJSONLFileSink appends results to the file at path, creating it and its
+// directories when needed.
+func NewJSONLFileSink(path string) (*JSONLSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directories for %s: %w", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &JSONLSink{w: file, closer: file}, nil
+}
+
+func (s *JSONLSink) Write(result AnalysisResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result of %s: %w", result.LogID, err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write result of %s: %w", result.LogID, err)
+	}
+	return nil
+}
+
+func (s *JSONLSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
@@ -0,0 +1,109 @@
+package reporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingSink struct{ closed bool }
+
+func (s *failingSink) Write(result AnalysisResult) error { return errors.New("disk full") }
+func (s *failingSink) Close() error                      { s.closed = true; return nil }
+
+func TestJSONLFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream", "results.jsonl")
+
+	sink, err := NewJSONLFileSink(path)
+	if err != nil {
+		t.Fatalf("NewJSONLFileSink() error = %v", err)
+	}
+
+	reporter := NewReporter()
+	reporter.AddSink(sink)
+	reporter.AddResult(CreateSuccessResult("log1", "/var/log/test1.log"))
+
+	// The first result is on disk before the run is over.
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "\n") != 1 {
+		t.Fatalf("stream after one result = %q, %v", data, err)
+	}
+
+	reporter.AddResult(CreateFailureResult("log2", "/var/log/test2.log", "File not found.", "missing"))
+	if err := reporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var result AnalysisResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		ids = append(ids, result.LogID)
+	}
+	if strings.Join(ids, ",") != "log1,log2" {
+		t.Errorf("streamed results = %v, want log1,log2", ids)
+	}
+}
+
+func TestReporterSinkErrors(t *testing.T) {
+	var buf bytes.Buffer
+	failing := &failingSink{}
+
+	reporter := NewReporter()
+	reporter.AddSink(NewJSONLSink(&buf))
+	reporter.AddSink(failing)
+	reporter.AddResult(CreateSuccessResult("log1", "/var/log/test1.log"))
+
+	if len(reporter.GetResults()) != 1 || buf.Len() == 0 {
+		t.Error("a failing sink must not stop the other sinks or the in-memory results")
+	}
+
+	err := reporter.Close()
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Close() error = %v, want the sink write error", err)
+	}
+	if !failing.closed {
+		t.Error("Close() did not close every sink")
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewEventSink(&buf)
+
+	reporter := NewReporter()
+	reporter.AddSink(sink)
+	reporter.AddResult(CreateFailureResult("log1", "/var/log/test1.log", "File not found.", "missing"))
+
+	summary := Summarize(reporter.GetResults())
+	if err := sink.Emit(Event{Event: EventSummary, Summary: &summary}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("events = %q, want 2 lines", buf.String())
+	}
+
+	var result, final Event
+	if err := json.Unmarshal([]byte(lines[0]), &result); err != nil || result.Event != EventResult || result.Result.LogID != "log1" {
+		t.Errorf("first event = %s (%v)", lines[0], err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &final); err != nil || final.Summary == nil || final.Summary.Failed != 1 || final.Time.IsZero() {
+		t.Errorf("summary event = %s (%v)", lines[1], err)
+	}
+}
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/metrics"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/tracing"
+)
+
+// ErrAnalysisRunning is returned by Analyze while another analysis is in
+// progress.
+var ErrAnalysisRunning = errors.New("an analysis is already running")
+
+// Run describes the latest completed analysis.
+type Run struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Server runs analyses of a configuration on demand and serves their results
+// over HTTP. Analyses are serialized; reads are served from the latest
+// completed report while a new one is running.
+type Server struct {
+	configPath string
+	options    parser.Options
+
+	mu      sync.RWMutex
+	cfg     *config.Config
+	latest  *reporter.Reporter
+	lastRun *Run
+	running bool
+	metrics *metrics.Collector
+
+	// onComplete is called after every analysis, e.g. to record history.
+	onComplete func(*config.Config, *reporter.Reporter, Run)
+	// tracerFor builds the tracer of the options from the configuration,
+	// again on every reload.
+	tracerFor func(*config.Config) *tracing.Tracer
+}
+
+// New loads the configuration at configPath and returns a server analyzing
+// it with the given options.
+func New(configPath string, opts parser.Options) (*Server, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return &Server{
+		configPath: configPath,
+		options:    opts,
+		cfg:        cfg,
+		metrics:    metrics.NewCollector(),
+	}, nil
+}
+
+// OnComplete sets a function called after every analysis with the
+// configuration it analyzed, which a reload may have replaced since.
+func (s *Server) OnComplete(fn func(*config.Config, *reporter.Reporter, Run)) {
+	s.onComplete = fn
+}
+
+// TraceWith sets the function building the tracer of the analyses from the
+// loaded configuration. It is called again when the configuration is
+// reloaded.
+func (s *Server) TraceWith(fn func(*config.Config) *tracing.Tracer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tracerFor = fn
+	s.options.Tracer = fn(s.cfg)
+}
+
+// Config returns the configuration currently loaded.
+func (s *Server) Config() *config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// Reload reads the configuration file again. The current configuration is
+// kept when the new one is invalid.
+func (s *Server) Reload() error {
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	s.mu.Lock()
+	s.cfg = cfg
+	if s.tracerFor != nil {
+		s.options.Tracer = s.tracerFor(cfg)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Analyze runs an analysis of the loaded configuration, makes its report the
+// latest one and calls the OnComplete function. It returns ErrAnalysisRunning
+// when one is in progress, until OnComplete returns.
+func (s *Server) Analyze() (*reporter.Reporter, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrAnalysisRunning
+	}
+	s.running = true
+	cfg, opts := s.cfg, s.options
+	s.mu.Unlock()
+
+	// The next analysis waits for onComplete too, so history records and
+	// notifications are never written concurrently.
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	run := Run{Started: time.Now()}
+	rep, err := analyze(cfg, opts)
+	run.Finished = time.Now()
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if rep != nil {
+		s.metrics.Observe(cfg, rep.GetResults(), run.Finished, run.Finished.Sub(run.Started))
+	} else {
+		s.metrics.ObserveError(run.Finished)
+	}
+
+	s.mu.Lock()
+	s.lastRun = &run
+	if rep != nil {
+		s.latest = rep
+	}
+	s.mu.Unlock()
+
+	if rep != nil && s.onComplete != nil {
+		s.onComplete(cfg, rep, run)
+	}
+	return rep, err
+}
+
+func analyze(cfg *config.Config, opts parser.Options) (*reporter.Reporter, error) {
+	analyzer, err := parser.NewAnalyzerWithOptions(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := analyzer.AnalyzeAllLogs(); err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	return analyzer.GetReporter(), nil
+}
+
+// Latest returns the report of the latest completed analysis, or nil.
+func (s *Server) Latest() *reporter.Reporter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest
+}
+
+// Handler returns the HTTP API of the server:
+//
+//	GET  /health             liveness and status of the latest run
+//	POST /api/analyze        start an analysis (?wait=true to block until done)
+//	GET  /api/report         results of the latest analysis
+//	GET  /api/logs/{id}      result of one log in the latest analysis
+//	POST /api/reload         reload the configuration file
+//	GET  /metrics            per-log metrics in the Prometheus text format
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /api/analyze", s.handleAnalyze)
+	mux.HandleFunc("GET /api/report", s.handleReport)
+	mux.HandleFunc("GET /api/logs/{id}", s.handleLog)
+	mux.HandleFunc("POST /api/reload", s.handleReload)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return mux
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	status := struct {
+		Status  string `json:"status"`
+		Logs    int    `json:"logs"`
+		Running bool   `json:"running"`
+		LastRun *Run   `json:"last_run,omitempty"`
+	}{
+		Status:  "ok",
+		Logs:    len(s.cfg.Logs),
+		Running: s.running,
+		LastRun: s.lastRun,
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("wait") != "true" {
+		s.mu.RLock()
+		running := s.running
+		s.mu.RUnlock()
+		if running {
+			writeError(w, http.StatusConflict, ErrAnalysisRunning)
+			return
+		}
+
+		go func() {
+			if _, err := s.Analyze(); err != nil && !errors.Is(err, ErrAnalysisRunning) {
+				fmt.Printf("✗ Analysis failed: %v\n", err)
+			}
+		}()
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+		return
+	}
+
+	rep, err := s.Analyze()
+	switch {
+	case errors.Is(err, ErrAnalysisRunning):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, rep.GetResults())
+	}
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	rep := s.Latest()
+	if rep == nil {
+		writeError(w, http.StatusNotFound, errors.New("no analysis has completed yet"))
+		return
+	}
+	writeJSON(w, http.StatusOK, rep.GetResults())
+}
+
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	rep := s.Latest()
+	if rep == nil {
+		writeError(w, http.StatusNotFound, errors.New("no analysis has completed yet"))
+		return
+	}
+
+	id := r.PathValue("id")
+	result, ok := rep.Result(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("log %s not found in the latest report", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if err := s.Reload(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "reloaded", "logs": len(s.Config().Logs)})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.WriteTo(w)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
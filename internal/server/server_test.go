@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/reporter"
+	"loganalyzer/internal/tracing"
+)
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func newTestServer(t *testing.T) (*Server, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("ERROR boom\nINFO fine\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	configPath := writeConfig(t, dir, `[{"id": "app", "path": "`+logPath+`", "type": "application"}]`)
+
+	srv, err := New(configPath, parser.Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return srv, dir, logPath
+}
+
+func do(t *testing.T, h http.Handler, method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	srv, dir, logPath := newTestServer(t)
+	h := srv.Handler()
+
+	if rec := do(t, h, http.MethodGet, "/health"); rec.Code != http.StatusOK {
+		t.Errorf("GET /health = %d, want 200", rec.Code)
+	}
+	if rec := do(t, h, http.MethodGet, "/api/report"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/report before any analysis = %d, want 404", rec.Code)
+	}
+
+	rec := do(t, h, http.MethodPost, "/api/analyze?wait=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/analyze?wait=true = %d: %s", rec.Code, rec.Body)
+	}
+	var results []reporter.AnalysisResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil || len(results) != 1 {
+		t.Fatalf("analyze response = %s (%v)", rec.Body, err)
+	}
+
+	rec = do(t, h, http.MethodGet, "/api/logs/app")
+	var result reporter.AnalysisResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/logs/app = %d: %s", rec.Code, rec.Body)
+	}
+	if result.Stats == nil || result.Stats.Levels["ERROR"] != 1 {
+		t.Errorf("GET /api/logs/app stats = %+v", result.Stats)
+	}
+	if rec := do(t, h, http.MethodGet, "/api/logs/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/logs/missing = %d, want 404", rec.Code)
+	}
+
+	rec = do(t, h, http.MethodGet, "/metrics")
+	if want := `loganalyzer_log_entries{log_id="app",type="application",level="ERROR"} 1`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("GET /metrics missing %q:\n%s", want, rec.Body)
+	}
+
+	// An invalid configuration is rejected and the previous one kept.
+	writeConfig(t, dir, `[]`)
+	if rec := do(t, h, http.MethodPost, "/api/reload"); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /api/reload with invalid config = %d, want 400", rec.Code)
+	}
+	writeConfig(t, dir, `[
+		{"id": "app", "path": "`+logPath+`", "type": "application"},
+		{"id": "other", "path": "`+logPath+`", "type": "application"}
+	]`)
+	if rec := do(t, h, http.MethodPost, "/api/reload"); rec.Code != http.StatusOK {
+		t.Errorf("POST /api/reload = %d: %s", rec.Code, rec.Body)
+	}
+	if got := len(srv.Config().Logs); got != 2 {
+		t.Errorf("Config() after reload has %d logs, want 2", got)
+	}
+}
+
+func TestAnalyzeConcurrent(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+	h := srv.Handler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			rec := do(t, h, http.MethodPost, "/api/analyze?wait=true")
+			if rec.Code != http.StatusOK && rec.Code != http.StatusConflict {
+				t.Errorf("POST /api/analyze = %d: %s", rec.Code, rec.Body)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			do(t, h, http.MethodGet, "/api/report")
+			do(t, h, http.MethodGet, "/health")
+		}()
+	}
+	wg.Wait()
+
+	if srv.Latest() == nil {
+		t.Error("Latest() = nil after concurrent analyses")
+	}
+}
+
+func TestAnalyzeRunningUntilComplete(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	completing := make(chan struct{})
+	release := make(chan struct{})
+	srv.OnComplete(func(*config.Config, *reporter.Reporter, Run) {
+		close(completing)
+		<-release
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := srv.Analyze()
+		done <- err
+	}()
+
+	<-completing
+	if _, err := srv.Analyze(); err != ErrAnalysisRunning {
+		t.Errorf("Analyze() during OnComplete error = %v, want ErrAnalysisRunning", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	srv.OnComplete(nil)
+	if _, err := srv.Analyze(); err != nil {
+		t.Errorf("Analyze() after OnComplete error = %v", err)
+	}
+}
+
+// countingExporter counts the traces it exports.
+type countingExporter struct {
+	mu     sync.Mutex
+	traces int
+}
+
+func (e *countingExporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.traces++
+	return nil
+}
+
+func TestTraceWithReload(t *testing.T) {
+	srv, dir, logPath := newTestServer(t)
+
+	// One exporter per configuration loaded.
+	var exporters []*countingExporter
+	srv.TraceWith(func(cfg *config.Config) *tracing.Tracer {
+		exporters = append(exporters, &countingExporter{})
+		return tracing.NewTracer(exporters[len(exporters)-1])
+	})
+	if _, err := srv.Analyze(); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	writeConfig(t, dir, `[
+		{"id": "app", "path": "`+logPath+`", "type": "application"},
+		{"id": "other", "path": "`+logPath+`", "type": "application"}
+	]`)
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if _, err := srv.Analyze(); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if len(exporters) != 2 {
+		t.Fatalf("TraceWith() function called %d times, want 2", len(exporters))
+	}
+	if exporters[0].traces != 1 || exporters[1].traces != 1 {
+		t.Errorf("traces exported per configuration = %d and %d, want 1 each", exporters[0].traces, exporters[1].traces)
+	}
+}
+
+func TestOnCompleteAnalyzedConfig(t *testing.T) {
+	srv, dir, logPath := newTestServer(t)
+	writeConfig(t, dir, `[
+		{"id": "app", "path": "`+logPath+`", "type": "application"},
+		{"id": "other", "path": "`+logPath+`", "type": "application"}
+	]`)
+
+	var analyzed *config.Config
+	srv.OnComplete(func(cfg *config.Config, rep *reporter.Reporter, run Run) {
+		// A reload since the analysis started must not change what the
+		// results are recorded against.
+		if err := srv.Reload(); err != nil {
+			t.Errorf("Reload() error = %v", err)
+		}
+		analyzed = cfg
+	})
+	if _, err := srv.Analyze(); err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if analyzed == nil || len(analyzed.Logs) != 1 {
+		t.Errorf("OnComplete() configuration = %+v, want the analyzed one with 1 log", analyzed)
+	}
+	if got := len(srv.Config().Logs); got != 2 {
+		t.Errorf("Config() after reload has %d logs, want 2", got)
+	}
+}
@@ -0,0 +1,167 @@
+// Package sketch implements DDSketch, a quantile sketch with relative error
+// guarantees that can be merged exactly: the sketch of the union of two sets
+// of values is the merge of their sketches, so per-file partials combine
+// into the same percentiles as a single pass over every file.
+package sketch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultRelativeAccuracy is the relative accuracy of New: a quantile
+// estimate is within 1% of the exact value.
+const DefaultRelativeAccuracy = 0.01
+
+// Sketch summarizes a set of values in logarithmic bins: bin i holds the
+// values in (gamma^(i-1), gamma^i], with gamma = (1+a)/(1-a) for a relative
+// accuracy a. The number of bins grows with the logarithm of the value
+// range, not with the number of values. A Sketch is not safe for concurrent
+// use.
+type Sketch struct {
+	accuracy float64
+	gamma    float64
+	logGamma float64
+	// positive and negative count the values by bin index, negative ones by
+	// the index of their absolute value.
+	positive map[int]uint64
+	negative map[int]uint64
+	zeros    uint64
+	count    uint64
+	min, max float64
+}
+
+// New returns an empty sketch with DefaultRelativeAccuracy.
+func New() *Sketch {
+	s, _ := NewWithAccuracy(DefaultRelativeAccuracy)
+	return s
+}
+
+// NewWithAccuracy returns an empty sketch whose quantiles are within the
+// given relative accuracy, between 0 and 1 exclusive.
+func NewWithAccuracy(accuracy float64) (*Sketch, error) {
+	if !(accuracy > 0 && accuracy < 1) {
+		return nil, fmt.Errorf("invalid relative accuracy %v: must be between 0 and 1", accuracy)
+	}
+	gamma := (1 + accuracy) / (1 - accuracy)
+	return &Sketch{
+		accuracy: accuracy,
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		positive: make(map[int]uint64),
+		negative: make(map[int]uint64),
+		min:      math.Inf(1),
+		max:      math.Inf(-1),
+	}, nil
+}
+
+// Add adds a value. NaN and infinite values are ignored.
+func (s *Sketch) Add(value float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+
+	switch {
+	case value > 0:
+		s.positive[s.index(value)]++
+	case value < 0:
+		s.negative[s.index(-value)]++
+	default:
+		s.zeros++
+	}
+	s.count++
+	s.min = math.Min(s.min, value)
+	s.max = math.Max(s.max, value)
+}
+
+// Merge adds the values of other to s. Both sketches must have the same
+// relative accuracy.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.accuracy != s.accuracy {
+		return fmt.Errorf("cannot merge sketches of relative accuracy %v and %v", s.accuracy, other.accuracy)
+	}
+	for index, n := range other.positive {
+		s.positive[index] += n
+	}
+	for index, n := range other.negative {
+		s.negative[index] += n
+	}
+	s.zeros += other.zeros
+	s.count += other.count
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+	return nil
+}
+
+// Count returns the number of values added.
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Quantile returns an estimate of the q-quantile of the values, q between 0
+// and 1, e.g. 0.95 for the 95th percentile: the value of nearest rank
+// ceil(q*n), so the 95th percentile of two values is the larger one. It
+// returns NaN for an empty sketch. The minimum and maximum are exact.
+func (s *Sketch) Quantile(q float64) float64 {
+	if s.count == 0 || q < 0 || q > 1 {
+		return math.NaN()
+	}
+	if q == 0 {
+		return s.min
+	}
+	if q == 1 {
+		return s.max
+	}
+
+	rank := uint64(math.Ceil(q * float64(s.count)))
+	var seen uint64
+
+	// Negative values, from the largest absolute value down.
+	for _, index := range sortedIndexes(s.negative, true) {
+		seen += s.negative[index]
+		if seen >= rank {
+			return s.clamp(-s.value(index))
+		}
+	}
+	seen += s.zeros
+	if seen >= rank {
+		return 0
+	}
+	for _, index := range sortedIndexes(s.positive, false) {
+		seen += s.positive[index]
+		if seen >= rank {
+			return s.clamp(s.value(index))
+		}
+	}
+	return s.max
+}
+
+// index returns the bin of a positive value.
+func (s *Sketch) index(value float64) int {
+	return int(math.Ceil(math.Log(value) / s.logGamma))
+}
+
+// value returns the estimate of the values of a bin, the one within the
+// relative accuracy of both of its bounds.
+func (s *Sketch) value(index int) float64 {
+	return 2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)
+}
+
+// clamp keeps an estimate within the exact minimum and maximum.
+func (s *Sketch) clamp(value float64) float64 {
+	return math.Max(s.min, math.Min(s.max, value))
+}
+
+func sortedIndexes(bins map[int]uint64, descending bool) []int {
+	indexes := make([]int, 0, len(bins))
+	for index := range bins {
+		indexes = append(indexes, index)
+	}
+	if descending {
+		sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	} else {
+		sort.Ints(indexes)
+	}
+	return indexes
+}
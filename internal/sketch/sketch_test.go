@@ -0,0 +1,96 @@
+package sketch
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := make([]float64, 10000)
+	s := New()
+	for i := range values {
+		values[i] = math.Exp(rng.NormFloat64()) // log-normal, like latencies
+		s.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0.01, 0.25, 0.5, 0.9, 0.95, 0.99, 0.999} {
+		exact := values[int(math.Ceil(q*float64(len(values))))-1]
+		got := s.Quantile(q)
+		if math.Abs(got-exact) > DefaultRelativeAccuracy*exact {
+			t.Errorf("Quantile(%v) = %v, want %v within 1%%", q, got, exact)
+		}
+	}
+	if s.Quantile(0) != values[0] || s.Quantile(1) != values[len(values)-1] {
+		t.Errorf("Quantile(0), Quantile(1) = %v, %v, want the exact minimum and maximum", s.Quantile(0), s.Quantile(1))
+	}
+	if s.Count() != uint64(len(values)) {
+		t.Errorf("Count() = %d, want %d", s.Count(), len(values))
+	}
+}
+
+func TestQuantileSigns(t *testing.T) {
+	s := New()
+	for _, v := range []float64{-100, -10, 0, 0, 10, 100, math.NaN(), math.Inf(1)} {
+		s.Add(v)
+	}
+
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{q: 0, want: -100},
+		{q: 0.1, want: -100},
+		{q: 0.3, want: -10},
+		{q: 0.5, want: 0},
+		{q: 0.8, want: 10},
+		{q: 1, want: 100},
+	}
+	for _, tt := range tests {
+		if got := s.Quantile(tt.q); math.Abs(got-tt.want) > DefaultRelativeAccuracy*math.Abs(tt.want) {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if s.Count() != 6 {
+		t.Errorf("Count() = %d, want 6 (NaN and Inf ignored)", s.Count())
+	}
+	if !math.IsNaN(New().Quantile(0.5)) {
+		t.Error("Quantile() of an empty sketch is not NaN")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	whole, first, second := New(), New(), New()
+	for i := 1; i <= 1000; i++ {
+		v := float64(i) / 10
+		whole.Add(v)
+		if i%3 == 0 {
+			first.Add(v)
+		} else {
+			second.Add(v)
+		}
+	}
+
+	if err := first.Merge(second); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	for _, q := range []float64{0, 0.5, 0.95, 0.99, 1} {
+		if got, want := first.Quantile(q), whole.Quantile(q); got != want {
+			t.Errorf("merged Quantile(%v) = %v, want %v as a single sketch", q, got, want)
+		}
+	}
+
+	coarse, err := NewWithAccuracy(0.05)
+	if err != nil {
+		t.Fatalf("NewWithAccuracy() error = %v", err)
+	}
+	if err := first.Merge(coarse); err == nil {
+		t.Error("Merge() accepted a sketch of another accuracy")
+	}
+	if _, err := NewWithAccuracy(1); err == nil {
+		t.Error("NewWithAccuracy(1) accepted an invalid accuracy")
+	}
+}
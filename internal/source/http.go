@@ -0,0 +1,159 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+// maxResumes bounds how many times a broken download is resumed.
+const maxResumes = 3
+
+// getFunc requests a remote file from the given offset. A non-empty etag makes
+// the request fail if the file changed since the first response.
+type getFunc func(offset int64, etag string) (*http.Response, error)
+
+// rangeReader streams a remote file and, when the connection breaks, resumes
+// the download with a Range request from the last byte read.
+type rangeReader struct {
+	get     getFunc
+	body    io.ReadCloser
+	offset  int64
+	etag    string
+	resumes int
+}
+
+// openRange starts the download, so that a missing or forbidden file is
+// reported by Open like a local one.
+func openRange(get getFunc) (io.ReadCloser, error) {
+	resp, err := get(0, "")
+	if err != nil {
+		return nil, err
+	}
+	return &rangeReader{get: get, body: resp.Body, etag: resp.Header.Get("ETag")}, nil
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == nil || err == io.EOF || r.resumes >= maxResumes {
+		return n, err
+	}
+
+	r.body.Close()
+	r.resumes++
+	resp, getErr := r.get(r.offset, r.etag)
+	if getErr != nil {
+		return n, fmt.Errorf("%w (resume failed: %v)", err, getErr)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return n, fmt.Errorf("%w (server does not support range requests)", err)
+	}
+	r.body = resp.Body
+	return n, nil
+}
+
+func (r *rangeReader) Close() error {
+	return r.body.Close()
+}
+
+// newHTTPClient returns a client honoring the proxy environment variables
+// that waits at most timeout for response headers. Downloads themselves are
+// not limited, whatever their size.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &http.Client{Transport: transport}
+}
+
+// doGet sends a GET request for the file from offset and returns the
+// response when it succeeded.
+func doGet(client *http.Client, req *http.Request, offset int64, etag string, sign func(*http.Request)) (*http.Response, error) {
+	where := req.URL.Redacted()
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+	if sign != nil {
+		sign(req)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", where, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		return nil, responseError(where, resp)
+	}
+	return resp, nil
+}
+
+// responseError maps an unsuccessful response onto the errors of a local
+// open: 404 and 410 wrap os.ErrNotExist, 401 and 403 os.ErrPermission. The
+// code of an S3 error document is kept in the message.
+func responseError(where string, resp *http.Response) error {
+	detail := resp.Status
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if code := xmlErrorCode(body); code != "" {
+		detail += " (" + code + ")"
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return &os.PathError{Op: "get", Path: where, Err: fmt.Errorf("%s: %w", detail, os.ErrNotExist)}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &os.PathError{Op: "get", Path: where, Err: fmt.Errorf("%s: %w", detail, os.ErrPermission)}
+	default:
+		return fmt.Errorf("GET %s: %s", where, detail)
+	}
+}
+
+// xmlErrorCode returns the <Code> of an S3 error document, if body is one.
+func xmlErrorCode(body []byte) string {
+	_, rest, found := bytes.Cut(body, []byte("<Code>"))
+	if !found {
+		return ""
+	}
+	code, _, found := bytes.Cut(rest, []byte("</Code>"))
+	if !found {
+		return ""
+	}
+	return string(code)
+}
+
+func openHTTP(logConfig config.LogConfig) (io.ReadCloser, error) {
+	settings := config.HTTPConfig{}
+	if logConfig.HTTP != nil {
+		settings = *logConfig.HTTP
+	}
+	u, err := url.Parse(logConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+	client := newHTTPClient(settings.ResponseTimeout())
+
+	return openRange(func(offset int64, etag string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range settings.Headers {
+			req.Header.Set(name, value)
+		}
+		if settings.Username != "" {
+			req.SetBasicAuth(settings.Username, settings.BasicAuthPassword())
+		}
+		return doGet(client, req, offset, etag, nil)
+	})
+}
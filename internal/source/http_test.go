@@ -0,0 +1,101 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"loganalyzer/internal/config"
+)
+
+func TestOpenHTTP(t *testing.T) {
+	content := strings.Repeat("2024-05-24 10:00:00 [INFO] Request served\n", 100)
+
+	var broken atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.log":
+			http.ServeContent(w, r, "app.log", testModTime, strings.NewReader(content))
+		case "/private.log":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "ops" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, content)
+		case "/token.log":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, content)
+		case "/flaky.log":
+			// The first response breaks halfway; later ones serve ranges.
+			if r.Header.Get("Range") == "" && broken.CompareAndSwap(false, true) {
+				w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+				w.Header().Set("ETag", `"v1"`)
+				io.WriteString(w, content[:len(content)/2])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "flaky.log", testModTime, strings.NewReader(content))
+		case "/error.log":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		http    *config.HTTPConfig
+		wantErr error
+		anyErr  bool
+	}{
+		{name: "Download", path: "/app.log"},
+		{name: "Basic auth", path: "/private.log", http: &config.HTTPConfig{Username: "ops", Password: "secret"}},
+		{name: "Header", path: "/token.log", http: &config.HTTPConfig{Headers: map[string]string{"Authorization": "Bearer abc"}}},
+		{name: "Resumed download", path: "/flaky.log"},
+		{name: "Not found", path: "/missing.log", wantErr: os.ErrNotExist},
+		{name: "Unauthorized", path: "/private.log", wantErr: os.ErrPermission},
+		{name: "Forbidden", path: "/token.log", wantErr: os.ErrPermission},
+		{name: "Server error", path: "/error.log", anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := Open(config.LogConfig{ID: "web", Path: server.URL + tt.path, Type: "text", HTTP: tt.http})
+			if tt.wantErr != nil || tt.anyErr {
+				if err == nil {
+					reader.Close()
+					t.Fatal("Open() expected an error, got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Open() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open() unexpected error: %v", err)
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() unexpected error: %v", err)
+			}
+			if string(data) != content {
+				t.Errorf("read %d bytes, want %d", len(data), len(content))
+			}
+		})
+	}
+}
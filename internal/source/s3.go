@@ -0,0 +1,240 @@
+package source
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+// s3Client reads objects from Amazon S3 or an S3-compatible store.
+type s3Client struct {
+	client    *http.Client
+	endpoint  *url.URL
+	pathStyle bool
+	signer    *signer
+}
+
+func newS3Client(settings config.S3Config) (*s3Client, error) {
+	endpoint, err := url.Parse(settings.EndpointOrDefault())
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+
+	c := &s3Client{
+		client:    newHTTPClient(settings.ResponseTimeout()),
+		endpoint:  endpoint,
+		pathStyle: settings.UsePathStyle(),
+	}
+	if accessKeyID, secretAccessKey, sessionToken := settings.Credentials(); accessKeyID != "" {
+		c.signer = &signer{
+			accessKeyID:     accessKeyID,
+			secretAccessKey: secretAccessKey,
+			sessionToken:    sessionToken,
+			region:          settings.RegionOrDefault(),
+			service:         "s3",
+			now:             time.Now,
+		}
+	}
+	return c, nil
+}
+
+// request returns a GET request for an object, or for the bucket itself when
+// key is empty.
+func (c *s3Client) request(bucket, key string, query url.Values) (*http.Request, error) {
+	u := *c.endpoint
+	objectPath := "/" + key
+	if c.pathStyle {
+		objectPath = "/" + bucket + objectPath
+	} else {
+		u.Host = bucket + "." + u.Host
+	}
+	u.Path = strings.TrimSuffix(c.endpoint.Path, "/") + objectPath
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query.Encode())
+
+	return http.NewRequest(http.MethodGet, u.String(), nil)
+}
+
+func (c *s3Client) sign(req *http.Request) {
+	if c.signer != nil {
+		c.signer.sign(req)
+	}
+}
+
+// listResult is the response of ListObjectsV2.
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the keys of the bucket starting with prefix, in lexicographic
+// order.
+func (c *s3Client) list(bucket, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := c.request(bucket, "", query)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := doGet(c.client, req, 0, "", c.sign)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse listing of s3://%s/%s: %w", bucket, prefix, err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// open starts the download of an object.
+func (c *s3Client) open(bucket, key string) (io.ReadCloser, error) {
+	return openRange(func(offset int64, etag string) (*http.Response, error) {
+		req, err := c.request(bucket, key, nil)
+		if err != nil {
+			return nil, err
+		}
+		return doGet(c.client, req, offset, etag, c.sign)
+	})
+}
+
+// matchingKeys lists the objects designated by a prefix or glob pattern.
+// Glob metacharacters follow path.Match, so "*" does not cross a "/".
+func (c *s3Client) matchingKeys(location config.S3Location) ([]string, error) {
+	keys, err := c.list(location.Bucket, location.Prefix())
+	if err != nil {
+		return nil, err
+	}
+
+	glob := location.Key != location.Prefix()
+	matched := keys[:0]
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/") {
+			// Folder placeholder created by some consoles.
+			continue
+		}
+		if glob {
+			if ok, _ := path.Match(location.Key, key); !ok {
+				continue
+			}
+		}
+		matched = append(matched, key)
+	}
+	return matched, nil
+}
+
+// openS3 reads an object or, for a prefix or glob pattern, every matching
+// object one after the other in key order, as one log.
+func openS3(logConfig config.LogConfig) (io.ReadCloser, error) {
+	location, err := config.ParseS3Path(logConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+	settings := config.S3Config{}
+	if logConfig.S3 != nil {
+		settings = *logConfig.S3
+	}
+	client, err := newS3Client(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if !location.IsPattern() {
+		return client.open(location.Bucket, location.Key)
+	}
+
+	keys, err := client.matchingKeys(location)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, &os.PathError{Op: "list", Path: logConfig.Path, Err: fmt.Errorf("no matching objects: %w", os.ErrNotExist)}
+	}
+
+	first, err := client.open(location.Bucket, keys[0])
+	if err != nil {
+		return nil, err
+	}
+	return &objectsReader{
+		current: first,
+		keys:    keys[1:],
+		open: func(key string) (io.ReadCloser, error) {
+			return client.open(location.Bucket, key)
+		},
+	}, nil
+}
+
+// objectsReader concatenates objects, opening each one only once the previous
+// one is read. A newline is inserted after an object not ending with one, so
+// that lines of two objects are never joined.
+type objectsReader struct {
+	current io.ReadCloser
+	keys    []string
+	open    func(key string) (io.ReadCloser, error)
+	last    byte
+}
+
+func (r *objectsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			return 0, io.EOF
+		}
+		n, err := r.current.Read(p)
+		if n > 0 {
+			r.last = p[n-1]
+			return n, nil
+		}
+		if err != io.EOF {
+			return 0, err
+		}
+
+		r.current.Close()
+		r.current = nil
+		if len(r.keys) == 0 {
+			return 0, io.EOF
+		}
+		next, err := r.open(r.keys[0])
+		if err != nil {
+			return 0, err
+		}
+		r.current, r.keys = next, r.keys[1:]
+		if r.last != '\n' && r.last != 0 && len(p) > 0 {
+			p[0], r.last = '\n', '\n'
+			return 1, nil
+		}
+	}
+}
+
+func (r *objectsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
@@ -0,0 +1,162 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+var testModTime = time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)
+
+// fakeS3 serves a path-style bucket named "logs", two keys per listing page.
+type fakeS3 struct {
+	objects map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Authorization"), "Credential=minio/") {
+		writeS3Error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "logs" {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	if key == "" && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r)
+		return
+	}
+
+	content, ok := f.objects[key]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	http.ServeContent(w, r, key, testModTime, strings.NewReader(content))
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if token := r.URL.Query().Get("continuation-token"); token != "" {
+		fmt.Sscanf(token, "page-%d", &start)
+	}
+	end := min(start+2, len(keys))
+
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult>`)
+	for _, key := range keys[start:end] {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", key)
+	}
+	if end < len(keys) {
+		fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>page-%d</NextContinuationToken>", end)
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code></Error>", code)
+}
+
+func TestOpenS3(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: map[string]string{
+		"app/2024-05-01.log":  "line 1\nline 2\n",
+		"app/2024-05-02.log":  "line 3",
+		"app/2024-05-03.log":  "line 4\n",
+		"app/2024-06-01.log":  "line 5\n",
+		"app/archive/":        "",
+		"app/archive/old.log": "old\n",
+	}})
+	defer server.Close()
+
+	settings := &config.S3Config{Endpoint: server.URL, AccessKeyID: "minio", SecretAccessKey: "minio123"}
+
+	tests := []struct {
+		name     string
+		path     string
+		settings *config.S3Config
+		want     string
+		wantErr  error
+	}{
+		{name: "Object", path: "s3://logs/app/2024-05-01.log", want: "line 1\nline 2\n"},
+		{name: "Glob", path: "s3://logs/app/2024-05-*.log", want: "line 1\nline 2\nline 3\nline 4\n"},
+		{name: "Prefix", path: "s3://logs/app/", want: "line 1\nline 2\nline 3\nline 4\nline 5\nold\n"},
+		{name: "Missing object", path: "s3://logs/app/missing.log", wantErr: os.ErrNotExist},
+		{name: "No match", path: "s3://logs/app/2023-*.log", wantErr: os.ErrNotExist},
+		{name: "Missing bucket", path: "s3://other/app.log", wantErr: os.ErrNotExist},
+		{
+			name:     "Wrong credentials",
+			path:     "s3://logs/app/2024-05-01.log",
+			settings: &config.S3Config{Endpoint: server.URL, AccessKeyID: "someone", SecretAccessKey: "else"},
+			wantErr:  os.ErrPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s3 := settings
+			if tt.settings != nil {
+				s3 = tt.settings
+			}
+			reader, err := Open(config.LogConfig{ID: "archive", Path: tt.path, Type: "text", S3: s3})
+			if tt.wantErr != nil {
+				if err == nil {
+					reader.Close()
+					t.Fatal("Open() expected an error, got nil")
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Open() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open() unexpected error: %v", err)
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() unexpected error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("content = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3ClientVirtualHostedURL(t *testing.T) {
+	pathStyle := false
+	client, err := newS3Client(config.S3Config{Endpoint: "https://s3.eu-west-3.amazonaws.com", PathStyle: &pathStyle})
+	if err != nil {
+		t.Fatalf("newS3Client() unexpected error: %v", err)
+	}
+
+	req, err := client.request("logs", "app/my log.txt", nil)
+	if err != nil {
+		t.Fatalf("request() unexpected error: %v", err)
+	}
+	if got, want := req.URL.String(), "https://logs.s3.eu-west-3.amazonaws.com/app/my%20log.txt"; got != want {
+		t.Errorf("URL = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package source
+
+import (
+	"io"
+	"os"
+
+	"loganalyzer/internal/config"
+)
+
+// Open returns a reader over the content of a log, which is either a local
+// file, the standard input for the path "-", a file on a remote host for an
+// ssh:// path, a download for an http(s):// URL or the objects of a bucket
+// for an s3:// path. Errors wrap os.ErrNotExist or os.ErrPermission when the
+// file is missing or unreadable, wherever it lives.
+func Open(logConfig config.LogConfig) (io.ReadCloser, error) {
+	switch {
+	case config.IsSSHPath(logConfig.Path):
+		return openSSH(logConfig)
+	case config.IsHTTPPath(logConfig.Path):
+		return openHTTP(logConfig)
+	case config.IsS3Path(logConfig.Path):
+		return openS3(logConfig)
+	case config.IsStdinPath(logConfig.Path):
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(logConfig.Path)
+}
+
+// IsLocalFile reports whether the log is a file of the local file system,
+// rather than the standard input or a file on another host.
+func IsLocalFile(logConfig config.LogConfig) bool {
+	return !config.IsRemotePath(logConfig.Path) && !config.IsStdinPath(logConfig.Path)
+}
@@ -0,0 +1,261 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"loganalyzer/internal/config"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// openSSH streams a remote file with "cat" over an SSH session. The first
+// byte is awaited before returning so that a missing or unreadable file is
+// reported by Open, like for a local file, rather than by the first Read.
+func openSSH(logConfig config.LogConfig) (io.ReadCloser, error) {
+	target, err := config.ParseSSHPath(logConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+	settings := config.SSHConfig{}
+	if logConfig.SSH != nil {
+		settings = *logConfig.SSH
+	}
+
+	client, agentConn, err := dial(target, settings)
+	if err != nil {
+		return nil, err
+	}
+	r := &remoteFile{client: client, agent: agentConn, target: target}
+
+	session, err := client.NewSession()
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to open SSH session on %s: %w", target.Host, err)
+	}
+	r.session = session
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to read from %s: %w", target.Host, err)
+	}
+	session.Stderr = &r.stderr
+	r.reader = bufio.NewReader(stdout)
+
+	if err := session.Start("cat -- " + shellQuote(target.Path)); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("failed to run cat on %s: %w", target.Host, err)
+	}
+
+	if _, err := r.reader.Peek(1); err != nil {
+		// Nothing was written: either an empty file or a failed cat.
+		if err := r.wait(); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// remoteFile is the output of a remote cat. Read returns the cat failure, if
+// any, instead of io.EOF.
+type remoteFile struct {
+	client *ssh.Client
+	// agent is the connection to the SSH agent, nil without one.
+	agent   net.Conn
+	session *ssh.Session
+	target  config.SSHTarget
+	reader  *bufio.Reader
+	stderr  bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+func (r *remoteFile) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		if waitErr := r.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (r *remoteFile) wait() error {
+	r.waitOnce.Do(func() {
+		err := r.session.Wait()
+		if err != nil {
+			r.waitErr = remoteError(r.target, r.stderr.String(), err)
+		}
+	})
+	return r.waitErr
+}
+
+func (r *remoteFile) Close() error {
+	if r.session != nil {
+		r.session.Close()
+	}
+	if r.agent != nil {
+		r.agent.Close()
+	}
+	return r.client.Close()
+}
+
+// remoteError maps the message of a failed cat onto the errors of a local
+// open, so missing and unreadable remote files are reported the same way.
+func remoteError(target config.SSHTarget, stderr string, err error) error {
+	msg := strings.TrimSpace(stderr)
+	if msg == "" {
+		msg = err.Error()
+	}
+	where := target.Host + ":" + target.Path
+
+	switch {
+	case strings.Contains(msg, "No such file or directory"):
+		return &os.PathError{Op: "open", Path: where, Err: os.ErrNotExist}
+	case strings.Contains(msg, "Permission denied"):
+		return &os.PathError{Op: "open", Path: where, Err: os.ErrPermission}
+	default:
+		return fmt.Errorf("cat %s failed: %s", where, msg)
+	}
+}
+
+// dial connects to the target. The returned connection to the SSH agent, if
+// any, must be closed with the client.
+func dial(target config.SSHTarget, settings config.SSHConfig) (*ssh.Client, net.Conn, error) {
+	hostKeyCallback, err := hostKeyCallback(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := target.User
+	if user == "" {
+		user = settings.User
+	}
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	methods, agentConn := authMethods(settings)
+	clientConfig := &ssh.ClientConfig{
+		User:            user,
+		Auth:            methods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         settings.DialTimeout(),
+	}
+
+	client, err := ssh.Dial("tcp", target.Addr(), clientConfig)
+	if err != nil {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, nil, fmt.Errorf("SSH authentication as %s on %s failed: %w", user, target.Addr(), os.ErrPermission)
+		}
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", target.Addr(), err)
+	}
+	return client, agentConn, nil
+}
+
+// authMethods offers the keys of the running SSH agent, if any, and the
+// configured or default private key files. Encrypted keys are skipped: use an
+// agent for them. All keys are offered by a single method, as the client
+// tries each method only once. The connection to the agent is returned for
+// the caller to close, nil when there is no agent.
+func authMethods(settings config.SSHConfig) ([]ssh.AuthMethod, net.Conn) {
+	var signers []ssh.Signer
+	for _, path := range settings.KeyFiles() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	var agentConn net.Conn
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+		}
+	}
+	if agentConn == nil {
+		if len(signers) == 0 {
+			return nil, nil
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signers...)}, nil
+	}
+
+	keyring := agent.NewClient(agentConn)
+	return []ssh.AuthMethod{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		agentSigners, err := keyring.Signers()
+		if err != nil {
+			return signers, nil
+		}
+		return append(agentSigners, signers...), nil
+	})}, agentConn
+}
+
+// knownHostsMu serializes additions to known_hosts files under the
+// accept-new policy.
+var knownHostsMu sync.Mutex
+
+func hostKeyCallback(settings config.SSHConfig) (ssh.HostKeyCallback, error) {
+	if settings.Policy() == config.HostKeyInsecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := settings.KnownHostsPath()
+	if settings.Policy() == config.HostKeyAcceptNew {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600); err == nil {
+			f.Close()
+		}
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known hosts %s: %w", path, err)
+	}
+	if settings.Policy() == config.HostKeyStrict {
+		return callback, nil
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			// Known host, or a known host whose key changed.
+			return err
+		}
+
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+		f, openErr := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+		if openErr != nil {
+			return fmt.Errorf("failed to record host key of %s: %w", hostname, openErr)
+		}
+		defer f.Close()
+		_, writeErr := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key))
+		return writeErr
+	}, nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,332 @@
+package source
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testServer is an SSH server answering "cat -- 'path'" with local files.
+type testServer struct {
+	addr    string
+	hostKey ssh.Signer
+}
+
+func startTestServer(t *testing.T, clientKey ssh.PublicKey) *testServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate host key: %v", err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("Failed to create host signer: %v", err)
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key for %s", conn.User())
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, serverConfig)
+		}
+	}()
+
+	return &testServer{addr: listener.Addr().String(), hostKey: hostKey}
+}
+
+func serveConn(conn net.Conn, serverConfig *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go serveSession(channel, requests)
+	}
+}
+
+func serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			req.Reply(false, nil)
+			continue
+		}
+		req.Reply(true, nil)
+
+		// The payload is the command as an SSH string.
+		command := string(req.Payload[4:])
+		path := strings.TrimSuffix(strings.TrimPrefix(command, "cat -- '"), "'")
+
+		status := uint32(0)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(channel.Stderr(), "cat: %s: No such file or directory\n", path)
+			status = 1
+		} else {
+			channel.Write(data)
+		}
+
+		payload := make([]byte, 4)
+		binary.BigEndian.PutUint32(payload, status)
+		channel.SendRequest("exit-status", false, payload)
+		return
+	}
+}
+
+// newClientKey writes a private key for the client and returns its public
+// key and path.
+func newClientKey(t *testing.T, dir string) (ssh.PublicKey, string) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate client key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("Failed to marshal client key: %v", err)
+	}
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Failed to write client key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Failed to create client signer: %v", err)
+	}
+	return signer.PublicKey(), path
+}
+
+func TestOpenSSH(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+
+	clientKey, keyFile := newClientKey(t, dir)
+	server := startTestServer(t, clientKey)
+	host, port, _ := net.SplitHostPort(server.addr)
+
+	logPath := filepath.Join(dir, "app.log")
+	content := "2024-05-24 10:00:00 [ERROR] Something failed\n2024-05-24 10:00:01 [INFO] Recovered\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	emptyPath := filepath.Join(dir, "empty.log")
+	if err := os.WriteFile(emptyPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(server.addr)}, server.hostKey.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write known_hosts: %v", err)
+	}
+	emptyKnownHosts := filepath.Join(dir, "known_hosts_empty")
+	if err := os.WriteFile(emptyKnownHosts, nil, 0600); err != nil {
+		t.Fatalf("Failed to write known_hosts: %v", err)
+	}
+
+	remote := func(path string) string {
+		return fmt.Sprintf("ssh://tester@%s:%s:%s", host, port, path)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		ssh        config.SSHConfig
+		want       string
+		wantErr    error
+		anyErr     bool
+		knownAfter bool
+	}{
+		{
+			name: "Read remote file",
+			path: remote(logPath),
+			ssh:  config.SSHConfig{KeyFile: keyFile, KnownHosts: knownHosts},
+			want: content,
+		},
+		{
+			name: "Empty remote file",
+			path: remote(emptyPath),
+			ssh:  config.SSHConfig{KeyFile: keyFile, KnownHosts: knownHosts},
+			want: "",
+		},
+		{
+			name:    "Missing remote file",
+			path:    remote(filepath.Join(dir, "missing.log")),
+			ssh:     config.SSHConfig{KeyFile: keyFile, KnownHosts: knownHosts},
+			wantErr: os.ErrNotExist,
+		},
+		{
+			name:    "Unknown key",
+			path:    remote(logPath),
+			ssh:     config.SSHConfig{KeyFile: filepath.Join(dir, "no-such-key"), KnownHosts: knownHosts},
+			wantErr: os.ErrPermission,
+		},
+		{
+			name:   "Unknown host in strict mode",
+			path:   remote(logPath),
+			ssh:    config.SSHConfig{KeyFile: keyFile, KnownHosts: emptyKnownHosts},
+			anyErr: true,
+		},
+		{
+			name:       "Unknown host accepted",
+			path:       remote(logPath),
+			ssh:        config.SSHConfig{KeyFile: keyFile, KnownHosts: emptyKnownHosts, HostKeyPolicy: config.HostKeyAcceptNew},
+			want:       content,
+			knownAfter: true,
+		},
+		{
+			name: "Insecure",
+			path: remote(logPath),
+			ssh:  config.SSHConfig{KeyFile: keyFile, KnownHosts: filepath.Join(dir, "none"), HostKeyPolicy: config.HostKeyInsecure},
+			want: content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := tt.ssh
+			reader, err := Open(config.LogConfig{ID: "remote", Path: tt.path, Type: "text", SSH: &settings})
+			if tt.wantErr != nil || tt.anyErr {
+				if err == nil {
+					reader.Close()
+					t.Fatal("Open() expected an error, got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Open() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open() unexpected error: %v", err)
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() unexpected error: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("content = %q, want %q", data, tt.want)
+			}
+
+			if tt.knownAfter {
+				callback, err := knownhosts.New(settings.KnownHosts)
+				if err != nil {
+					t.Fatalf("knownhosts.New() unexpected error: %v", err)
+				}
+				tcpAddr, _ := net.ResolveTCPAddr("tcp", server.addr)
+				if err := callback(server.addr, tcpAddr, server.hostKey.PublicKey()); err != nil {
+					t.Errorf("host key not recorded: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenSSHClosesAgent(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, keyFile := newClientKey(t, dir)
+	server := startTestServer(t, clientKey)
+	host, port, _ := net.SplitHostPort(server.addr)
+
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("INFO ok\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	// An empty agent, reporting when each of its connections is closed.
+	sock := filepath.Join(dir, "agent.sock")
+	agentListener, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { agentListener.Close() })
+	closed := make(chan struct{}, 8)
+	go func() {
+		for {
+			conn, err := agentListener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				agent.ServeAgent(agent.NewKeyring(), conn)
+				closed <- struct{}{}
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+
+	settings := config.SSHConfig{KeyFile: keyFile, HostKeyPolicy: config.HostKeyInsecure}
+	reader, err := Open(config.LogConfig{ID: "remote", Path: fmt.Sprintf("ssh://tester@%s:%s:%s", host, port, logPath), Type: "text", SSH: &settings})
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	reader.Close()
+
+	// A failed connection closes its agent connection too.
+	if _, err := Open(config.LogConfig{ID: "remote", Path: "ssh://tester@127.0.0.1:1:/var/log/app.log", Type: "text", SSH: &settings}); err == nil {
+		t.Fatal("Open() of a closed port expected an error, got nil")
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d of 2 agent connections left open", 2-i)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if got := shellQuote("/var/log/it's.log"); got != `'/var/log/it'\''s.log'` {
+		t.Errorf("shellQuote() = %s", got)
+	}
+}
@@ -0,0 +1,240 @@
+package templates
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"loganalyzer/internal/reporter"
+)
+
+const (
+	// Wildcard replaces the tokens that vary between messages of a template.
+	Wildcard = "<*>"
+
+	// similarityThreshold is the minimum fraction of identical tokens for a
+	// message to join an existing template.
+	similarityThreshold = 0.5
+	// prefixDepth is the number of leading tokens used to route messages.
+	prefixDepth = 1
+	// maxChildren bounds the distinct prefixes per level before falling back
+	// to the wildcard branch.
+	maxChildren = 100
+	// DefaultMaxClusters bounds the memory used by a Miner.
+	DefaultMaxClusters = 1000
+)
+
+type maskRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+	// match optionally filters the candidates found by pattern.
+	match func(string) bool
+}
+
+// masks are applied in order, so more specific patterns come first.
+var masks = []maskRule{
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<UUID>", nil},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b`), "<IP>", nil},
+	{regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`), "<IP>", nil},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<HEX>", nil},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{6,}\b`), "<HEX>", isHexID},
+	{regexp.MustCompile(`[-+]?\b\d+(?:\.\d+)?\b`), "<NUM>", nil},
+}
+
+// Mask replaces the variable parts of a message (UUIDs, IPs, hex IDs and
+// numbers) with placeholders.
+func Mask(message string) string {
+	for _, m := range masks {
+		if m.match == nil {
+			message = m.pattern.ReplaceAllString(message, m.replacement)
+			continue
+		}
+		message = m.pattern.ReplaceAllStringFunc(message, func(s string) string {
+			if m.match(s) {
+				return m.replacement
+			}
+			return s
+		})
+	}
+	return message
+}
+
+// isHexID reports whether s mixes digits and hex letters, so plain words
+// ("facade") and plain numbers are left to the other masks.
+func isHexID(s string) bool {
+	return strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "abcdefABCDEF")
+}
+
+type cluster struct {
+	tokens []string
+	count  int
+	sample string
+}
+
+func (c *cluster) pattern() string {
+	return strings.Join(c.tokens, " ")
+}
+
+type node struct {
+	children map[string]*node
+	clusters []*cluster
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+// Miner groups similar messages into templates following the Drain approach:
+// messages are masked, routed through a fixed-depth prefix tree keyed by the
+// token count and the leading tokens, and merged into the most similar
+// template of the leaf.
+type Miner struct {
+	root        *node
+	clusters    []*cluster
+	maxClusters int
+	dropped     int
+}
+
+func NewMiner() *Miner {
+	return &Miner{
+		root:        newNode(),
+		maxClusters: DefaultMaxClusters,
+	}
+}
+
+// Add records a message. sample is the raw line kept as an example for a new
+// template; it defaults to the message.
+func (m *Miner) Add(message, sample string) {
+	tokens := strings.Fields(Mask(message))
+	if len(tokens) == 0 {
+		return
+	}
+	if sample == "" {
+		sample = message
+	}
+
+	leaf := m.leaf(tokens)
+
+	if c := bestMatch(leaf.clusters, tokens); c != nil {
+		merge(c, tokens)
+		c.count++
+		return
+	}
+
+	if len(m.clusters) >= m.maxClusters {
+		m.dropped++
+		return
+	}
+
+	c := &cluster{tokens: tokens, count: 1, sample: sample}
+	leaf.clusters = append(leaf.clusters, c)
+	m.clusters = append(m.clusters, c)
+}
+
+// Dropped returns the number of messages that did not fit in any template
+// because the cluster limit was reached.
+func (m *Miner) Dropped() int {
+	return m.dropped
+}
+
+// Top returns the n most frequent templates, most frequent first. n <= 0
+// returns every template.
+func (m *Miner) Top(n int) []reporter.Template {
+	sorted := make([]*cluster, len(m.clusters))
+	copy(sorted, m.clusters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].pattern() < sorted[j].pattern()
+	})
+
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	result := make([]reporter.Template, 0, len(sorted))
+	for _, c := range sorted {
+		result = append(result, reporter.Template{
+			Pattern: c.pattern(),
+			Count:   c.count,
+			Sample:  c.sample,
+		})
+	}
+	return result
+}
+
+// leaf walks (and grows) the prefix tree for tokens. The first level is the
+// token count, the next levels are the leading tokens; tokens holding a
+// placeholder or a digit are routed to the wildcard branch.
+func (m *Miner) leaf(tokens []string) *node {
+	current := child(m.root, strconv.Itoa(len(tokens)))
+
+	for i := 0; i < prefixDepth && i < len(tokens); i++ {
+		key := tokens[i]
+		if strings.ContainsAny(key, "<>0123456789") {
+			key = Wildcard
+		}
+		if _, ok := current.children[key]; !ok && len(current.children) >= maxChildren {
+			key = Wildcard
+		}
+		current = child(current, key)
+	}
+
+	return current
+}
+
+func child(n *node, key string) *node {
+	c, ok := n.children[key]
+	if !ok {
+		c = newNode()
+		n.children[key] = c
+	}
+	return c
+}
+
+// bestMatch returns the most similar cluster when its similarity reaches the
+// threshold. Ties go to the cluster with more wildcards, as in Drain.
+func bestMatch(clusters []*cluster, tokens []string) *cluster {
+	var best *cluster
+	bestSim, bestParams := -1.0, -1
+
+	for _, c := range clusters {
+		sim, params := similarity(c.tokens, tokens)
+		if sim > bestSim || (sim == bestSim && params > bestParams) {
+			best, bestSim, bestParams = c, sim, params
+		}
+	}
+
+	if best == nil || bestSim < similarityThreshold {
+		return nil
+	}
+	return best
+}
+
+func similarity(template, tokens []string) (float64, int) {
+	if len(template) != len(tokens) {
+		return 0, 0
+	}
+
+	same, params := 0, 0
+	for i, token := range template {
+		if token == Wildcard {
+			params++
+			continue
+		}
+		if token == tokens[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(template)), params
+}
+
+func merge(c *cluster, tokens []string) {
+	for i, token := range c.tokens {
+		if token != tokens[i] {
+			c.tokens[i] = Wildcard
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "user 550e8400-e29b-41d4-a716-446655440000 not found",
+			expected: "user <UUID> not found",
+		},
+		{
+			input:    "connection to 10.0.0.12:5432 refused",
+			expected: "connection to <IP> refused",
+		},
+		{
+			input:    "request a3f9c2e1b7 took 250ms with 3 retries",
+			expected: "request <HEX> took 250ms with <NUM> retries",
+		},
+		{
+			input:    "pointer 0xc000123456 at offset -42.5",
+			expected: "pointer <HEX> at offset <NUM>",
+		},
+		{
+			input:    "facade decade",
+			expected: "facade decade",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Mask(tt.input); got != tt.expected {
+				t.Errorf("Mask(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMinerGroupsSimilarMessages(t *testing.T) {
+	miner := NewMiner()
+
+	messages := []string{
+		"Connection to 10.0.0.1 timed out after 30 seconds",
+		"Connection to 10.0.0.2 timed out after 45 seconds",
+		"Connection to 10.0.0.3 timed out after 30 seconds",
+		"User alice failed to login",
+		"User bob failed to login",
+		"Disk /dev/sda1 is full",
+	}
+	for _, msg := range messages {
+		miner.Add(msg, "raw: "+msg)
+	}
+
+	top := miner.Top(2)
+	if len(top) != 2 {
+		t.Fatalf("Top(2) returned %d templates, want 2", len(top))
+	}
+
+	if top[0].Pattern != "Connection to <IP> timed out after <NUM> seconds" || top[0].Count != 3 {
+		t.Errorf("Top()[0] = %+v", top[0])
+	}
+	if top[0].Sample != "raw: "+messages[0] {
+		t.Errorf("Top()[0] sample = %q, want first raw line", top[0].Sample)
+	}
+
+	if top[1].Pattern != "User <*> failed to login" || top[1].Count != 2 {
+		t.Errorf("Top()[1] = %+v", top[1])
+	}
+
+	if all := miner.Top(0); len(all) != 3 {
+		t.Errorf("Top(0) returned %d templates, want 3", len(all))
+	}
+}
+
+func TestMinerClusterLimit(t *testing.T) {
+	miner := NewMiner()
+	miner.maxClusters = 2
+
+	miner.Add("alpha beta gamma", "")
+	miner.Add("one two", "")
+	miner.Add("completely different message here", "")
+
+	if got := len(miner.Top(0)); got != 2 {
+		t.Errorf("Top(0) returned %d templates, want 2", got)
+	}
+	if miner.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", miner.Dropped())
+	}
+}
@@ -0,0 +1,159 @@
+package timeline
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	parser "loganalyzer/internal/analyzer"
+	"loganalyzer/internal/config"
+	"loganalyzer/internal/entry"
+)
+
+// DefaultBuffer is the number of entries read ahead per source. Memory use is
+// bounded by the number of sources times the buffer, whatever the file sizes.
+const DefaultBuffer = 256
+
+// errStop ends the reading of a source once it is past Options.Until.
+var errStop = errors.New("stop reading")
+
+// Item is an entry of the merged timeline. Source is the index of the log in
+// the list passed to Merge.
+type Item struct {
+	LogID  string
+	Source int
+	Time   time.Time
+	Entry  entry.Entry
+}
+
+// Options restricts the merged timeline to [Since, Until]; zero values leave
+// the range open.
+type Options struct {
+	Since  time.Time
+	Until  time.Time
+	Buffer int
+}
+
+// Merge reads every log concurrently and calls emit with their entries in
+// chronological order (a k-way merge on the parsed timestamps). Each source is
+// expected to be mostly chronological already; an entry without a timestamp
+// takes the time of the previous entry of its log so it stays next to it.
+// Read errors of individual logs are joined into the returned error.
+func Merge(ctx context.Context, logs []config.LogConfig, opts Options, emit func(Item) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+
+	channels := make([]chan Item, len(logs))
+	sourceErrs := make([]error, len(logs))
+	var wg sync.WaitGroup
+
+	for i, logConfig := range logs {
+		channels[i] = make(chan Item, buffer)
+		wg.Add(1)
+		go func(i int, logConfig config.LogConfig) {
+			defer wg.Done()
+			defer close(channels[i])
+			sourceErrs[i] = readSource(ctx, i, logConfig, opts, channels[i])
+		}(i, logConfig)
+	}
+
+	h := &itemHeap{}
+	for i := range channels {
+		if item, ok := <-channels[i]; ok {
+			heap.Push(h, item)
+		}
+	}
+
+	var emitErr error
+	for h.Len() > 0 {
+		item := heap.Pop(h).(Item)
+		if err := emit(item); err != nil {
+			emitErr = err
+			break
+		}
+		if next, ok := <-channels[item.Source]; ok {
+			heap.Push(h, next)
+		}
+	}
+
+	cancel()
+	for i := range channels {
+		for range channels[i] {
+			// Drain so every reader can exit.
+		}
+	}
+	wg.Wait()
+
+	if emitErr != nil {
+		return emitErr
+	}
+	return errors.Join(sourceErrs...)
+}
+
+func readSource(ctx context.Context, source int, logConfig config.LogConfig, opts Options, out chan<- Item) error {
+	var last time.Time
+
+	err := parser.ReadEntries(logConfig, func(e entry.Entry, _ error) error {
+		ts := e.Time
+		if ts.IsZero() {
+			ts = last
+		} else {
+			last = ts
+		}
+
+		if !opts.Since.IsZero() && ts.Before(opts.Since) {
+			return nil
+		}
+		if !opts.Until.IsZero() && ts.After(opts.Until) {
+			return errStop
+		}
+
+		select {
+		case out <- Item{LogID: logConfig.ID, Source: source, Time: ts, Entry: e}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	if errors.Is(err, errStop) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("log %s: %w", logConfig.ID, err)
+	}
+	return nil
+}
+
+// itemHeap orders items by time, then by source so equal timestamps keep the
+// configuration order.
+type itemHeap []Item
+
+func (h itemHeap) Len() int { return len(h) }
+
+func (h itemHeap) Less(i, j int) bool {
+	if !h[i].Time.Equal(h[j].Time) {
+		return h[i].Time.Before(h[j].Time)
+	}
+	return h[i].Source < h[j].Source
+}
+
+func (h itemHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *itemHeap) Push(x any) { *h = append(*h, x.(Item)) }
+
+func (h *itemHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,120 @@
+package timeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+func writeLog(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	// The app host writes local time two hours ahead of UTC and its clock
+	// runs 30 seconds fast.
+	app := writeLog(t, dir, "app.log", `2024-01-01 12:00:31 INFO starting
+2024-01-01 12:00:33 ERROR database unreachable
+    at db.connect
+2024-01-01 12:00:36 INFO retrying
+`)
+	api := writeLog(t, dir, "api.log", `{"time":"2024-01-01T10:00:00Z","level":"info","msg":"request"}
+{"time":"2024-01-01T10:00:02Z","level":"warn","msg":"slow"}
+{"time":"2024-01-01T10:00:05Z","level":"info","msg":"done"}
+`)
+
+	logs := []config.LogConfig{
+		{ID: "app", Path: app, Type: "application", Timezone: "Etc/GMT-2", TimeOffset: "-30s"},
+		{ID: "api", Path: api, Type: "jsonl"},
+	}
+
+	var got []string
+	err := Merge(context.Background(), logs, Options{Buffer: 1}, func(item Item) error {
+		got = append(got, item.LogID+" "+item.Time.UTC().Format("15:04:05")+" "+strings.TrimSpace(item.Entry.Message))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	want := []string{
+		"api 10:00:00 request",
+		"app 10:00:01 INFO starting",
+		"api 10:00:02 slow",
+		"app 10:00:03 ERROR database unreachable",
+		"app 10:00:03 at db.connect",
+		"api 10:00:05 done",
+		"app 10:00:06 INFO retrying",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Merge() order:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMergeRange(t *testing.T) {
+	dir := t.TempDir()
+	a := writeLog(t, dir, "a.log", `2024-01-01T10:00:00Z a1
+2024-01-01T10:00:10Z a2
+2024-01-01T10:00:20Z a3
+`)
+	b := writeLog(t, dir, "b.log", `2024-01-01T10:00:05Z b1
+2024-01-01T10:00:15Z b2
+2024-01-01T10:00:25Z b3
+`)
+
+	opts := Options{
+		Since: time.Date(2024, 1, 1, 10, 0, 5, 0, time.UTC),
+		Until: time.Date(2024, 1, 1, 10, 0, 15, 0, time.UTC),
+	}
+	logs := []config.LogConfig{
+		{ID: "a", Path: a, Type: "application"},
+		{ID: "b", Path: b, Type: "application"},
+	}
+
+	var got []string
+	err := Merge(context.Background(), logs, opts, func(item Item) error {
+		got = append(got, item.Entry.Message)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	if want := "b1 a2 b2"; strings.Join(got, " ") != want {
+		t.Errorf("Merge() = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestMergeMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	a := writeLog(t, dir, "a.log", "2024-01-01T10:00:00Z a1\n")
+
+	logs := []config.LogConfig{
+		{ID: "a", Path: a, Type: "application"},
+		{ID: "gone", Path: filepath.Join(dir, "gone.log"), Type: "application"},
+	}
+
+	count := 0
+	err := Merge(context.Background(), logs, Options{}, func(Item) error {
+		count++
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "gone") {
+		t.Errorf("Merge() error = %v, want error naming the missing log", err)
+	}
+	if count != 1 {
+		t.Errorf("Merge() emitted %d entries, want 1", count)
+	}
+}
@@ -0,0 +1,196 @@
+// Package tracing exports the analyses as OpenTelemetry traces, over OTLP or
+// to stdout. It exports no metrics: those are scraped from the /metrics
+// endpoint of serve.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"loganalyzer/internal/config"
+)
+
+// Span is an operation of a trace. A nil *Span is valid and ignores every
+// call, so code can be instrumented whether or not tracing is enabled.
+type Span struct {
+	tracer *Tracer
+
+	mu         sync.Mutex
+	traceID    [16]byte
+	spanID     [8]byte
+	parentID   [8]byte
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        string
+	ended      bool
+}
+
+// Attribute is a key/value pair attached to a span. Values are strings,
+// integers, floats or booleans.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// String, Int and Bool build attributes.
+func String(key, value string) Attribute    { return Attribute{Key: key, Value: value} }
+func Int(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is a finished span handed to an exporter.
+type SpanData struct {
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Error is the status message of a failed span, empty when it succeeded.
+	Error string
+}
+
+// Exporter sends the spans of a finished trace to a backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer creates spans and exports each trace when its root span ends. A nil
+// *Tracer creates nil spans.
+type Tracer struct {
+	exporter Exporter
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	pending map[[16]byte][]SpanData
+	// onError reports export failures; exports happen when a root span
+	// ends, so there is no caller to return the error to.
+	onError func(error)
+}
+
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		timeout:  10 * time.Second,
+		pending:  make(map[[16]byte][]SpanData),
+		onError: func(err error) {
+			fmt.Printf("✗ Failed to export trace: %v\n", err)
+		},
+	}
+}
+
+// OnError replaces the function reporting failed exports.
+func (t *Tracer) OnError(fn func(error)) {
+	t.onError = fn
+}
+
+type spanKey struct{}
+
+// Start begins a span, child of the span carried by ctx if any, and returns a
+// context carrying the new span.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		start:      time.Now(),
+		attributes: attrs,
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attrs...)
+}
+
+// SetError marks the span as failed.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+// End finishes the span. Ending the root span of a trace exports the trace.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := SpanData{
+		TraceID:    hex.EncodeToString(s.traceID[:]),
+		SpanID:     hex.EncodeToString(s.spanID[:]),
+		Name:       s.name,
+		Start:      s.start,
+		End:        s.end,
+		Attributes: append([]Attribute(nil), s.attributes...),
+		Error:      s.err,
+	}
+	isRoot := s.parentID == [8]byte{}
+	if !isRoot {
+		data.ParentID = hex.EncodeToString(s.parentID[:])
+	}
+	s.mu.Unlock()
+
+	s.tracer.finish(s.traceID, data, isRoot)
+}
+
+func (t *Tracer) finish(traceID [16]byte, data SpanData, isRoot bool) {
+	t.mu.Lock()
+	t.pending[traceID] = append(t.pending[traceID], data)
+	if !isRoot {
+		t.mu.Unlock()
+		return
+	}
+	spans := t.pending[traceID]
+	delete(t.pending, traceID)
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	if err := t.exporter.Export(ctx, spans); err != nil && t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// FromConfig returns the tracer described by cfg. The stdout exporter writes
+// to w.
+func FromConfig(cfg config.TracingConfig, w io.Writer) *Tracer {
+	if cfg.ExporterOrDefault() == config.TracingExporterStdout {
+		return NewTracer(NewStdoutExporter(w, cfg.ServiceNameOrDefault()))
+	}
+	return NewTracer(NewOTLPExporter(cfg.EndpointOrDefault(), cfg.ServiceNameOrDefault(), cfg.Headers))
+}
@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recordingExporter struct {
+	mu     sync.Mutex
+	traces [][]SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.traces = append(e.traces, spans)
+	return nil
+}
+
+func TestTracer(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "analyze")
+	_, child := tracer.Start(ctx, "analyzeLogFile", String("log.id", "web"))
+	child.SetAttributes(Int("log.lines", 42))
+	child.SetError(errors.New("file not found"))
+	child.End()
+
+	if len(exporter.traces) != 0 {
+		t.Fatal("trace exported before the root span ended")
+	}
+	root.End()
+	root.End()
+
+	if len(exporter.traces) != 1 || len(exporter.traces[0]) != 2 {
+		t.Fatalf("exported traces = %+v, want one trace of 2 spans", exporter.traces)
+	}
+	spans := exporter.traces[0]
+	if spans[0].TraceID != spans[1].TraceID || spans[0].ParentID != spans[1].SpanID || spans[1].ParentID != "" {
+		t.Errorf("spans are not linked: %+v", spans)
+	}
+	if spans[0].Error != "file not found" || len(spans[0].Attributes) != 2 {
+		t.Errorf("child span = %+v", spans[0])
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "analyze")
+	span.SetAttributes(String("k", "v"))
+	span.SetError(errors.New("ignored"))
+	span.End()
+	if ctx == nil {
+		t.Error("Start() on nil tracer returned nil context")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body []byte
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	tracer := NewTracer(NewOTLPExporter(srv.URL, "loganalyzer-test", map[string]string{"Authorization": "Bearer secret"}))
+	var exportErr error
+	tracer.OnError(func(err error) { exportErr = err })
+
+	ctx, root := tracer.Start(context.Background(), "analyze")
+	_, child := tracer.Start(ctx, "analyzeLogFile", Int("log.bytes", 1024), Bool("ok", true))
+	child.End()
+	root.End()
+
+	if exportErr != nil {
+		t.Fatalf("export error = %v", exportErr)
+	}
+	if path != "/v1/traces" {
+		t.Errorf("exported to %s, want /v1/traces", path)
+	}
+
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("invalid OTLP body: %v", err)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 || spans[0].Name != "analyzeLogFile" || len(spans[0].TraceID) != 32 || len(spans[0].SpanID) != 16 {
+		t.Fatalf("OTLP spans = %+v", spans)
+	}
+	if v := spans[0].Attributes[0].Value.IntValue; v == nil || *v != "1024" {
+		t.Errorf("log.bytes attribute = %+v, want intValue \"1024\"", spans[0].Attributes[0].Value)
+	}
+	if !strings.Contains(string(body), `"stringValue":"loganalyzer-test"`) {
+		t.Errorf("OTLP body missing service.name: %s", body)
+	}
+
+	tracer = NewTracer(NewOTLPExporter(srv.URL, "loganalyzer-test", nil))
+	tracer.OnError(func(err error) { exportErr = err })
+	_, root = tracer.Start(context.Background(), "analyze")
+	root.End()
+	if exportErr == nil || !strings.Contains(exportErr.Error(), "401") {
+		t.Errorf("export error = %v, want status 401", exportErr)
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(NewStdoutExporter(&buf, "loganalyzer"))
+
+	_, root := tracer.Start(context.Background(), "analyze")
+	root.SetError(errors.New("no logs selected"))
+	root.End()
+
+	var req otlpRequest
+	if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
+		t.Fatalf("invalid OTLP line %q: %v", buf.String(), err)
+	}
+	status := req.ResourceSpans[0].ScopeSpans[0].Spans[0].Status
+	if status.Code != statusError || status.Message != "no logs selected" {
+		t.Errorf("status = %+v, want error", status)
+	}
+}